@@ -9,7 +9,11 @@
 package collector
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/intel/ipmctl_exporter/collector/nvm"
 	"github.com/prometheus/client_golang/prometheus"
@@ -22,11 +26,27 @@ type ipmctlCollector struct {
 	metricsReader *nvm.MetricsReader
 	// internal fields
 	enableThresholds bool
+	enableDetails    bool
+	nfitReader       *nvm.NfitReader
+	// readings failed
+	readErrors *prometheus.Desc
 	// performance readings
-	totalMediaReads    *prometheus.Desc
-	totalMediaWrites   *prometheus.Desc
-	totalReadRequests  *prometheus.Desc
-	totalWriteRequests *prometheus.Desc
+	totalMediaReads        *prometheus.Desc
+	totalMediaWrites       *prometheus.Desc
+	totalReadRequests      *prometheus.Desc
+	totalWriteRequests     *prometheus.Desc
+	totalBlockReads        *prometheus.Desc
+	totalBlockWrites       *prometheus.Desc
+	totalMediaReadBytes    *prometheus.Desc
+	totalMediaWrittenBytes *prometheus.Desc
+	performanceTime        *prometheus.Desc
+	mediaReads             *prometheus.Desc
+	mediaWrites            *prometheus.Desc
+	readRequests           *prometheus.Desc
+	writeRequests          *prometheus.Desc
+	mediaReadBytes         *prometheus.Desc
+	mediaWrittenBytes      *prometheus.Desc
+	pmonCounter            *prometheus.Desc
 	// sensor readings
 	health                      *prometheus.Desc
 	mediaTemperature            *prometheus.Desc
@@ -38,6 +58,65 @@ type ipmctlCollector struct {
 	powerCycles                 *prometheus.Desc
 	fwErrorCount                *prometheus.Desc
 	unlatchedDirtyShutdownCount *prometheus.Desc
+	sensorState                 *prometheus.Desc
+	// status readings
+	isNew                  *prometheus.Desc
+	isConfigured           *prometheus.Desc
+	isMissing              *prometheus.Desc
+	packageSparesAvailable *prometheus.Desc
+	lastShutdownTime       *prometheus.Desc
+	lastShutdownStatus     *prometheus.Desc
+	viralState             *prometheus.Desc
+	aitDRAMEnabled         *prometheus.Desc
+	bootStatus             *prometheus.Desc
+	injectedMediaErrors    *prometheus.Desc
+	injectedNonMediaErrors *prometheus.Desc
+	configStatus           *prometheus.Desc
+	arsStatus              *prometheus.Desc
+	overwriteDIMMStatus    *prometheus.Desc
+	// settings and details readings
+	viralPolicyEnabled    *prometheus.Desc
+	viralStatus           *prometheus.Desc
+	packageSparingEnabled *prometheus.Desc
+	// thermal throttling readings
+	thermalThrottleLoss     *prometheus.Desc
+	thermalThrottleEpisodes *prometheus.Desc
+	thermalThrottledTime    *prometheus.Desc
+	// firmware image readings
+	firmwareInfo              *prometheus.Desc
+	firmwareUpdateStatus      *prometheus.Desc
+	firmwareActivationPending *prometheus.Desc
+	// capacity readings
+	capacity       *prometheus.Desc
+	socketCapacity *prometheus.Desc
+	// socket and memory topology readings
+	socketMappedMemoryLimit *prometheus.Desc
+	socketTotalMappedMemory *prometheus.Desc
+	memoryTopologyInfo      *prometheus.Desc
+	// host and software inventory readings
+	hostInfo        *prometheus.Desc
+	skuViolation    *prometheus.Desc
+	mixedSKU        *prometheus.Desc
+	swInventoryInfo *prometheus.Desc
+	libraryInfo     *prometheus.Desc
+	// platform capability readings
+	featureSupported     *prometheus.Desc
+	platformCapability   *prometheus.Desc
+	memoryModeSupported  *prometheus.Desc
+	interleaveFormatInfo *prometheus.Desc
+	volatileMode         *prometheus.Desc
+	appDirectMode        *prometheus.Desc
+	// region readings
+	regionInfo         *prometheus.Desc
+	regionMember       *prometheus.Desc
+	regionCapacity     *prometheus.Desc
+	regionFreeCapacity *prometheus.Desc
+	regionHealth       *prometheus.Desc
+	// event log readings
+	events *prometheus.Desc
+	// firmware error log readings
+	fwErrorLogEntries        *prometheus.Desc
+	fwErrorLogSequenceNumber *prometheus.Desc
 	// sensor settings (thresholds)
 	mtEnabled                   *prometheus.Desc
 	mtUpperCriticalThreshold    *prometheus.Desc
@@ -60,11 +139,23 @@ type ipmctlCollector struct {
 	prLowerFatalThreshold       *prometheus.Desc
 	prUpperNoncriticalThreshold *prometheus.Desc
 	prLowerNoncriticalThreshold *prometheus.Desc
+	sensorThresholdInfo         *prometheus.Desc
+	// device details (SMBIOS data and power budgets)
+	deviceDetailsInfo *prometheus.Desc
+	peakPowerBudget   *prometheus.Desc
+	avgPowerBudget    *prometheus.Desc
 	// metadata readings (some sort of states / additional information)
 	deviceDiscoveryInfo            *prometheus.Desc
 	deviceSecurityCapabilitiesInfo *prometheus.Desc
 	deviceCapabilitiesInfo         *prometheus.Desc
 	ipmctlExporterInfo             *prometheus.Desc
+	// topology readings (ACPI NFIT table)
+	nfitSPARangeLength     *prometheus.Desc
+	nfitRegionSize         *prometheus.Desc
+	nfitInterleaveWays     *prometheus.Desc
+	nfitInterleaveLineSize *prometheus.Desc
+	nfitStateFlags         *prometheus.Desc
+	nfitControlRegionInfo  *prometheus.Desc
 }
 
 // Function used to get metrics description.
@@ -74,38 +165,165 @@ type ipmctlCollector struct {
 //   suffixes in metrics
 // - always specify the units you are working with for clarity, units should be plural
 // - don't put the type of the metric in the name such as gauge, counter etc.
-func newIpmctlCollector(enableThresholds bool) *ipmctlCollector {
+func newIpmctlCollector(backend nvm.Backend, config Config) *ipmctlCollector {
 	collector := new(ipmctlCollector)
-	collector.metricsReader = nvm.NewMetricsReader()
-	collector.enableThresholds = enableThresholds
+	collector.metricsReader = nvm.NewMetricsReader(backend, config.LocationLabels)
+	// per device metrics get location label, if it is enabled
+	labelNames := collector.metricsReader.LabelNames
+	collector.enableThresholds = config.EnableThresholds
+	collector.enableDetails = config.EnableDetails
+	collector.readErrors = prometheus.NewDesc("ipmctl_read_errors",
+		"Number of readings of the DCPMM failed in the last reading cycle, their metrics are not exported", labelNames(nvm.ReadErrorsLabelNames), nil)
 	collector.totalMediaReads = prometheus.NewDesc("ipmctl_total_media_reads_total",
-		"Lifetime number of 64 byte reads from media on the DCPMM", nvm.DevPerformanceLabelNames, nil)
+		"Lifetime number of 64 byte reads from media on the DCPMM", labelNames(nvm.DevPerformanceLabelNames), nil)
 	collector.totalMediaWrites = prometheus.NewDesc("ipmctl_total_media_writes_total",
-		"Lifetime number of 64 byte writes to media on the DCPMM", nvm.DevPerformanceLabelNames, nil)
+		"Lifetime number of 64 byte writes to media on the DCPMM", labelNames(nvm.DevPerformanceLabelNames), nil)
 	collector.totalReadRequests = prometheus.NewDesc("ipmctl_total_read_requests_total",
-		"Lifetime number of DDRT read transactions the DCPMM has serviced", nvm.DevPerformanceLabelNames, nil)
+		"Lifetime number of DDRT read transactions the DCPMM has serviced", labelNames(nvm.DevPerformanceLabelNames), nil)
 	collector.totalWriteRequests = prometheus.NewDesc("ipmctl_total_write_requests_total",
-		"Lifetime number of DDRT write transactions the DCPMM has serviced", nvm.DevPerformanceLabelNames, nil)
+		"Lifetime number of DDRT write transactions the DCPMM has serviced", labelNames(nvm.DevPerformanceLabelNames), nil)
+	collector.totalBlockReads = prometheus.NewDesc("ipmctl_total_block_reads_total",
+		"Lifetime number of block window read requests the DCPMM has serviced", labelNames(nvm.DevPerformanceLabelNames), nil)
+	collector.totalBlockWrites = prometheus.NewDesc("ipmctl_total_block_writes_total",
+		"Lifetime number of block window write requests the DCPMM has serviced", labelNames(nvm.DevPerformanceLabelNames), nil)
+	collector.totalMediaReadBytes = prometheus.NewDesc("ipmctl_media_read_bytes_total",
+		"Lifetime number of bytes read from media on the DCPMM", labelNames(nvm.DevPerformanceLabelNames), nil)
+	collector.totalMediaWrittenBytes = prometheus.NewDesc("ipmctl_media_written_bytes_total",
+		"Lifetime number of bytes written to media on the DCPMM", labelNames(nvm.DevPerformanceLabelNames), nil)
+	collector.performanceTime = prometheus.NewDesc("ipmctl_performance_timestamp_seconds",
+		"Time the performance snapshot of the DCPMM was gathered, as Unix timestamp", labelNames(nvm.DevPerformanceLabelNames), nil)
+	collector.mediaReads = prometheus.NewDesc("ipmctl_power_cycle_media_reads_total",
+		"Number of 64 byte reads from media on the DCPMM since last power cycle", labelNames(nvm.DevPerformanceLabelNames), nil)
+	collector.mediaWrites = prometheus.NewDesc("ipmctl_power_cycle_media_writes_total",
+		"Number of 64 byte writes to media on the DCPMM since last power cycle", labelNames(nvm.DevPerformanceLabelNames), nil)
+	collector.readRequests = prometheus.NewDesc("ipmctl_power_cycle_read_requests_total",
+		"Number of DDRT read transactions the DCPMM has serviced since last power cycle", labelNames(nvm.DevPerformanceLabelNames), nil)
+	collector.writeRequests = prometheus.NewDesc("ipmctl_power_cycle_write_requests_total",
+		"Number of DDRT write transactions the DCPMM has serviced since last power cycle", labelNames(nvm.DevPerformanceLabelNames), nil)
+	collector.mediaReadBytes = prometheus.NewDesc("ipmctl_power_cycle_media_read_bytes_total",
+		"Number of bytes read from media on the DCPMM since last power cycle", labelNames(nvm.DevPerformanceLabelNames), nil)
+	collector.mediaWrittenBytes = prometheus.NewDesc("ipmctl_power_cycle_media_written_bytes_total",
+		"Number of bytes written to media on the DCPMM since last power cycle", labelNames(nvm.DevPerformanceLabelNames), nil)
+	collector.pmonCounter = prometheus.NewDesc("ipmctl_pmon_counter_total",
+		"Value of the PMON counter of the DCPMM, events counted depend on the PMON group enabled (F means none)", labelNames(nvm.PMONCounterLabelNames), nil)
 	collector.health = prometheus.NewDesc("ipmctl_health",
-		"DCPMM health as reported in the SMART log", nvm.SensorLabelNames, nil)
+		"DCPMM health as reported in the SMART log", labelNames(nvm.SensorLabelNames), nil)
 	collector.mediaTemperature = prometheus.NewDesc("ipmctl_media_temperature_celsius",
-		"Device media temperature in degrees Celsius", nvm.SensorLabelNames, nil)
+		"Device media temperature in degrees Celsius", labelNames(nvm.SensorLabelNames), nil)
 	collector.controllerTemperature = prometheus.NewDesc("ipmctl_controller_temperature_celsius",
-		"Device media temperature in degrees Celsius", nvm.SensorLabelNames, nil)
+		"Device media temperature in degrees Celsius", labelNames(nvm.SensorLabelNames), nil)
 	collector.percentageRemaining = prometheus.NewDesc("ipmctl_lifespan_percentage_remaining",
-		"Amount of lifespan remaining as a percentage", nvm.SensorLabelNames, nil)
+		"Amount of lifespan remaining as a percentage", labelNames(nvm.SensorLabelNames), nil)
 	collector.latchedDirtyShutdownCount = prometheus.NewDesc("ipmctl_latched_dirty_shutdown_count_total",
-		"Device shutdowns without notification", nvm.SensorLabelNames, nil)
+		"Device shutdowns without notification", labelNames(nvm.SensorLabelNames), nil)
 	collector.powerOnTime = prometheus.NewDesc("ipmctl_power_on_time_seconds_total",
-		"Total power-on time over the lifetime of the device", nvm.SensorLabelNames, nil)
+		"Total power-on time over the lifetime of the device", labelNames(nvm.SensorLabelNames), nil)
 	collector.upTime = prometheus.NewDesc("ipmctl_up_time_seconds_total",
-		"Total power-on time since the last power cycle of the device", nvm.SensorLabelNames, nil)
+		"Total power-on time since the last power cycle of the device", labelNames(nvm.SensorLabelNames), nil)
 	collector.powerCycles = prometheus.NewDesc("ipmctl_power_cycles_total",
-		"Number of power cycles over the lifetime of the device", nvm.SensorLabelNames, nil)
+		"Number of power cycles over the lifetime of the device", labelNames(nvm.SensorLabelNames), nil)
 	collector.fwErrorCount = prometheus.NewDesc("ipmctl_fw_error_total",
-		"The total number of firmware error log entries", nvm.SensorLabelNames, nil)
+		"The total number of firmware error log entries", labelNames(nvm.SensorLabelNames), nil)
 	collector.unlatchedDirtyShutdownCount = prometheus.NewDesc("ipmctl_unlatched_dirty_shutdown_count_total",
-		"Number of times that the FW received an unexpected power loss", nvm.SensorLabelNames, nil)
+		"Number of times that the FW received an unexpected power loss", labelNames(nvm.SensorLabelNames), nil)
+	collector.sensorState = prometheus.NewDesc("ipmctl_sensor_state",
+		"State of the sensor as evaluated by the firmware against its thresholds, 1 for the current state", labelNames(nvm.SensorStateLabelNames), nil)
+	collector.sensorThresholdInfo = prometheus.NewDesc("ipmctl_sensor_threshold_info",
+		"Describes if the threshold of the sensor is supported and settable", labelNames(nvm.SensorThresholdLabelNames), nil)
+	collector.isNew = prometheus.NewDesc("ipmctl_device_is_new",
+		"Indicates if the DCPMM is unincorporated with the rest of the devices", labelNames(nvm.DeviceStatusLabelNames), nil)
+	collector.isConfigured = prometheus.NewDesc("ipmctl_device_is_configured",
+		"Indicates if the DCPMM is configured", labelNames(nvm.DeviceStatusLabelNames), nil)
+	collector.isMissing = prometheus.NewDesc("ipmctl_device_is_missing",
+		"Indicates if the DCPMM is missing", labelNames(nvm.DeviceStatusLabelNames), nil)
+	collector.packageSparesAvailable = prometheus.NewDesc("ipmctl_package_spares_available",
+		"Number of package spares on the DCPMM that are available", labelNames(nvm.DeviceStatusLabelNames), nil)
+	collector.lastShutdownTime = prometheus.NewDesc("ipmctl_last_shutdown_time_seconds",
+		"Time of the last shutdown of the DCPMM since unix epoch", labelNames(nvm.DeviceStatusLabelNames), nil)
+	collector.lastShutdownStatus = prometheus.NewDesc("ipmctl_last_shutdown_status_info",
+		"Last shutdown status details of the DCPMM decoded into flags", labelNames(nvm.LastShutdownStatusLabelNames), nil)
+	collector.viralState = prometheus.NewDesc("ipmctl_viral_state",
+		"Current viral status of the DCPMM", labelNames(nvm.DeviceStatusLabelNames), nil)
+	collector.aitDRAMEnabled = prometheus.NewDesc("ipmctl_ait_dram_enabled",
+		"Indicates if the AIT DRAM of the DCPMM is enabled", labelNames(nvm.DeviceStatusLabelNames), nil)
+	collector.bootStatus = prometheus.NewDesc("ipmctl_boot_status_register",
+		"Status of the DCPMM as reported by the firmware in the boot status register", labelNames(nvm.DeviceStatusLabelNames), nil)
+	collector.injectedMediaErrors = prometheus.NewDesc("ipmctl_injected_media_errors",
+		"Number of injected media errors on the DCPMM", labelNames(nvm.DeviceStatusLabelNames), nil)
+	collector.injectedNonMediaErrors = prometheus.NewDesc("ipmctl_injected_non_media_errors",
+		"Number of injected non-media errors on the DCPMM", labelNames(nvm.DeviceStatusLabelNames), nil)
+	collector.configStatus = prometheus.NewDesc("ipmctl_config_status",
+		"Status of the last configuration request of the DCPMM, 1 for the current state", labelNames(nvm.DeviceStatusStateLabelNames), nil)
+	collector.arsStatus = prometheus.NewDesc("ipmctl_ars_status",
+		"Address range scrub operation status of the DCPMM, 1 for the current state", labelNames(nvm.DeviceStatusStateLabelNames), nil)
+	collector.overwriteDIMMStatus = prometheus.NewDesc("ipmctl_overwrite_dimm_status",
+		"Overwrite DCPMM operation status, 1 for the current state", labelNames(nvm.DeviceStatusStateLabelNames), nil)
+	collector.viralPolicyEnabled = prometheus.NewDesc("ipmctl_viral_policy_enabled",
+		"Indicates if viral policy is enabled on the DCPMM", labelNames(nvm.DeviceDetailsLabelNames), nil)
+	collector.viralStatus = prometheus.NewDesc("ipmctl_viral_status",
+		"Viral status of the DCPMM as reported with its viral policy", labelNames(nvm.DeviceDetailsLabelNames), nil)
+	collector.packageSparingEnabled = prometheus.NewDesc("ipmctl_package_sparing_enabled",
+		"Indicates if package sparing is enabled on the DCPMM", labelNames(nvm.DeviceDetailsLabelNames), nil)
+	collector.thermalThrottleLoss = prometheus.NewDesc("ipmctl_thermal_throttle_performance_loss_percent",
+		"Average percentage of performance lost due to thermal throttling since the last reading", labelNames(nvm.DeviceStatusLabelNames), nil)
+	collector.thermalThrottleEpisodes = prometheus.NewDesc("ipmctl_thermal_throttle_episodes_total",
+		"Number of transitions into thermal throttling observed by the exporter", labelNames(nvm.DeviceStatusLabelNames), nil)
+	collector.thermalThrottledTime = prometheus.NewDesc("ipmctl_thermal_throttled_seconds_total",
+		"Time the DCPMM spent thermally throttled as observed by the exporter", labelNames(nvm.DeviceStatusLabelNames), nil)
+	collector.firmwareInfo = prometheus.NewDesc("ipmctl_firmware_info",
+		"Active and staged firmware revisions of the DCPMM", labelNames(nvm.FirmwareInfoLabelNames), nil)
+	collector.firmwareUpdateStatus = prometheus.NewDesc("ipmctl_firmware_update_status",
+		"Status of the last firmware update operation of the DCPMM, 1 for the current state", labelNames(nvm.FirmwareStateLabelNames), nil)
+	collector.firmwareActivationPending = prometheus.NewDesc("ipmctl_firmware_activation_pending",
+		"Indicates if the DCPMM has staged firmware, which requires reboot to be activated", labelNames(nvm.FirmwareLabelNames), nil)
+	collector.capacity = prometheus.NewDesc("ipmctl_capacity_bytes",
+		"Capacity of the DCPMM by the way it is provisioned", labelNames(nvm.CapacityLabelNames), nil)
+	collector.socketCapacity = prometheus.NewDesc("ipmctl_socket_capacity_bytes",
+		"Capacity of all DCPMMs in the socket by the way it is provisioned", nvm.SocketCapacityLabelNames, nil)
+	collector.socketMappedMemoryLimit = prometheus.NewDesc("ipmctl_socket_mapped_memory_limit_bytes",
+		"Maximum memory allowed to be mapped by the socket", nvm.SocketLabelNames, nil)
+	collector.socketTotalMappedMemory = prometheus.NewDesc("ipmctl_socket_mapped_memory_bytes",
+		"Memory currently mapped by the socket", nvm.SocketLabelNames, nil)
+	collector.memoryTopologyInfo = prometheus.NewDesc("ipmctl_memory_topology_info",
+		"Describes memory device (DDR4 DIMM or DCPMM) installed in the system, its type and physical labels", nvm.MemoryTopologyLabelNames, nil)
+	collector.hostInfo = prometheus.NewDesc("ipmctl_host_info",
+		"Describes the host server, its name and operating system", nvm.HostLabelNames, nil)
+	collector.skuViolation = prometheus.NewDesc("ipmctl_sku_violation",
+		"Indicates if the configuration of DCPMMs is not supported due to a SKU violation", nvm.HostStateLabelNames, nil)
+	collector.mixedSKU = prometheus.NewDesc("ipmctl_mixed_sku",
+		"Indicates if DCPMMs of different SKUs are installed in the host server", nvm.HostStateLabelNames, nil)
+	collector.swInventoryInfo = prometheus.NewDesc("ipmctl_software_inventory_info",
+		"Describes versions of libipmctl and DCPMM driver, and if the driver is compatible", nvm.SwInventoryLabelNames, nil)
+	collector.libraryInfo = prometheus.NewDesc("ipmctl_library_info",
+		"Describes version of libipmctl the readings are taken with", nvm.LibraryLabelNames, nil)
+	collector.featureSupported = prometheus.NewDesc("ipmctl_feature_supported",
+		"Indicates if the feature is supported by the host software", nvm.FeatureLabelNames, nil)
+	collector.platformCapability = prometheus.NewDesc("ipmctl_platform_capability",
+		"Indicates if the capability is supported by the platform (BIOS, driver and DCPMM SKUs)", nvm.PlatformCapabilityLabelNames, nil)
+	collector.memoryModeSupported = prometheus.NewDesc("ipmctl_memory_mode_supported",
+		"Indicates if the memory mode is supported by the BIOS", nvm.MemoryModeLabelNames, nil)
+	collector.interleaveFormatInfo = prometheus.NewDesc("ipmctl_interleave_format_info",
+		"Describes interleave format supported by the BIOS for the memory mode", nvm.InterleaveFormatLabelNames, nil)
+	collector.volatileMode = prometheus.NewDesc("ipmctl_volatile_mode",
+		"Volatile memory mode currently selected by the BIOS, 1 for the current state", nvm.ModeStateLabelNames, nil)
+	collector.appDirectMode = prometheus.NewDesc("ipmctl_app_direct_mode",
+		"App Direct mode currently selected by the BIOS, 1 for the current state", nvm.ModeStateLabelNames, nil)
+	collector.regionInfo = prometheus.NewDesc("ipmctl_region_info",
+		"Describes persistent memory region, its type, socket and member DCPMMs", nvm.RegionInfoLabelNames, nil)
+	collector.regionMember = prometheus.NewDesc("ipmctl_region_member_info",
+		"Links persistent memory region with every of its member DCPMMs", nvm.RegionMemberLabelNames, nil)
+	collector.regionCapacity = prometheus.NewDesc("ipmctl_region_capacity_bytes",
+		"Size of the persistent memory region", nvm.RegionLabelNames, nil)
+	collector.regionFreeCapacity = prometheus.NewDesc("ipmctl_region_free_capacity_bytes",
+		"Available size of the persistent memory region", nvm.RegionLabelNames, nil)
+	collector.regionHealth = prometheus.NewDesc("ipmctl_region_health",
+		"Rolled up health of the DCPMMs the region is made of, 1 for the current state", nvm.RegionStateLabelNames, nil)
+	collector.events = prometheus.NewDesc("ipmctl_events_total",
+		"Number of events logged by the DCPMM (uid) as observed by the exporter", nvm.EventLabelNames, nil)
+	collector.fwErrorLogEntries = prometheus.NewDesc("ipmctl_fw_error_log_entries",
+		"Number of entries currently stored in the firmware error log of given type and level", labelNames(nvm.FwErrorLogLabelNames), nil)
+	collector.fwErrorLogSequenceNumber = prometheus.NewDesc("ipmctl_fw_error_log_sequence_number",
+		"Sequence number of the newest entry of the firmware error log of given type and level", labelNames(nvm.FwErrorLogLabelNames), nil)
 	collector.deviceDiscoveryInfo = prometheus.NewDesc("ipmctl_device_discovery_info",
 		"Describes an enterprise-level view of a device", nvm.DeviceDiscoveryLabelNames, nil)
 	collector.deviceSecurityCapabilitiesInfo = prometheus.NewDesc("ipmctl_device_security_capabilities_info",
@@ -113,60 +331,96 @@ func newIpmctlCollector(enableThresholds bool) *ipmctlCollector {
 	collector.deviceCapabilitiesInfo = prometheus.NewDesc("impctl_device_capabilities_info",
 		"Describes the capabilities supported by a DCPMM", nvm.DeviceCapabilitiesLabelNames, nil)
 	collector.ipmctlExporterInfo = prometheus.NewDesc("ipmctl_info",
-		"Describes ipmctl_exporter info", nvm.IpmctlExporterLabelNames, nil)
-	if enableThresholds {
+		"Describes ipmctl_exporter info, its version and Go runtime it was built with", nvm.IpmctlExporterLabelNames, nil)
+	if config.EnableNfit {
+		collector.nfitReader = nvm.NewNfitReader(config.NfitTable)
+		collector.nfitSPARangeLength = prometheus.NewDesc("ipmctl_nfit_spa_range_size_bytes",
+			"Size of the system physical address range the DCPMM region is mapped to", nvm.NfitSPARangeLabelNames, nil)
+		collector.nfitRegionSize = prometheus.NewDesc("ipmctl_nfit_region_size_bytes",
+			"Size of the DCPMM region mapped to the system physical address range", nvm.NfitRegionMappingLabelNames, nil)
+		collector.nfitInterleaveWays = prometheus.NewDesc("ipmctl_nfit_interleave_ways",
+			"Number of DCPMMs interleaved together in the system physical address range", nvm.NfitRegionMappingLabelNames, nil)
+		collector.nfitInterleaveLineSize = prometheus.NewDesc("ipmctl_nfit_interleave_line_size_bytes",
+			"Interleave line size of the DCPMM region", nvm.NfitRegionMappingLabelNames, nil)
+		collector.nfitStateFlags = prometheus.NewDesc("ipmctl_nfit_state_flags",
+			"NVDIMM state flags of the DCPMM region as reported in the NFIT table", nvm.NfitRegionMappingLabelNames, nil)
+		collector.nfitControlRegionInfo = prometheus.NewDesc("ipmctl_nfit_control_region_info",
+			"Describes the NVDIMM control region of a DCPMM", nvm.NfitControlRegionLabelNames, nil)
+	}
+	if config.EnableThresholds {
 		collector.mtEnabled = prometheus.NewDesc("ipmctl_media_temperature_enabled",
-			"Indictes if firmware notifications are enabled when media temperature value is critical", nvm.SettingsLabelNames, nil)
+			"Indictes if firmware notifications are enabled when media temperature value is critical", labelNames(nvm.SettingsLabelNames), nil)
 		collector.mtUpperCriticalThreshold = prometheus.NewDesc("ipmctl_media_temperature_upper_critical_threshold_celsius",
-			"The upper media temperature critical threshold", nvm.SettingsLabelNames, nil)
+			"The upper media temperature critical threshold", labelNames(nvm.SettingsLabelNames), nil)
 		collector.mtLowerCriticalThreshold = prometheus.NewDesc("ipmctl_media_temperature_lower_critical_threshold_celsius",
-			"The lower media temperature critical threshold", nvm.SettingsLabelNames, nil)
+			"The lower media temperature critical threshold", labelNames(nvm.SettingsLabelNames), nil)
 		collector.mtUpperFatalThreshold = prometheus.NewDesc("ipmctl_media_temperature_upper_fatal_threshold_celsius",
-			"The upper media temperature fatal threshold", nvm.SettingsLabelNames, nil)
+			"The upper media temperature fatal threshold", labelNames(nvm.SettingsLabelNames), nil)
 		collector.mtLowerFatalThreshold = prometheus.NewDesc("ipmctl_media_temperature_lower_fatal_threshold_celsius",
-			"The lower media temperature fatal threshold", nvm.SettingsLabelNames, nil)
+			"The lower media temperature fatal threshold", labelNames(nvm.SettingsLabelNames), nil)
 		collector.mtUpperNoncriticalThreshold = prometheus.NewDesc("ipmctl_media_temperature_upper_noncritical_threshold_celsius",
-			"The upper media temperature noncritical threshold", nvm.SettingsLabelNames, nil)
+			"The upper media temperature noncritical threshold", labelNames(nvm.SettingsLabelNames), nil)
 		collector.mtLowerNoncriticalThreshold = prometheus.NewDesc("ipmctl_media_temperature_lower_noncritical_threshold_celsius",
-			"The lower media temperature noncritical threshold", nvm.SettingsLabelNames, nil)
+			"The lower media temperature noncritical threshold", labelNames(nvm.SettingsLabelNames), nil)
 		collector.ctEnabled = prometheus.NewDesc("ipmctl_controller_temperature_enabled",
-			"Indictes if firmware notifications are enabled when controller temperature value is critical", nvm.SettingsLabelNames, nil)
+			"Indictes if firmware notifications are enabled when controller temperature value is critical", labelNames(nvm.SettingsLabelNames), nil)
 		collector.ctUpperCriticalThreshold = prometheus.NewDesc("ipmctl_controller_temperature_upper_critical_threshold_celsius",
-			"The upper controller temperature critical threshold", nvm.SettingsLabelNames, nil)
+			"The upper controller temperature critical threshold", labelNames(nvm.SettingsLabelNames), nil)
 		collector.ctLowerCriticalThreshold = prometheus.NewDesc("ipmctl_controller_temperature_lower_critical_threshold_celsius",
-			"The lower controller temperature critical threshold", nvm.SettingsLabelNames, nil)
+			"The lower controller temperature critical threshold", labelNames(nvm.SettingsLabelNames), nil)
 		collector.ctUpperFatalThreshold = prometheus.NewDesc("ipmctl_controller_temperature_upper_fatal_threshold_celsius",
-			"The upper controller temperature fatal threshold", nvm.SettingsLabelNames, nil)
+			"The upper controller temperature fatal threshold", labelNames(nvm.SettingsLabelNames), nil)
 		collector.ctLowerFatalThreshold = prometheus.NewDesc("ipmctl_controller_temperature_lower_fatal_threshold_celsius",
-			"The lower controller temperature fatal threshold", nvm.SettingsLabelNames, nil)
+			"The lower controller temperature fatal threshold", labelNames(nvm.SettingsLabelNames), nil)
 		collector.ctUpperNoncriticalThreshold = prometheus.NewDesc("ipmctl_controller_temperature_upper_noncritical_threshold_celsius",
-			"The upper controller temperature noncritical threshold", nvm.SettingsLabelNames, nil)
+			"The upper controller temperature noncritical threshold", labelNames(nvm.SettingsLabelNames), nil)
 		collector.ctLowerNoncriticalThreshold = prometheus.NewDesc("ipmctl_controller_temperature_lower_noncritical_threshold_celsius",
-			"The lower controller temperature noncritical threshold", nvm.SettingsLabelNames, nil)
+			"The lower controller temperature noncritical threshold", labelNames(nvm.SettingsLabelNames), nil)
 		collector.prEnabled = prometheus.NewDesc("ipmctl_lifespan_percentage_remaining_enabled",
-			"Indictes if firmware notifications are enabled when lifespan percentage remaining value is critical", nvm.SettingsLabelNames, nil)
+			"Indictes if firmware notifications are enabled when lifespan percentage remaining value is critical", labelNames(nvm.SettingsLabelNames), nil)
 		collector.prUpperCriticalThreshold = prometheus.NewDesc("ipmctl_lifespan_percentage_remaining_upper_critical_threshold",
-			"The upper lifespan percentage remaining critical threshold", nvm.SettingsLabelNames, nil)
+			"The upper lifespan percentage remaining critical threshold", labelNames(nvm.SettingsLabelNames), nil)
 		collector.prLowerCriticalThreshold = prometheus.NewDesc("ipmctl_lifespan_percentage_remaining_lower_critical_threshold",
-			"The lower lifespan percentage remaining critical threshold", nvm.SettingsLabelNames, nil)
+			"The lower lifespan percentage remaining critical threshold", labelNames(nvm.SettingsLabelNames), nil)
 		collector.prUpperFatalThreshold = prometheus.NewDesc("ipmctl_lifespan_percentage_remaining_upper_fatal_threshold",
-			"The upper lifespan percentage remaining fatal threshold", nvm.SettingsLabelNames, nil)
+			"The upper lifespan percentage remaining fatal threshold", labelNames(nvm.SettingsLabelNames), nil)
 		collector.prLowerFatalThreshold = prometheus.NewDesc("ipmctl_lifespan_percentage_remaining_lower_fatal_threshold",
-			"The lower lifespan percentage remaining fatal threshold", nvm.SettingsLabelNames, nil)
+			"The lower lifespan percentage remaining fatal threshold", labelNames(nvm.SettingsLabelNames), nil)
 		collector.prUpperNoncriticalThreshold = prometheus.NewDesc("ipmctl_lifespan_percentage_remaining_upper_noncritical_threshold",
-			"The upper lifespan percentage remaining noncritical threshold", nvm.SettingsLabelNames, nil)
+			"The upper lifespan percentage remaining noncritical threshold", labelNames(nvm.SettingsLabelNames), nil)
 		collector.prLowerNoncriticalThreshold = prometheus.NewDesc("ipmctl_lifespan_percentage_remaining_lower_noncritical_threshold",
-			"The lower lifespan percentage remaining noncritical threshold", nvm.SettingsLabelNames, nil)
+			"The lower lifespan percentage remaining noncritical threshold", labelNames(nvm.SettingsLabelNames), nil)
+	}
+	if config.EnableDetails {
+		collector.deviceDetailsInfo = prometheus.NewDesc("ipmctl_device_details_info",
+			"Describes SMBIOS data of the DCPMM: form factor, widths, speed and slot labels", labelNames(nvm.DeviceDetailsInfoLabelNames), nil)
+		collector.peakPowerBudget = prometheus.NewDesc("ipmctl_peak_power_budget_watts",
+			"Instantaneous power budget of the DCPMM", labelNames(nvm.DeviceDetailsLabelNames), nil)
+		collector.avgPowerBudget = prometheus.NewDesc("ipmctl_avg_power_budget_watts",
+			"Average power budget of the DCPMM", labelNames(nvm.DeviceDetailsLabelNames), nil)
 	}
 	return collector
 }
 
 // Function called to describe all metrics exposed by ipmctl_exporter
 func (collector *ipmctlCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.readErrors
 	ch <- collector.totalMediaReads
 	ch <- collector.totalMediaWrites
 	ch <- collector.totalReadRequests
 	ch <- collector.totalWriteRequests
+	ch <- collector.totalBlockReads
+	ch <- collector.totalBlockWrites
+	ch <- collector.totalMediaReadBytes
+	ch <- collector.totalMediaWrittenBytes
+	ch <- collector.performanceTime
+	ch <- collector.mediaReads
+	ch <- collector.mediaWrites
+	ch <- collector.readRequests
+	ch <- collector.writeRequests
+	ch <- collector.mediaReadBytes
+	ch <- collector.mediaWrittenBytes
+	ch <- collector.pmonCounter
 	ch <- collector.health
 	ch <- collector.mediaTemperature
 	ch <- collector.controllerTemperature
@@ -177,10 +431,67 @@ func (collector *ipmctlCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- collector.powerCycles
 	ch <- collector.fwErrorCount
 	ch <- collector.unlatchedDirtyShutdownCount
+	ch <- collector.sensorState
+	ch <- collector.sensorThresholdInfo
+	ch <- collector.isNew
+	ch <- collector.isConfigured
+	ch <- collector.isMissing
+	ch <- collector.packageSparesAvailable
+	ch <- collector.lastShutdownTime
+	ch <- collector.lastShutdownStatus
+	ch <- collector.viralState
+	ch <- collector.aitDRAMEnabled
+	ch <- collector.bootStatus
+	ch <- collector.injectedMediaErrors
+	ch <- collector.injectedNonMediaErrors
+	ch <- collector.configStatus
+	ch <- collector.arsStatus
+	ch <- collector.overwriteDIMMStatus
+	ch <- collector.viralPolicyEnabled
+	ch <- collector.viralStatus
+	ch <- collector.packageSparingEnabled
+	ch <- collector.thermalThrottleLoss
+	ch <- collector.thermalThrottleEpisodes
+	ch <- collector.thermalThrottledTime
+	ch <- collector.firmwareInfo
+	ch <- collector.firmwareUpdateStatus
+	ch <- collector.firmwareActivationPending
+	ch <- collector.capacity
+	ch <- collector.socketCapacity
+	ch <- collector.socketMappedMemoryLimit
+	ch <- collector.socketTotalMappedMemory
+	ch <- collector.memoryTopologyInfo
+	ch <- collector.hostInfo
+	ch <- collector.skuViolation
+	ch <- collector.mixedSKU
+	ch <- collector.swInventoryInfo
+	ch <- collector.libraryInfo
+	ch <- collector.featureSupported
+	ch <- collector.platformCapability
+	ch <- collector.memoryModeSupported
+	ch <- collector.interleaveFormatInfo
+	ch <- collector.volatileMode
+	ch <- collector.appDirectMode
+	ch <- collector.regionInfo
+	ch <- collector.regionMember
+	ch <- collector.regionCapacity
+	ch <- collector.regionFreeCapacity
+	ch <- collector.regionHealth
+	ch <- collector.events
+	ch <- collector.fwErrorLogEntries
+	ch <- collector.fwErrorLogSequenceNumber
 	ch <- collector.deviceDiscoveryInfo
 	ch <- collector.deviceSecurityCapabilitiesInfo
 	ch <- collector.deviceCapabilitiesInfo
 	ch <- collector.ipmctlExporterInfo
+	if collector.nfitReader != nil {
+		ch <- collector.nfitSPARangeLength
+		ch <- collector.nfitRegionSize
+		ch <- collector.nfitInterleaveWays
+		ch <- collector.nfitInterleaveLineSize
+		ch <- collector.nfitStateFlags
+		ch <- collector.nfitControlRegionInfo
+	}
 	if collector.enableThresholds {
 		ch <- collector.mtEnabled
 		ch <- collector.mtUpperCriticalThreshold
@@ -204,6 +515,11 @@ func (collector *ipmctlCollector) Describe(ch chan<- *prometheus.Desc) {
 		ch <- collector.prUpperNoncriticalThreshold
 		ch <- collector.prLowerNoncriticalThreshold
 	}
+	if collector.enableDetails {
+		ch <- collector.deviceDetailsInfo
+		ch <- collector.peakPowerBudget
+		ch <- collector.avgPowerBudget
+	}
 }
 
 func addMetric(ch chan<- prometheus.Metric,
@@ -211,6 +527,11 @@ func addMetric(ch chan<- prometheus.Metric,
 	metricType prometheus.ValueType,
 	readings []nvm.MetricReading) {
 	for _, reading := range readings {
+		// skip readings not supported by backend or failed to be taken,
+		// rather than report them with a misleading zero value
+		if 0 != reading.ReadStatus {
+			continue
+		}
 		labelValues := reading.Labels.GetLabelValues()
 		ch <- prometheus.MustNewConstMetric(desc,
 			metricType,
@@ -233,6 +554,8 @@ func (collector *ipmctlCollector) Collect(ch chan<- prometheus.Metric) {
 		log.Error("ipmctl exporter - failed to read PMEM metrics due to: ", err)
 		return
 	}
+	readErrors := reader.GetReadErrors()
+	addMetric(ch, collector.readErrors, prometheus.GaugeValue, readErrors)
 	healthReadings := reader.GetHealth()
 	addMetric(ch, collector.health, prometheus.GaugeValue, healthReadings)
 	mediaTemperatureReadings := reader.GetMediaTemperature()
@@ -253,6 +576,10 @@ func (collector *ipmctlCollector) Collect(ch chan<- prometheus.Metric) {
 	addMetric(ch, collector.fwErrorCount, prometheus.CounterValue, fwErrorCountReadings)
 	UDSCReadings := reader.GetUnlatchedDirtyShutdownCount()
 	addMetric(ch, collector.unlatchedDirtyShutdownCount, prometheus.CounterValue, UDSCReadings)
+	sensorStateReadings := reader.GetSensorState()
+	addMetric(ch, collector.sensorState, prometheus.GaugeValue, sensorStateReadings)
+	sensorThresholdInfo := reader.GetSensorThresholdInfo()
+	addMetric(ch, collector.sensorThresholdInfo, prometheus.GaugeValue, sensorThresholdInfo)
 	totalMediaReads := reader.GetTotalMediaReads()
 	addMetric(ch, collector.totalMediaReads, prometheus.CounterValue, totalMediaReads)
 	totalMediaWrites := reader.GetTotalMediaWrites()
@@ -261,6 +588,124 @@ func (collector *ipmctlCollector) Collect(ch chan<- prometheus.Metric) {
 	addMetric(ch, collector.totalReadRequests, prometheus.CounterValue, totalReadRequests)
 	totalWriteRequests := reader.GetTotalWriteRequests()
 	addMetric(ch, collector.totalWriteRequests, prometheus.CounterValue, totalWriteRequests)
+	totalBlockReads := reader.GetTotalBlockReads()
+	addMetric(ch, collector.totalBlockReads, prometheus.CounterValue, totalBlockReads)
+	totalBlockWrites := reader.GetTotalBlockWrites()
+	addMetric(ch, collector.totalBlockWrites, prometheus.CounterValue, totalBlockWrites)
+	totalMediaReadBytes := reader.GetTotalMediaReadBytes()
+	addMetric(ch, collector.totalMediaReadBytes, prometheus.CounterValue, totalMediaReadBytes)
+	totalMediaWrittenBytes := reader.GetTotalMediaWrittenBytes()
+	addMetric(ch, collector.totalMediaWrittenBytes, prometheus.CounterValue, totalMediaWrittenBytes)
+	performanceTime := reader.GetPerformanceTime()
+	addMetric(ch, collector.performanceTime, prometheus.GaugeValue, performanceTime)
+	mediaReads := reader.GetMediaReads()
+	addMetric(ch, collector.mediaReads, prometheus.CounterValue, mediaReads)
+	mediaWrites := reader.GetMediaWrites()
+	addMetric(ch, collector.mediaWrites, prometheus.CounterValue, mediaWrites)
+	readRequests := reader.GetReadRequests()
+	addMetric(ch, collector.readRequests, prometheus.CounterValue, readRequests)
+	writeRequests := reader.GetWriteRequests()
+	addMetric(ch, collector.writeRequests, prometheus.CounterValue, writeRequests)
+	mediaReadBytes := reader.GetMediaReadBytes()
+	addMetric(ch, collector.mediaReadBytes, prometheus.CounterValue, mediaReadBytes)
+	mediaWrittenBytes := reader.GetMediaWrittenBytes()
+	addMetric(ch, collector.mediaWrittenBytes, prometheus.CounterValue, mediaWrittenBytes)
+	pmonCounter := reader.GetPMONCounters()
+	addMetric(ch, collector.pmonCounter, prometheus.CounterValue, pmonCounter)
+	isNew := reader.GetIsNew()
+	addMetric(ch, collector.isNew, prometheus.GaugeValue, isNew)
+	isConfigured := reader.GetIsConfigured()
+	addMetric(ch, collector.isConfigured, prometheus.GaugeValue, isConfigured)
+	isMissing := reader.GetIsMissing()
+	addMetric(ch, collector.isMissing, prometheus.GaugeValue, isMissing)
+	packageSparesAvailable := reader.GetPackageSparesAvailable()
+	addMetric(ch, collector.packageSparesAvailable, prometheus.GaugeValue, packageSparesAvailable)
+	lastShutdownTime := reader.GetLastShutdownTime()
+	addMetric(ch, collector.lastShutdownTime, prometheus.GaugeValue, lastShutdownTime)
+	lastShutdownStatus := reader.GetLastShutdownStatusInfo()
+	addMetric(ch, collector.lastShutdownStatus, prometheus.GaugeValue, lastShutdownStatus)
+	viralState := reader.GetViralState()
+	addMetric(ch, collector.viralState, prometheus.GaugeValue, viralState)
+	aitDRAMEnabled := reader.GetAitDRAMEnabled()
+	addMetric(ch, collector.aitDRAMEnabled, prometheus.GaugeValue, aitDRAMEnabled)
+	bootStatus := reader.GetBootStatus()
+	addMetric(ch, collector.bootStatus, prometheus.GaugeValue, bootStatus)
+	injectedMediaErrors := reader.GetInjectedMediaErrors()
+	addMetric(ch, collector.injectedMediaErrors, prometheus.GaugeValue, injectedMediaErrors)
+	injectedNonMediaErrors := reader.GetInjectedNonMediaErrors()
+	addMetric(ch, collector.injectedNonMediaErrors, prometheus.GaugeValue, injectedNonMediaErrors)
+	configStatus := reader.GetConfigStatus()
+	addMetric(ch, collector.configStatus, prometheus.GaugeValue, configStatus)
+	arsStatus := reader.GetARSStatus()
+	addMetric(ch, collector.arsStatus, prometheus.GaugeValue, arsStatus)
+	overwriteDIMMStatus := reader.GetOverwriteDIMMStatus()
+	addMetric(ch, collector.overwriteDIMMStatus, prometheus.GaugeValue, overwriteDIMMStatus)
+	viralPolicyEnabled := reader.GetViralPolicyEnabled()
+	addMetric(ch, collector.viralPolicyEnabled, prometheus.GaugeValue, viralPolicyEnabled)
+	viralStatus := reader.GetViralStatus()
+	addMetric(ch, collector.viralStatus, prometheus.GaugeValue, viralStatus)
+	packageSparingEnabled := reader.GetPackageSparingEnabled()
+	addMetric(ch, collector.packageSparingEnabled, prometheus.GaugeValue, packageSparingEnabled)
+	thermalThrottleLoss := reader.GetThermalThrottlePerformanceLoss()
+	addMetric(ch, collector.thermalThrottleLoss, prometheus.GaugeValue, thermalThrottleLoss)
+	thermalThrottleEpisodes := reader.GetThermalThrottleEpisodes()
+	addMetric(ch, collector.thermalThrottleEpisodes, prometheus.CounterValue, thermalThrottleEpisodes)
+	thermalThrottledTime := reader.GetThermalThrottledTime()
+	addMetric(ch, collector.thermalThrottledTime, prometheus.CounterValue, thermalThrottledTime)
+	firmwareInfo := reader.GetFirmwareInfo()
+	addMetric(ch, collector.firmwareInfo, prometheus.GaugeValue, firmwareInfo)
+	firmwareUpdateStatus := reader.GetFirmwareUpdateStatus()
+	addMetric(ch, collector.firmwareUpdateStatus, prometheus.GaugeValue, firmwareUpdateStatus)
+	firmwareActivationPending := reader.GetFirmwareActivationPending()
+	addMetric(ch, collector.firmwareActivationPending, prometheus.GaugeValue, firmwareActivationPending)
+	capacity := reader.GetCapacities()
+	addMetric(ch, collector.capacity, prometheus.GaugeValue, capacity)
+	socketCapacity := reader.GetSocketCapacities()
+	addMetric(ch, collector.socketCapacity, prometheus.GaugeValue, socketCapacity)
+	socketMappedMemoryLimit := reader.GetSocketMappedMemoryLimit()
+	addMetric(ch, collector.socketMappedMemoryLimit, prometheus.GaugeValue, socketMappedMemoryLimit)
+	socketTotalMappedMemory := reader.GetSocketTotalMappedMemory()
+	addMetric(ch, collector.socketTotalMappedMemory, prometheus.GaugeValue, socketTotalMappedMemory)
+	memoryTopologyInfo := reader.GetMemoryTopologyInfo()
+	addMetric(ch, collector.memoryTopologyInfo, prometheus.GaugeValue, memoryTopologyInfo)
+	hostInfo := reader.GetHostInfo()
+	addMetric(ch, collector.hostInfo, prometheus.GaugeValue, hostInfo)
+	skuViolation := reader.GetSKUViolation()
+	addMetric(ch, collector.skuViolation, prometheus.GaugeValue, skuViolation)
+	mixedSKU := reader.GetMixedSKU()
+	addMetric(ch, collector.mixedSKU, prometheus.GaugeValue, mixedSKU)
+	swInventoryInfo := reader.GetSwInventoryInfo()
+	addMetric(ch, collector.swInventoryInfo, prometheus.GaugeValue, swInventoryInfo)
+	libraryInfo := reader.GetLibraryInfo()
+	addMetric(ch, collector.libraryInfo, prometheus.GaugeValue, libraryInfo)
+	featureSupported := reader.GetFeatureSupported()
+	addMetric(ch, collector.featureSupported, prometheus.GaugeValue, featureSupported)
+	platformCapability := reader.GetPlatformCapabilities()
+	addMetric(ch, collector.platformCapability, prometheus.GaugeValue, platformCapability)
+	memoryModeSupported := reader.GetMemoryModeSupported()
+	addMetric(ch, collector.memoryModeSupported, prometheus.GaugeValue, memoryModeSupported)
+	interleaveFormatInfo := reader.GetInterleaveFormatInfo()
+	addMetric(ch, collector.interleaveFormatInfo, prometheus.GaugeValue, interleaveFormatInfo)
+	volatileMode := reader.GetVolatileMode()
+	addMetric(ch, collector.volatileMode, prometheus.GaugeValue, volatileMode)
+	appDirectMode := reader.GetAppDirectMode()
+	addMetric(ch, collector.appDirectMode, prometheus.GaugeValue, appDirectMode)
+	regionInfo := reader.GetRegionInfo()
+	addMetric(ch, collector.regionInfo, prometheus.GaugeValue, regionInfo)
+	regionMember := reader.GetRegionMembers()
+	addMetric(ch, collector.regionMember, prometheus.GaugeValue, regionMember)
+	regionCapacity := reader.GetRegionCapacity()
+	addMetric(ch, collector.regionCapacity, prometheus.GaugeValue, regionCapacity)
+	regionFreeCapacity := reader.GetRegionFreeCapacity()
+	addMetric(ch, collector.regionFreeCapacity, prometheus.GaugeValue, regionFreeCapacity)
+	regionHealth := reader.GetRegionHealth()
+	addMetric(ch, collector.regionHealth, prometheus.GaugeValue, regionHealth)
+	events := reader.GetEventCounts()
+	addMetric(ch, collector.events, prometheus.CounterValue, events)
+	fwErrorLogEntries := reader.GetFwErrorLogEntries()
+	addMetric(ch, collector.fwErrorLogEntries, prometheus.GaugeValue, fwErrorLogEntries)
+	fwErrorLogSequenceNumber := reader.GetFwErrorLogSequenceNumber()
+	addMetric(ch, collector.fwErrorLogSequenceNumber, prometheus.GaugeValue, fwErrorLogSequenceNumber)
 	deviceDiscoveryInfo := reader.GetDeviceDiscoveryInfo()
 	addMetric(ch, collector.deviceDiscoveryInfo, prometheus.GaugeValue, deviceDiscoveryInfo)
 	deviceSecurityCapabilitiesInfo := reader.GetDeviceSecurityCapabilitiesInfo()
@@ -272,6 +717,9 @@ func (collector *ipmctlCollector) Collect(ch chan<- prometheus.Metric) {
 		log.Error("ipmctlExporterInfo error: ", ieInfoError)
 	}
 	addMetric(ch, collector.ipmctlExporterInfo, prometheus.GaugeValue, ipmctlExporterInfo)
+	if collector.nfitReader != nil {
+		collector.collectNfit(ch)
+	}
 	if collector.enableThresholds {
 		mtEnabled := reader.GetMTEnabled()
 		addMetric(ch, collector.mtEnabled, prometheus.GaugeValue, mtEnabled)
@@ -316,20 +764,185 @@ func (collector *ipmctlCollector) Collect(ch chan<- prometheus.Metric) {
 		prLowerNoncriticalThreshold := reader.GetPRLowerNoncriticalThreshold()
 		addMetric(ch, collector.prLowerNoncriticalThreshold, prometheus.GaugeValue, prLowerNoncriticalThreshold)
 	}
+	if collector.enableDetails {
+		deviceDetailsInfo := reader.GetDeviceDetailsInfo()
+		addMetric(ch, collector.deviceDetailsInfo, prometheus.GaugeValue, deviceDetailsInfo)
+		peakPowerBudget := reader.GetPeakPowerBudget()
+		addMetric(ch, collector.peakPowerBudget, prometheus.GaugeValue, peakPowerBudget)
+		avgPowerBudget := reader.GetAvgPowerBudget()
+		addMetric(ch, collector.avgPowerBudget, prometheus.GaugeValue, avgPowerBudget)
+	}
 }
 
+// Function called to collect topology metrics decoded from ACPI NFIT table.
+// These are independent of the backend, so failure to read the table does not
+// affect any other metric.
+func (collector *ipmctlCollector) collectNfit(ch chan<- prometheus.Metric) {
+	nfit := collector.nfitReader
+	if err := nfit.Read(); err != nil {
+		log.Error("ipmctl exporter - failed to read NFIT table due to: ", err)
+		return
+	}
+	addMetric(ch, collector.nfitSPARangeLength, prometheus.GaugeValue, nfit.GetSPARangeLength())
+	addMetric(ch, collector.nfitRegionSize, prometheus.GaugeValue, nfit.GetRegionSize())
+	addMetric(ch, collector.nfitInterleaveWays, prometheus.GaugeValue, nfit.GetInterleaveWays())
+	addMetric(ch, collector.nfitInterleaveLineSize, prometheus.GaugeValue, nfit.GetInterleaveLineSize())
+	addMetric(ch, collector.nfitStateFlags, prometheus.GaugeValue, nfit.GetStateFlags())
+	addMetric(ch, collector.nfitControlRegionInfo, prometheus.GaugeValue, nfit.GetControlRegionInfo())
+}
+
+// eventsHandler serves events observed by the exporter after the cursor given
+// as a query parameter (0 when omitted) as JSON, the cursor returned should be
+// passed with the next request to get only the new ones. Events are read from
+// the backend by the reading cycle of /metrics scrape only (so they are
+// counted and recorded along with the other readings), the handler does not
+// read the event log, so no new events are served unless /metrics is scraped.
+func eventsHandler(reader *nvm.MetricsReader) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cursor := uint64(0)
+		if value := r.URL.Query().Get("cursor"); value != "" {
+			var err error
+			if cursor, err = strconv.ParseUint(value, 10, 64); err != nil {
+				http.Error(w, fmt.Sprintf("invalid cursor: %s", value), http.StatusBadRequest)
+				return
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(reader.GetEventsSince(cursor)); err != nil {
+			log.Error("ipmctl exporter - failed to serve events due to: ", err)
+		}
+	})
+}
+
+// errorLogHandler serves decoded entries of the firmware error log kept by the
+// exporter as JSON, grouped by DCPMM. Optional uid query parameter limits the
+// result to single DCPMM.
+func errorLogHandler(reader *nvm.MetricsReader) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(reader.GetErrorLogEntries(r.URL.Query().Get("uid"))); err != nil {
+			log.Error("ipmctl exporter - failed to serve error log due to: ", err)
+		}
+	})
+}
+
+// Stop releases the backend last, forwarder may be polling the event log
+// until it is stopped
 func Stop() {
-	nvm.Uninit()
+	if forwarder != nil {
+		forwarder.Stop()
+	}
+	if recorder != nil {
+		recorder.Close()
+	}
+	if backend != nil {
+		backend.Uninit()
+	}
 }
 
 var Version string
 
-func Run(port string, enableThresholds bool) {
-	nvm.Init()
+// Config gathers all settings used to run the exporter
+type Config struct {
+	// enable collection of sensor thresholds
+	EnableThresholds bool
+	// enable collection of SMBIOS data and power budgets from device details
+	EnableDetails bool
+	// source of readings: "lib" (libipmctl), "cli" (ipmctl tool), "sysfs"
+	// (nfit driver and ndctl), "sim" (simulated DCPMMs) or "replay" (readings
+	// recorded in capture file)
+	Backend string
+	// ipmctl tool used by CLI backend
+	IpmctlPath string
+	// sysfs mount point and ndctl tool used by sysfs backend
+	SysfsRoot string
+	NdctlPath string
+	// scenario file used by simulated backend
+	ScenarioFile string
+	// capture file played back by replay backend
+	CaptureFile string
+	// file where all raw readings are recorded, empty disables recording
+	RecordFile string
+	// attach location label to all sensor and performance metrics
+	LocationLabels bool
+	// enable collection of topology metrics from ACPI NFIT table
+	EnableNfit bool
+	NfitTable  string
+	// syslog address events are forwarded to (udp://host:port,
+	// tcp://host:port or unix:///dev/log), empty disables forwarding
+	SyslogAddress string
+	// interval of event log polling, and file keeping the ID of the last
+	// event forwarded across restarts
+	SyslogInterval  time.Duration
+	SyslogStateFile string
+}
+
+// backend used by exporter to collect all readings
+var backend nvm.Backend
+
+// recorder used to save raw readings, nil when recording is disabled
+var recorder *nvm.Recorder
+
+// forwarder of events to syslog, nil when forwarding is disabled
+var forwarder *nvm.EventForwarder
+
+func newBackend(config Config) (nvm.Backend, error) {
+	switch config.Backend {
+	case "", "lib":
+		return nvm.NewLibBackend(), nil
+	case "cli":
+		if config.IpmctlPath == "" {
+			return nil, fmt.Errorf("CLI backend requires path to ipmctl tool")
+		}
+		return nvm.NewCLIBackend(config.IpmctlPath), nil
+	case "sysfs":
+		if config.SysfsRoot == "" {
+			return nil, fmt.Errorf("sysfs backend requires sysfs root directory")
+		}
+		return nvm.NewSysfsBackend(config.SysfsRoot, config.NdctlPath), nil
+	case "sim":
+		if config.ScenarioFile == "" {
+			return nil, fmt.Errorf("simulated backend requires scenario file")
+		}
+		return nvm.NewSimBackend(config.ScenarioFile), nil
+	case "replay":
+		if config.CaptureFile == "" {
+			return nil, fmt.Errorf("replay backend requires capture file")
+		}
+		return nvm.NewReplayBackend(config.CaptureFile), nil
+	}
+	return nil, fmt.Errorf("unknown backend: %s", config.Backend)
+}
+
+func Run(port string, config Config) {
+	var err error
+	backend, err = newBackend(config)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if _, err = backend.Init(); err != nil {
+		log.Fatal("ipmctl exporter - failed to initialize backend due to: ", err)
+	}
 	nvm.Version = Version
-	ipmctlCollector := newIpmctlCollector(enableThresholds)
+	ipmctlCollector := newIpmctlCollector(backend, config)
+	if config.RecordFile != "" {
+		if recorder, err = nvm.NewRecorder(config.RecordFile); err != nil {
+			log.Fatal(err)
+		}
+		ipmctlCollector.metricsReader.SetRecorder(recorder)
+	}
+	if config.SyslogAddress != "" {
+		forwarder, err = nvm.NewEventForwarder(ipmctlCollector.metricsReader, config.SyslogAddress,
+			config.SyslogInterval, config.SyslogStateFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		forwarder.Start()
+	}
 	prometheus.MustRegister(ipmctlCollector)
 	http.Handle("/metrics", promhttp.Handler())
+	http.Handle("/events", eventsHandler(ipmctlCollector.metricsReader))
+	http.Handle("/error-log", errorLogHandler(ipmctlCollector.metricsReader))
 	http.Handle("/", promhttp.Handler())
 	port = ":" + port
 	if err := http.ListenAndServe(port, nil); err != nil {
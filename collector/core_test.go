@@ -0,0 +1,178 @@
+/**
+ * Copyright (c) 2020-2021, Intel Corporation.
+ * SPDX-License-Identifier: BSD-3-Clause
+ **
+ * core_test.go file drives ipmctlCollector with the simulated backend, so
+ * all the metrics are described and collected without libipmctl or DCPMM
+ * hardware present.
+ */
+
+package collector
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/intel/ipmctl_exporter/collector/nvm"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func newTestCollector(t *testing.T, config Config) *ipmctlCollector {
+	backend := nvm.NewSimBackend("testdata/scenario.yaml")
+	if _, err := backend.Init(); err != nil {
+		t.Fatalf("failed to initialize simulated backend: %v", err)
+	}
+	return newIpmctlCollector(backend, config)
+}
+
+func TestCollect(t *testing.T) {
+	collector := newTestCollector(t, Config{EnableThresholds: true, EnableDetails: true})
+	// pedantic registry checks every metric collected against its
+	// description, so inconsistent label names fail the gathering
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector)
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+	counts := make(map[string]int)
+	for _, family := range families {
+		counts[family.GetName()] = len(family.GetMetric())
+	}
+	expected := map[string]int{
+		"ipmctl_health":                                          2,
+		"ipmctl_read_errors":                                     2,
+		"ipmctl_media_temperature_celsius":                       2,
+		"ipmctl_total_media_reads_total":                         2,
+		"ipmctl_media_read_bytes_total":                          2,
+		"ipmctl_power_cycle_media_read_bytes_total":              2,
+		"ipmctl_power_cycle_media_reads_total":                   2,
+		"ipmctl_sensor_state":                                    2 * nvm.NumberOfAvailableSensors * 5,
+		"ipmctl_media_temperature_upper_fatal_threshold_celsius": 2,
+		"ipmctl_device_details_info":                             2,
+		"ipmctl_package_sparing_enabled":                         2,
+		"ipmctl_device_discovery_info":                           2,
+	}
+	for name, count := range expected {
+		if counts[name] != count {
+			t.Errorf("expected %d %s metrics, got %d", count, name, counts[name])
+		}
+	}
+}
+
+func TestCollectDetails(t *testing.T) {
+	collector := newTestCollector(t, Config{EnableDetails: true})
+	expected := `
+# HELP ipmctl_avg_power_budget_watts Average power budget of the DCPMM
+# TYPE ipmctl_avg_power_budget_watts gauge
+ipmctl_avg_power_budget_watts{uid="8089-a2-1901-00001000"} 15
+ipmctl_avg_power_budget_watts{uid="8089-a2-1901-00001001"} 15
+`
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected),
+		"ipmctl_avg_power_budget_watts"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCollectPMONCounters(t *testing.T) {
+	collector := newTestCollector(t, Config{})
+	var expected strings.Builder
+	expected.WriteString(`
+# HELP ipmctl_pmon_counter_total Value of the PMON counter of the DCPMM, events counted depend on the PMON group enabled (F means none)
+# TYPE ipmctl_pmon_counter_total counter
+`)
+	// PMON 6 has no counter in the PMON registers payload
+	for _, uid := range []string{"8089-a2-1901-00001000", "8089-a2-1901-00001001"} {
+		for _, counter := range []string{"14", "4", "5", "7", "8", "9"} {
+			fmt.Fprintf(&expected, "ipmctl_pmon_counter_total{counter=%q,group=\"F\",uid=%q} 0\n", counter, uid)
+		}
+	}
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected.String()),
+		"ipmctl_pmon_counter_total"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCollectOptInDisabled(t *testing.T) {
+	collector := newTestCollector(t, Config{})
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector)
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+	collected := make(map[string]bool)
+	for _, family := range families {
+		collected[family.GetName()] = true
+		switch family.GetName() {
+		case "ipmctl_device_details_info", "ipmctl_media_temperature_enabled":
+			t.Errorf("opt-in metric %s collected although not enabled", family.GetName())
+		}
+	}
+	for _, name := range []string{"ipmctl_package_sparing_enabled", "ipmctl_viral_policy_enabled", "ipmctl_sensor_threshold_info"} {
+		if !collected[name] {
+			t.Errorf("metric %s not collected by default", name)
+		}
+	}
+}
+
+// TestCollectSysfs reads the fake sysfs tree the way -backend sysfs
+// -sysfs-root does without ndctl, readings not available in sysfs are not
+// collected at all
+func TestCollectSysfs(t *testing.T) {
+	backend, err := newBackend(Config{Backend: "sysfs", SysfsRoot: "nvm/testdata/sysfs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := backend.Init(); err != nil {
+		t.Fatalf("failed to initialize sysfs backend: %v", err)
+	}
+	collector := newIpmctlCollector(backend, Config{})
+	expected := `
+# HELP ipmctl_latched_dirty_shutdown_count_total Device shutdowns without notification
+# TYPE ipmctl_latched_dirty_shutdown_count_total counter
+ipmctl_latched_dirty_shutdown_count_total{uid="8089-a2-1951-00001234"} 3
+ipmctl_latched_dirty_shutdown_count_total{uid="8089-a2-1951-00005678"} 0
+`
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected),
+		"ipmctl_latched_dirty_shutdown_count_total", "ipmctl_health",
+		"ipmctl_media_temperature_celsius", "ipmctl_total_media_reads_total"); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestCollectLocationLabels runs collectors with and without location labels
+// side by side, the option of one must not change label names of the other
+func TestCollectLocationLabels(t *testing.T) {
+	for _, enabled := range []bool{true, false, true} {
+		collector := newTestCollector(t, Config{LocationLabels: enabled})
+		registry := prometheus.NewPedanticRegistry()
+		registry.MustRegister(collector)
+		families, err := registry.Gather()
+		if err != nil {
+			t.Fatalf("failed to gather metrics: %v", err)
+		}
+		locations := make([]string, 0)
+		for _, family := range families {
+			if family.GetName() != "ipmctl_health" {
+				continue
+			}
+			for _, metric := range family.GetMetric() {
+				for _, label := range metric.GetLabel() {
+					if label.GetName() == "location" {
+						locations = append(locations, label.GetValue())
+					}
+				}
+			}
+		}
+		expected := []string{}
+		if enabled {
+			expected = []string{"CPU0_IMC0_CH0_DIMM0", "CPU0_IMC0_CH1_DIMM0"}
+		}
+		if strings.Join(locations, ",") != strings.Join(expected, ",") {
+			t.Errorf("location labels enabled %t: expected %v, got %v", enabled, expected, locations)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+/**
+ * Copyright (c) 2020-2021, Intel Corporation.
+ * SPDX-License-Identifier: BSD-3-Clause
+ **
+ * This package introduces wrapper for ipmctl library written in C.
+ * api_backend.go file defines the Backend interface, which describes the
+ * source of all raw readings consumed by MetricsReader. The default backend
+ * is libBackend (see api_lib_backend.go), which forwards every call to the
+ * libipmctl wrappers from api_lib.go. Other backends may be plugged in to
+ * drive the exporter without libipmctl or DCPMM hardware present in the
+ * system.
+ */
+
+package nvm
+
+import (
+	"fmt"
+)
+
+// Backend is a source of raw DCPMM readings used by MetricsReader. Every
+// method follows the conventions of the api_lib.go wrappers: it returns
+// the operation status first and the error object last.
+type Backend interface {
+	// Init prepares the backend before the first reading is taken
+	Init() (bool, error)
+	// Uninit releases all resources acquired by the backend
+	Uninit() (bool, error)
+	// GetNumberOfDevices is called at the beginning of every reading cycle
+	GetNumberOfDevices() (nvmStatusCodeEnumAttr, nvmUint8, error)
+	GetDevices(count nvmUint8) (nvmStatusCodeEnumAttr, []deviceDiscovery, error)
+	GetDeviceDiscovery(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceDiscovery, error)
+	GetSensor(deviceUID nvmUID, stype sensorTypeEnumAttr) (nvmStatusCodeEnumAttr, sensor, error)
+	GetDevicePerformance(deviceUID nvmUID) (nvmStatusCodeEnumAttr, devicePerformance, error)
+	GetDeviceStatus(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceStatus, error)
+	GetDeviceFwImageInfo(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceFWInfo, error)
+	GetDeviceCapacities(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceCapacities, error)
+	// GetDeviceSettings returns the viral policy of the device
+	GetDeviceSettings(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceSettings, error)
+	// GetDeviceDetails returns SMBIOS data, power budgets and package
+	// sparing policy of the device, readings embedded in the details which
+	// are taken by the other methods (e.g. sensors) may be left empty
+	GetDeviceDetails(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceDetails, error)
+	GetRegions() (nvmStatusCodeEnumAttr, []region, error)
+	// GetEvents returns all the events currently stored in the event log,
+	// events already seen are filtered out by MetricsReader
+	GetEvents() (nvmStatusCodeEnumAttr, []event, error)
+	GetSockets() (nvmStatusCodeEnumAttr, []socket, error)
+	// GetMemoryTopology returns all the memory devices installed in the
+	// system, DDR4 DIMMs as well as DCPMMs
+	GetMemoryTopology() (nvmStatusCodeEnumAttr, []memoryTopology, error)
+	// GetHost returns the name and operating system of the host server, and
+	// if the DCPMMs installed in it are affected by any SKU issue
+	GetHost() (nvmStatusCodeEnumAttr, host, error)
+	GetSwInventory() (nvmStatusCodeEnumAttr, swInventory, error)
+	// GetLibraryVersion returns version of libipmctl the readings are taken
+	// with
+	GetLibraryVersion() (nvmStatusCodeEnumAttr, libraryVersion, error)
+	// GetNVMCapabilities returns features supported by the host software,
+	// and memory modes supported and selected by the BIOS
+	GetNVMCapabilities() (nvmStatusCodeEnumAttr, nvmCapabilities, error)
+	// GetPMONRegisters returns the performance monitor registers of the
+	// device, counters of the DDRT and media traffic start over on every
+	// power cycle
+	GetPMONRegisters(deviceUID nvmUID) (nvmStatusCodeEnumAttr, pmonRegisters, error)
+	// GetFwErrLogStats returns the oldest and current sequence numbers of
+	// all the logs of the firmware error log
+	GetFwErrLogStats(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceErrorLogStatus, error)
+	GetFwErrorLogEntry(deviceUID nvmUID,
+		sequenceNumber nvmUint16,
+		logLevel errorLogLevelEnumAttr,
+		logType errorLogTypeEnumAttr) (nvmStatusCodeEnumAttr, errorLog, error)
+}
+
+// detailedBackend may be implemented by backends, which device details embed
+// the capacities and sensor readings of the device (libipmctl). MetricsReader
+// takes these from the details, which are read in every reading cycle anyway,
+// instead of calling GetDeviceCapacities and GetSensor one by one.
+type detailedBackend interface {
+	embedsDetailsReadings() bool
+}
+
+// unsupportedBackend may be embedded by backends, which are not able to
+// provide all the readings. Every reading method reports the operation as
+// not supported by the backend.
+type unsupportedBackend struct{}
+
+func (backend *unsupportedBackend) Init() (bool, error) {
+	return true, nil
+}
+
+func (backend *unsupportedBackend) Uninit() (bool, error) {
+	return true, nil
+}
+
+func (backend *unsupportedBackend) GetNumberOfDevices() (nvmStatusCodeEnumAttr, nvmUint8, error) {
+	return nvmStatusCodeEnum.nvmErrAPINotSupported, 0, fmt.Errorf("Method is not supported by backend")
+}
+
+func (backend *unsupportedBackend) GetDevices(count nvmUint8) (nvmStatusCodeEnumAttr, []deviceDiscovery, error) {
+	return nvmStatusCodeEnum.nvmErrAPINotSupported, nil, fmt.Errorf("Method is not supported by backend")
+}
+
+func (backend *unsupportedBackend) GetDeviceDiscovery(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceDiscovery, error) {
+	return nvmStatusCodeEnum.nvmErrAPINotSupported, deviceDiscovery{}, fmt.Errorf("Method is not supported by backend")
+}
+
+func (backend *unsupportedBackend) GetSensor(deviceUID nvmUID,
+	stype sensorTypeEnumAttr) (nvmStatusCodeEnumAttr, sensor, error) {
+	return nvmStatusCodeEnum.nvmErrAPINotSupported, sensor{}, fmt.Errorf("Method is not supported by backend")
+}
+
+func (backend *unsupportedBackend) GetDevicePerformance(deviceUID nvmUID) (nvmStatusCodeEnumAttr,
+	devicePerformance,
+	error) {
+	return nvmStatusCodeEnum.nvmErrAPINotSupported, devicePerformance{}, fmt.Errorf("Method is not supported by backend")
+}
+
+func (backend *unsupportedBackend) GetDeviceStatus(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceStatus, error) {
+	return nvmStatusCodeEnum.nvmErrAPINotSupported, deviceStatus{}, fmt.Errorf("Method is not supported by backend")
+}
+
+func (backend *unsupportedBackend) GetDeviceFwImageInfo(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceFWInfo, error) {
+	return nvmStatusCodeEnum.nvmErrAPINotSupported, deviceFWInfo{}, fmt.Errorf("Method is not supported by backend")
+}
+
+func (backend *unsupportedBackend) GetDeviceCapacities(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceCapacities, error) {
+	return nvmStatusCodeEnum.nvmErrAPINotSupported, deviceCapacities{}, fmt.Errorf("Method is not supported by backend")
+}
+
+func (backend *unsupportedBackend) GetDeviceSettings(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceSettings, error) {
+	return nvmStatusCodeEnum.nvmErrAPINotSupported, deviceSettings{}, fmt.Errorf("Method is not supported by backend")
+}
+
+func (backend *unsupportedBackend) GetDeviceDetails(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceDetails, error) {
+	return nvmStatusCodeEnum.nvmErrAPINotSupported, deviceDetails{}, fmt.Errorf("Method is not supported by backend")
+}
+
+func (backend *unsupportedBackend) GetRegions() (nvmStatusCodeEnumAttr, []region, error) {
+	return nvmStatusCodeEnum.nvmErrAPINotSupported, nil, fmt.Errorf("Method is not supported by backend")
+}
+
+func (backend *unsupportedBackend) GetEvents() (nvmStatusCodeEnumAttr, []event, error) {
+	return nvmStatusCodeEnum.nvmErrAPINotSupported, nil, fmt.Errorf("Method is not supported by backend")
+}
+
+func (backend *unsupportedBackend) GetSockets() (nvmStatusCodeEnumAttr, []socket, error) {
+	return nvmStatusCodeEnum.nvmErrAPINotSupported, nil, fmt.Errorf("Method is not supported by backend")
+}
+
+func (backend *unsupportedBackend) GetMemoryTopology() (nvmStatusCodeEnumAttr, []memoryTopology, error) {
+	return nvmStatusCodeEnum.nvmErrAPINotSupported, nil, fmt.Errorf("Method is not supported by backend")
+}
+
+func (backend *unsupportedBackend) GetHost() (nvmStatusCodeEnumAttr, host, error) {
+	return nvmStatusCodeEnum.nvmErrAPINotSupported, host{}, fmt.Errorf("Method is not supported by backend")
+}
+
+func (backend *unsupportedBackend) GetSwInventory() (nvmStatusCodeEnumAttr, swInventory, error) {
+	return nvmStatusCodeEnum.nvmErrAPINotSupported, swInventory{}, fmt.Errorf("Method is not supported by backend")
+}
+
+func (backend *unsupportedBackend) GetLibraryVersion() (nvmStatusCodeEnumAttr, libraryVersion, error) {
+	return nvmStatusCodeEnum.nvmErrAPINotSupported, libraryVersion{}, fmt.Errorf("Method is not supported by backend")
+}
+
+func (backend *unsupportedBackend) GetNVMCapabilities() (nvmStatusCodeEnumAttr, nvmCapabilities, error) {
+	return nvmStatusCodeEnum.nvmErrAPINotSupported, nvmCapabilities{}, fmt.Errorf("Method is not supported by backend")
+}
+
+func (backend *unsupportedBackend) GetPMONRegisters(deviceUID nvmUID) (nvmStatusCodeEnumAttr, pmonRegisters, error) {
+	return nvmStatusCodeEnum.nvmErrAPINotSupported, pmonRegisters{}, fmt.Errorf("Method is not supported by backend")
+}
+
+func (backend *unsupportedBackend) GetFwErrLogStats(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceErrorLogStatus, error) {
+	return nvmStatusCodeEnum.nvmErrAPINotSupported, deviceErrorLogStatus{}, fmt.Errorf("Method is not supported by backend")
+}
+
+func (backend *unsupportedBackend) GetFwErrorLogEntry(deviceUID nvmUID,
+	sequenceNumber nvmUint16,
+	logLevel errorLogLevelEnumAttr,
+	logType errorLogTypeEnumAttr) (nvmStatusCodeEnumAttr, errorLog, error) {
+	return nvmStatusCodeEnum.nvmErrAPINotSupported, errorLog{}, fmt.Errorf("Method is not supported by backend")
+}
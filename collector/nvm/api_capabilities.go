@@ -0,0 +1,318 @@
+/**
+ * Copyright (c) 2020-2021, Intel Corporation.
+ * SPDX-License-Identifier: BSD-3-Clause
+ **
+ * This package introduces wrapper for ipmctl library written in C.
+ * api_capabilities.go file exposes external API for exporter to collect
+ * capabilities of the platform: features supported by the host software,
+ * memory modes and interleave formats supported by the BIOS, and the modes
+ * currently selected by the BIOS.
+ */
+
+package nvm
+
+var FeatureLabelNames = []string{
+	"feature",
+}
+
+var PlatformCapabilityLabelNames = []string{
+	"capability",
+}
+
+var MemoryModeLabelNames = []string{
+	"mode",
+}
+
+var InterleaveFormatLabelNames = []string{
+	"mode",
+	"channel",
+	"imc",
+	"ways",
+	"recommended",
+}
+
+var ModeStateLabelNames = []string{
+	"state",
+}
+
+var capabilityMetricTypeEnum = &capabilityMetricType{
+	feature:          0,
+	platform:         1,
+	memoryMode:       2,
+	interleaveFormat: 3,
+	volatileMode:     4,
+	appDirectMode:    5,
+	unknown:          0xFF,
+}
+
+type capabilityReading MetricReading
+type featureLabels MetricLabels
+type platformCapabilityLabels MetricLabels
+type memoryModeLabels MetricLabels
+type interleaveFormatLabels MetricLabels
+type modeStateLabels MetricLabels
+type capabilityMetricTypeEnumAttr enumAttr
+type capabilityMetricType struct {
+	feature          capabilityMetricTypeEnumAttr
+	platform         capabilityMetricTypeEnumAttr
+	memoryMode       capabilityMetricTypeEnumAttr
+	interleaveFormat capabilityMetricTypeEnumAttr
+	volatileMode     capabilityMetricTypeEnumAttr
+	appDirectMode    capabilityMetricTypeEnumAttr
+	unknown          capabilityMetricTypeEnumAttr
+}
+
+// nvmFeatureNames lists features of the host software by their label values,
+// the accessor is shared by metrics and captures
+var nvmFeatureNames = []struct {
+	name  string
+	value func(features *nvmFeatures) *nvmBool
+}{
+	{"get_platform_capabilities", func(f *nvmFeatures) *nvmBool { return &f.getPlatformCapabilities }},
+	{"get_devices", func(f *nvmFeatures) *nvmBool { return &f.getDevices }},
+	{"get_device_smbios", func(f *nvmFeatures) *nvmBool { return &f.getDeviceSMBIOS }},
+	{"get_device_health", func(f *nvmFeatures) *nvmBool { return &f.getDeviceHealth }},
+	{"get_device_settings", func(f *nvmFeatures) *nvmBool { return &f.getDeviceSettings }},
+	{"modify_device_settings", func(f *nvmFeatures) *nvmBool { return &f.modifyDeviceSettings }},
+	{"get_device_security", func(f *nvmFeatures) *nvmBool { return &f.getDeviceSecurity }},
+	{"modify_device_security", func(f *nvmFeatures) *nvmBool { return &f.modifyDeviceSecurity }},
+	{"get_device_performance", func(f *nvmFeatures) *nvmBool { return &f.getDevicePerformance }},
+	{"get_device_firmware", func(f *nvmFeatures) *nvmBool { return &f.getDeviceDeviceFirmware }},
+	{"update_device_firmware", func(f *nvmFeatures) *nvmBool { return &f.updateDeviceFirmware }},
+	{"get_sensors", func(f *nvmFeatures) *nvmBool { return &f.getSensors }},
+	{"modify_sensors", func(f *nvmFeatures) *nvmBool { return &f.modifySensors }},
+	{"get_device_capacity", func(f *nvmFeatures) *nvmBool { return &f.getDeviceCapacity }},
+	{"modify_device_capacity", func(f *nvmFeatures) *nvmBool { return &f.modifyDeviceCapacity }},
+	{"get_regions", func(f *nvmFeatures) *nvmBool { return &f.getRegions }},
+	{"get_namespaces", func(f *nvmFeatures) *nvmBool { return &f.getNamespaces }},
+	{"get_namespace_details", func(f *nvmFeatures) *nvmBool { return &f.getNamespaceDetails }},
+	{"create_namespace", func(f *nvmFeatures) *nvmBool { return &f.createNamespace }},
+	{"enable_namespace", func(f *nvmFeatures) *nvmBool { return &f.enableNamespace }},
+	{"disable_namespace", func(f *nvmFeatures) *nvmBool { return &f.disableNamespace }},
+	{"delete_namespace", func(f *nvmFeatures) *nvmBool { return &f.deleteNamespace }},
+	{"get_address_scrub_data", func(f *nvmFeatures) *nvmBool { return &f.getAddressScrubData }},
+	{"start_address_scrub", func(f *nvmFeatures) *nvmBool { return &f.startAddressScrub }},
+	{"quick_diagnostic", func(f *nvmFeatures) *nvmBool { return &f.quickDiagnostic }},
+	{"platform_config_diagnostic", func(f *nvmFeatures) *nvmBool { return &f.platformConfigDiagnostic }},
+	{"pm_metadata_diagnostic", func(f *nvmFeatures) *nvmBool { return &f.pmMetadataDiagnostic }},
+	{"security_diagnostic", func(f *nvmFeatures) *nvmBool { return &f.securityDiagnostic }},
+	{"fw_consistency_diagnostic", func(f *nvmFeatures) *nvmBool { return &f.fwConsistencyDiagnostic }},
+	{"memory_mode", func(f *nvmFeatures) *nvmBool { return &f.memoryMode }},
+	{"app_direct_mode", func(f *nvmFeatures) *nvmBool { return &f.appDirectMode }},
+	{"error_injection", func(f *nvmFeatures) *nvmBool { return &f.errorInjection }},
+}
+
+// platformCapabilityNames lists capabilities of the platform (BIOS, driver
+// and DCPMM SKUs) by their label values. Mixed SKU and SKU violation are
+// reported by the host metrics already.
+var platformCapabilityNames = []struct {
+	name  string
+	value func(capabilities *nvmCapabilities) *nvmBool
+}{
+	{"bios_config", func(c *nvmCapabilities) *nvmBool { return &c.platformCapabilities.biosConfigSupport }},
+	{"bios_runtime", func(c *nvmCapabilities) *nvmBool { return &c.platformCapabilities.biosRuntimeSupport }},
+	{"memory_mirror", func(c *nvmCapabilities) *nvmBool { return &c.platformCapabilities.memoryMirrorSupported }},
+	{"memory_spare", func(c *nvmCapabilities) *nvmBool { return &c.platformCapabilities.memorySpareSupported }},
+	{"memory_migration", func(c *nvmCapabilities) *nvmBool { return &c.platformCapabilities.memoryMigrationSupported }},
+	{"namespace_memory_page_allocation", func(c *nvmCapabilities) *nvmBool {
+		return &c.swCapabilities.namespaceMemoryPageAllocationCapable
+	}},
+	{"memory_sku", func(c *nvmCapabilities) *nvmBool { return &c.dimmSKUCapabilities.memorySKU }},
+	{"app_direct_sku", func(c *nvmCapabilities) *nvmBool { return &c.dimmSKUCapabilities.appDirectSKU }},
+}
+
+// memoryModeNames lists memory modes of the platform by their label values
+var memoryModeNames = []struct {
+	name  string
+	value func(capabilities *platformCapabilities) *memoryCapabilities
+}{
+	{"1lm", func(c *platformCapabilities) *memoryCapabilities { return &c.oneLMMode }},
+	{"memory", func(c *platformCapabilities) *memoryCapabilities { return &c.memoryMode }},
+	{"app_direct", func(c *platformCapabilities) *memoryCapabilities { return &c.appDirectMode }},
+}
+
+func (fl featureLabels) GetLabelValues() []string {
+	return getValuesByName(FeatureLabelNames, MetricLabels(fl).labels)
+}
+
+func (fl featureLabels) GetLabelNames() []string {
+	return FeatureLabelNames
+}
+
+func (fl featureLabels) addLabel(name string, value string) {
+	MetricLabels(fl).labels[name] = value
+}
+
+func (pl platformCapabilityLabels) GetLabelValues() []string {
+	return getValuesByName(PlatformCapabilityLabelNames, MetricLabels(pl).labels)
+}
+
+func (pl platformCapabilityLabels) GetLabelNames() []string {
+	return PlatformCapabilityLabelNames
+}
+
+func (pl platformCapabilityLabels) addLabel(name string, value string) {
+	MetricLabels(pl).labels[name] = value
+}
+
+func (ml memoryModeLabels) GetLabelValues() []string {
+	return getValuesByName(MemoryModeLabelNames, MetricLabels(ml).labels)
+}
+
+func (ml memoryModeLabels) GetLabelNames() []string {
+	return MemoryModeLabelNames
+}
+
+func (ml memoryModeLabels) addLabel(name string, value string) {
+	MetricLabels(ml).labels[name] = value
+}
+
+func (il interleaveFormatLabels) GetLabelValues() []string {
+	return getValuesByName(InterleaveFormatLabelNames, MetricLabels(il).labels)
+}
+
+func (il interleaveFormatLabels) GetLabelNames() []string {
+	return InterleaveFormatLabelNames
+}
+
+func (il interleaveFormatLabels) addLabel(name string, value string) {
+	MetricLabels(il).labels[name] = value
+}
+
+func (sl modeStateLabels) GetLabelValues() []string {
+	return getValuesByName(ModeStateLabelNames, MetricLabels(sl).labels)
+}
+
+func (sl modeStateLabels) GetLabelNames() []string {
+	return ModeStateLabelNames
+}
+
+func (sl modeStateLabels) addLabel(name string, value string) {
+	MetricLabels(sl).labels[name] = value
+}
+
+func newCapabilityReading(readStatus nvmStatusCodeEnumAttr,
+	metricType capabilityMetricTypeEnumAttr,
+	metricValue nvmUint64,
+	labels Labels) *capabilityReading {
+	capabilityReading := new(capabilityReading)
+	capabilityReading.ReadStatus = int(readStatus)
+	capabilityReading.MetricType = uint8(metricType)
+	capabilityReading.MetricValue = float64(metricValue)
+	capabilityReading.Labels = labels
+	return capabilityReading
+}
+
+// Indicates which features are supported by the host software
+func (reader *MetricsReader) GetFeatureSupported() []MetricReading {
+	platform := reader.platform
+	results := make([]MetricReading, len(nvmFeatureNames))
+	for i, feature := range nvmFeatureNames {
+		capabilityReading := *newCapabilityReading(platform.capabilitiesOpstat, capabilityMetricTypeEnum.feature,
+			feature.value(&platform.capabilities.nvmFeatures).toNvmUint64(), featureLabels(*newMetricLabels()))
+		capabilityReading.Labels.addLabel("feature", feature.name)
+		results[i] = MetricReading(capabilityReading)
+	}
+	return results
+}
+
+// Indicates which capabilities are supported by the platform (BIOS, driver
+// and DCPMM SKUs)
+func (reader *MetricsReader) GetPlatformCapabilities() []MetricReading {
+	platform := reader.platform
+	results := make([]MetricReading, len(platformCapabilityNames))
+	for i, capability := range platformCapabilityNames {
+		capabilityReading := *newCapabilityReading(platform.capabilitiesOpstat, capabilityMetricTypeEnum.platform,
+			capability.value(&platform.capabilities).toNvmUint64(), platformCapabilityLabels(*newMetricLabels()))
+		capabilityReading.Labels.addLabel("capability", capability.name)
+		results[i] = MetricReading(capabilityReading)
+	}
+	return results
+}
+
+// Indicates which memory modes (1LM, Memory Mode, App Direct) are supported
+// by the BIOS
+func (reader *MetricsReader) GetMemoryModeSupported() []MetricReading {
+	platform := reader.platform
+	results := make([]MetricReading, len(memoryModeNames))
+	for i, mode := range memoryModeNames {
+		capabilities := mode.value(&platform.capabilities.platformCapabilities)
+		capabilityReading := *newCapabilityReading(platform.capabilitiesOpstat, capabilityMetricTypeEnum.memoryMode,
+			capabilities.supported.toNvmUint64(), memoryModeLabels(*newMetricLabels()))
+		capabilityReading.Labels.addLabel("mode", mode.name)
+		results[i] = MetricReading(capabilityReading)
+	}
+	return results
+}
+
+// Describes every interleave format supported by the BIOS for given memory
+// mode, and if the format is recommended
+func (reader *MetricsReader) GetInterleaveFormatInfo() []MetricReading {
+	platform := reader.platform
+	results := make([]MetricReading, 0)
+	for _, mode := range memoryModeNames {
+		capabilities := mode.value(&platform.capabilities.platformCapabilities)
+		count := int(capabilities.interleaveFormatsCount)
+		if count > len(capabilities.interleaveFormats) {
+			count = len(capabilities.interleaveFormats)
+		}
+		for i := 0; i < count; i++ {
+			format := &capabilities.interleaveFormats[i]
+			capabilityReading := *newCapabilityReading(platform.capabilitiesOpstat, capabilityMetricTypeEnum.interleaveFormat,
+				1, interleaveFormatLabels(*newMetricLabels()))
+			capabilityReading.Labels.addLabel("mode", mode.name)
+			capabilityReading.Labels.addLabel("channel", getInterleaveSizeName(format.channel))
+			capabilityReading.Labels.addLabel("imc", getInterleaveSizeName(format.imc))
+			capabilityReading.Labels.addLabel("ways", getInterleaveWaysName(format.ways))
+			capabilityReading.Labels.addLabel("recommended", format.recommended.toString(10))
+			results = append(results, MetricReading(capabilityReading))
+		}
+	}
+	return results
+}
+
+// Volatile memory mode currently selected by the BIOS, 1 for the current
+// state
+func (reader *MetricsReader) GetVolatileMode() []MetricReading {
+	states := []volatileModeEnumAttr{
+		volatileModeEnum.volatileMode1LM,
+		volatileModeEnum.volatileModeMemory,
+		volatileModeEnum.volatileModeAuto,
+		volatileModeEnum.volatileModeUnknown,
+	}
+	platform := reader.platform
+	results := make([]MetricReading, len(states))
+	for i, state := range states {
+		metricValue := nvmUint64(0)
+		if state == platform.capabilities.platformCapabilities.currentVolatileMode {
+			metricValue = 1
+		}
+		capabilityReading := *newCapabilityReading(platform.capabilitiesOpstat, capabilityMetricTypeEnum.volatileMode,
+			metricValue, modeStateLabels(*newMetricLabels()))
+		capabilityReading.Labels.addLabel("state", getVolatileModeName(state))
+		results[i] = MetricReading(capabilityReading)
+	}
+	return results
+}
+
+// App Direct mode currently selected by the BIOS, 1 for the current state
+func (reader *MetricsReader) GetAppDirectMode() []MetricReading {
+	states := []appDirectModeEnumAttr{
+		appDirectModeEnum.appDirectModeDisabled,
+		appDirectModeEnum.appDirectModeEnabled,
+		appDirectModeEnum.appDirectModeUnknown,
+	}
+	platform := reader.platform
+	results := make([]MetricReading, len(states))
+	for i, state := range states {
+		metricValue := nvmUint64(0)
+		if state == platform.capabilities.platformCapabilities.currentAppDirectMode {
+			metricValue = 1
+		}
+		capabilityReading := *newCapabilityReading(platform.capabilitiesOpstat, capabilityMetricTypeEnum.appDirectMode,
+			metricValue, modeStateLabels(*newMetricLabels()))
+		capabilityReading.Labels.addLabel("state", getAppDirectModeName(state))
+		results[i] = MetricReading(capabilityReading)
+	}
+	return results
+}
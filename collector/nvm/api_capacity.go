@@ -0,0 +1,152 @@
+/**
+ * Copyright (c) 2020-2021, Intel Corporation.
+ * SPDX-License-Identifier: BSD-3-Clause
+ **
+ * This package introduces wrapper for ipmctl library written in C.
+ * api_capacity.go file exposes external API for exporter to collect
+ * capacities of NVM devices broken down by the way they are provisioned
+ * (Memory Mode, App Direct etc.), both per device and per socket.
+ */
+
+package nvm
+
+import (
+	"sort"
+)
+
+var CapacityLabelNames = []string{
+	"uid",
+	"class",
+}
+
+var SocketCapacityLabelNames = []string{
+	"socket_id",
+	"class",
+}
+
+var capacityTypeEnum = &capacityType{
+	device:  0,
+	socket:  1,
+	unknown: 0xFF,
+}
+
+type capacityReading MetricReading
+type capacityLabels MetricLabels
+type socketCapacityLabels MetricLabels
+type capacityTypeEnumAttr enumAttr
+type capacityType struct {
+	device  capacityTypeEnumAttr
+	socket  capacityTypeEnumAttr
+	unknown capacityTypeEnumAttr
+}
+
+// capacityClass maps value of class label to the capacity it describes
+type capacityClass struct {
+	name     string
+	capacity func(capacities *deviceCapacities) nvmUint64
+}
+
+var capacityClasses = []capacityClass{
+	{"total", func(c *deviceCapacities) nvmUint64 { return c.capacity }},
+	{"memory", func(c *deviceCapacities) nvmUint64 { return c.memoryCapacity }},
+	{"app_direct", func(c *deviceCapacities) nvmUint64 { return c.appDirectoryCapacity }},
+	{"mirrored_app_direct", func(c *deviceCapacities) nvmUint64 { return c.mirroredAPPDirectCapacity }},
+	{"unconfigured", func(c *deviceCapacities) nvmUint64 { return c.unconfiguredCapacity }},
+	{"inaccessible", func(c *deviceCapacities) nvmUint64 { return c.inaccessibleCapacity }},
+	{"reserved", func(c *deviceCapacities) nvmUint64 { return c.reservedCapacity }},
+}
+
+func (cl capacityLabels) GetLabelValues() []string {
+	return getValuesByName(CapacityLabelNames, MetricLabels(cl).labels)
+}
+
+func (cl capacityLabels) GetLabelNames() []string {
+	return getNamesByLabels(CapacityLabelNames, MetricLabels(cl).labels)
+}
+
+func (cl capacityLabels) addLabel(name string, value string) {
+	MetricLabels(cl).labels[name] = value
+}
+
+func (cl socketCapacityLabels) GetLabelValues() []string {
+	return getValuesByName(SocketCapacityLabelNames, MetricLabels(cl).labels)
+}
+
+func (cl socketCapacityLabels) GetLabelNames() []string {
+	return SocketCapacityLabelNames
+}
+
+func (cl socketCapacityLabels) addLabel(name string, value string) {
+	MetricLabels(cl).labels[name] = value
+}
+
+func newCapacityReading(dimmUID nvmUID,
+	readStatus nvmStatusCodeEnumAttr,
+	metricType capacityTypeEnumAttr,
+	metricValue nvmUint64) *capacityReading {
+	capacityReading := new(capacityReading)
+	capacityReading.DIMMUID = string(dimmUID)
+	capacityReading.ReadStatus = int(readStatus)
+	capacityReading.MetricType = uint8(metricType)
+	capacityReading.MetricValue = float64(metricValue)
+	capacityReading.Labels = capacityLabels(*newMetricLabels())
+	return capacityReading
+}
+
+// Capacity of the DCPMM in bytes, one reading per capacity class
+func (reader *MetricsReader) GetCapacities() []MetricReading {
+	results := make([]MetricReading, 0, int(reader.deviceCount)*len(capacityClasses))
+	for _, dev := range reader.devices {
+		for _, class := range capacityClasses {
+			capacityReading := *newCapacityReading(dev.uid, dev.capacitiesOpstat,
+				capacityTypeEnum.device, class.capacity(&dev.capacities))
+			capacityReading.Labels.addLabel("uid", string(dev.uid))
+			capacityReading.Labels.addLabel("class", class.name)
+			dev.addLocationLabel(capacityReading.Labels)
+			results = append(results, MetricReading(capacityReading))
+		}
+	}
+	return results
+}
+
+// Capacity of all DCPMMs populated in the socket in bytes, one reading per
+// capacity class. DCPMMs, which capacities could not be read, are skipped.
+func (reader *MetricsReader) GetSocketCapacities() []MetricReading {
+	sockets := make(map[nvmUint16]*deviceCapacities)
+	for _, dev := range reader.devices {
+		if nvmStatusCodeEnum.nvmSuccess != dev.capacitiesOpstat {
+			continue
+		}
+		total, found := sockets[dev.discovery.socketID]
+		if !found {
+			total = new(deviceCapacities)
+			sockets[dev.discovery.socketID] = total
+		}
+		total.capacity += dev.capacities.capacity
+		total.memoryCapacity += dev.capacities.memoryCapacity
+		total.appDirectoryCapacity += dev.capacities.appDirectoryCapacity
+		total.mirroredAPPDirectCapacity += dev.capacities.mirroredAPPDirectCapacity
+		total.unconfiguredCapacity += dev.capacities.unconfiguredCapacity
+		total.inaccessibleCapacity += dev.capacities.inaccessibleCapacity
+		total.reservedCapacity += dev.capacities.reservedCapacity
+	}
+	socketIDs := make([]nvmUint16, 0, len(sockets))
+	for socketID := range sockets {
+		socketIDs = append(socketIDs, socketID)
+	}
+	sort.Slice(socketIDs, func(i, j int) bool {
+		return socketIDs[i] < socketIDs[j]
+	})
+	results := make([]MetricReading, 0, len(sockets)*len(capacityClasses))
+	for _, socketID := range socketIDs {
+		for _, class := range capacityClasses {
+			capacityReading := *newCapacityReading("", nvmStatusCodeEnum.nvmSuccess,
+				capacityTypeEnum.socket, class.capacity(sockets[socketID]))
+			capacityReading.Labels = socketCapacityLabels(*newMetricLabels())
+			capacityReading.Labels.addLabel("socket_id", socketID.toString(16))
+			capacityReading.Labels.addLabel("class", class.name)
+			results = append(results, MetricReading(capacityReading))
+		}
+	}
+	return results
+}
@@ -0,0 +1,1175 @@
+/**
+ * Copyright (c) 2020-2021, Intel Corporation.
+ * SPDX-License-Identifier: BSD-3-Clause
+ **
+ * This package introduces wrapper for ipmctl library written in C.
+ * api_capture.go file contains Recorder, which saves every raw reading taken
+ * by MetricsReader to a capture file, and the serializable representation of
+ * these readings shared with the replay backend. Capture file consists of
+ * JSON frames (one per line), each frame holds readings of one reading cycle.
+ */
+
+package nvm
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// captureFrame holds all readings taken during one reading cycle
+type captureFrame struct {
+	Timestamp time.Time       `json:"timestamp"`
+	Devices   []captureDevice `json:"devices"`
+	Platform  capturePlatform `json:"platform"`
+}
+
+// capturePlatform holds readings describing the whole system, all of them
+// are optional just like newer readings of the devices
+type capturePlatform struct {
+	RegionsOpstat        *int                    `json:"regions_opstat,omitempty"`
+	Regions              []captureRegion         `json:"regions,omitempty"`
+	SocketsOpstat        *int                    `json:"sockets_opstat,omitempty"`
+	Sockets              []captureSocket         `json:"sockets,omitempty"`
+	TopologyOpstat       *int                    `json:"topology_opstat,omitempty"`
+	Topology             []captureMemoryTopology `json:"topology,omitempty"`
+	HostOpstat           *int                    `json:"host_opstat,omitempty"`
+	Host                 *captureHost            `json:"host,omitempty"`
+	SwInventoryOpstat    *int                    `json:"sw_inventory_opstat,omitempty"`
+	SwInventory          *captureSwInventory     `json:"sw_inventory,omitempty"`
+	LibraryVersionOpstat *int                    `json:"library_version_opstat,omitempty"`
+	LibraryVersion       *string                 `json:"library_version,omitempty"`
+	CapabilitiesOpstat   *int                    `json:"capabilities_opstat,omitempty"`
+	Capabilities         *captureCapabilities    `json:"capabilities,omitempty"`
+	// only the events logged since the previous frame are captured
+	EventsOpstat *int           `json:"events_opstat,omitempty"`
+	Events       []captureEvent `json:"events,omitempty"`
+}
+
+type captureEvent struct {
+	EventID    uint32   `json:"event_id"`
+	Type       int      `json:"type"`
+	Severity   int      `json:"severity"`
+	Code       uint16   `json:"code"`
+	UID        string   `json:"uid"`
+	Time       uint64   `json:"time"`
+	Message    string   `json:"message"`
+	Args       []string `json:"args"`
+	DiagResult int      `json:"diag_result"`
+}
+
+type captureSocket struct {
+	ID                uint16 `json:"id"`
+	MappedMemoryLimit uint64 `json:"mapped_memory_limit"`
+	TotalMappedMemory uint64 `json:"total_mapped_memory"`
+}
+
+type captureMemoryTopology struct {
+	PhysicalID    uint16 `json:"physical_id"`
+	MemoryType    int    `json:"memory_type"`
+	DeviceLocator string `json:"device_locator"`
+	BankLabel     string `json:"bank_label"`
+}
+
+type captureHost struct {
+	Name         string `json:"name"`
+	OsType       int    `json:"os_type"`
+	OsName       string `json:"os_name"`
+	OsVersion    string `json:"os_version"`
+	MixedSKU     bool   `json:"mixed_sku"`
+	SKUViolation bool   `json:"sku_violation"`
+}
+
+type captureSwInventory struct {
+	MgmtSwRevision         string `json:"mgmt_sw_revision"`
+	VendorDriverRevision   string `json:"vendor_driver_revision"`
+	VendorDriverCompatible bool   `json:"vendor_driver_compatible"`
+}
+
+// captureCapabilities keeps features and capabilities by their label values,
+// memory modes supported by the BIOS are keyed by mode name as well
+type captureCapabilities struct {
+	Features         map[string]bool                      `json:"features"`
+	Platform         map[string]bool                      `json:"platform"`
+	MemoryModes      map[string]captureMemoryCapabilities `json:"memory_modes"`
+	MinNamespaceSize uint64                               `json:"min_namespace_size"`
+	VolatileMode     int                                  `json:"volatile_mode"`
+	AppDirectMode    int                                  `json:"app_direct_mode"`
+	MixedSKU         bool                                 `json:"mixed_sku"`
+	SKUViolation     bool                                 `json:"sku_violation"`
+}
+
+type captureMemoryCapabilities struct {
+	Supported               bool                      `json:"supported"`
+	InterleaveAlignmentSize uint16                    `json:"interleave_alignment_size"`
+	InterleaveFormats       []captureInterleaveFormat `json:"interleave_formats"`
+}
+
+type captureInterleaveFormat struct {
+	Recommended bool `json:"recommended"`
+	Channel     int  `json:"channel"`
+	IMC         int  `json:"imc"`
+	Ways        int  `json:"ways"`
+}
+
+type captureRegion struct {
+	ISetID       uint64   `json:"iset_id"`
+	Type         int      `json:"type"`
+	Capacity     uint64   `json:"capacity"`
+	FreeCapacity uint64   `json:"free_capacity"`
+	SocketID     uint16   `json:"socket_id"`
+	DIMMs        []uint16 `json:"dimms"`
+	Health       int      `json:"health"`
+}
+
+type captureDevice struct {
+	Discovery         captureDiscovery                        `json:"discovery"`
+	PerformanceOpstat int                                     `json:"performance_opstat"`
+	Performance       capturePerformance                      `json:"performance"`
+	SensorsOpstat     [NumberOfAvailableSensors]int           `json:"sensors_opstat"`
+	Sensors           [NumberOfAvailableSensors]captureSensor `json:"sensors"`
+	// readings added after the capture format was introduced are optional,
+	// so captures taken by older exporter versions can still be played back
+	StatusOpstat     *int              `json:"status_opstat,omitempty"`
+	Status           captureStatus     `json:"status"`
+	FwInfoOpstat     *int              `json:"fw_info_opstat,omitempty"`
+	FwInfo           captureFwInfo     `json:"fw_info"`
+	CapacitiesOpstat *int              `json:"capacities_opstat,omitempty"`
+	Capacities       captureCapacities `json:"capacities"`
+	ErrorLogOpstat   *int              `json:"error_log_opstat,omitempty"`
+	ErrorLog         captureErrorLog   `json:"error_log"`
+	PMONOpstat       *int              `json:"pmon_opstat,omitempty"`
+	PMON             captureRegisters  `json:"pmon"`
+	SettingsOpstat   *int              `json:"settings_opstat,omitempty"`
+	Settings         captureSettings   `json:"settings"`
+	DetailsOpstat    *int              `json:"details_opstat,omitempty"`
+	Details          captureDetails    `json:"details"`
+	// only the entries fetched since the previous frame are captured
+	ErrorLogEntries []captureErrorLogEntry `json:"error_log_entries,omitempty"`
+}
+
+type captureDiscovery struct {
+	AllPropertiesPopulated  bool      `json:"all_properties_populated"`
+	DeviceHandle            []byte    `json:"device_handle"`
+	PhysicalID              uint16    `json:"physical_id"`
+	VendorID                uint16    `json:"vendor_id"`
+	DeviceID                uint16    `json:"device_id"`
+	RevisionID              uint16    `json:"revision_id"`
+	ChannelPos              uint16    `json:"channel_pos"`
+	ChannelID               uint16    `json:"channel_id"`
+	MemoryControllerID      uint16    `json:"memory_controller_id"`
+	SocketID                uint16    `json:"socket_id"`
+	NodeControllerID        uint16    `json:"node_controller_id"`
+	MemoryType              int       `json:"memory_type"`
+	DimmSKU                 uint32    `json:"dimm_sku"`
+	Manufacturer            []uint8   `json:"manufacturer"`
+	SerialNumber            []uint8   `json:"serial_number"`
+	SubsystemVendorID       uint16    `json:"subsystem_vendor_id"`
+	SubsystemDeviceID       uint16    `json:"subsystem_device_id"`
+	SubsystemRevisionID     uint16    `json:"subsystem_revision_id"`
+	ManufacturingInfoValid  bool      `json:"manufacturing_info_valid"`
+	ManufacturingLocation   uint8     `json:"manufacturing_location"`
+	ManufacturingDate       uint16    `json:"manufacturing_date"`
+	PartNumber              string    `json:"part_number"`
+	FwRevision              string    `json:"fw_revision"`
+	FwAPIVersion            string    `json:"fw_api_version"`
+	Capacity                uint64    `json:"capacity"`
+	InterfaceFormatCodes    [9]uint16 `json:"interface_format_codes"`
+	PassphraseCapable       bool      `json:"passphrase_capable"`
+	UnlockDeviceCapable     bool      `json:"unlock_device_capable"`
+	EraseCryptoCapable      bool      `json:"erase_crypto_capable"`
+	MasterPassphraseCapable bool      `json:"master_passphrase_capable"`
+	PackageSparingCapable   bool      `json:"package_sparing_capable"`
+	MemoryModeCapable       bool      `json:"memory_mode_capable"`
+	AppDirectModeCapable    bool      `json:"app_direct_mode_capable"`
+	UID                     string    `json:"uid"`
+	LockState               int       `json:"lock_state"`
+	Manageability           int       `json:"manageability"`
+	ControllerRevisionID    uint16    `json:"controller_revision_id"`
+	MasterPassphraseEnabled bool      `json:"master_passphrase_enabled"`
+}
+
+type capturePerformance struct {
+	Time         uint64 `json:"time"`
+	BytesRead    uint64 `json:"bytes_read"`
+	HostReads    uint64 `json:"host_reads"`
+	BytesWritten uint64 `json:"bytes_written"`
+	HostWrites   uint64 `json:"host_writes"`
+	BlockReads   uint64 `json:"block_reads"`
+	BlockWrites  uint64 `json:"block_writes"`
+}
+
+type captureFwInfo struct {
+	ActiveFwRevision string `json:"active_fw_revision"`
+	StagedFwRevision string `json:"staged_fw_revision"`
+	FwImageMaxSize   uint32 `json:"fw_image_max_size"`
+	FwUpdateStatus   int    `json:"fw_update_status"`
+}
+
+type captureCapacities struct {
+	Capacity                  uint64 `json:"capacity"`
+	MemoryCapacity            uint64 `json:"memory_capacity"`
+	AppDirectCapacity         uint64 `json:"app_direct_capacity"`
+	MirroredAppDirectCapacity uint64 `json:"mirrored_app_direct_capacity"`
+	UnconfiguredCapacity      uint64 `json:"unconfigured_capacity"`
+	InaccessibleCapacity      uint64 `json:"inaccessible_capacity"`
+	ReservedCapacity          uint64 `json:"reserved_capacity"`
+}
+
+type captureErrorLog struct {
+	MediaLow    captureSequenceNumbers `json:"media_low"`
+	MediaHigh   captureSequenceNumbers `json:"media_high"`
+	ThermalLow  captureSequenceNumbers `json:"thermal_low"`
+	ThermalHigh captureSequenceNumbers `json:"thermal_high"`
+}
+
+type captureRegisters struct {
+	SmartDataMask         uint8  `json:"smart_data_mask"`
+	GroupEnabled          uint8  `json:"group_enabled"`
+	PMON4Counter          uint   `json:"pmon4_counter"`
+	PMON5Counter          uint   `json:"pmon5_counter"`
+	PMON7Counter          uint   `json:"pmon7_counter"`
+	PMON8Counter          uint   `json:"pmon8_counter"`
+	PMON9Counter          uint   `json:"pmon9_counter"`
+	PMON14Counter         uint   `json:"pmon14_counter"`
+	DDRTReads             uint64 `json:"ddrt_reads"`
+	DDRTWrites            uint64 `json:"ddrt_writes"`
+	MediaReads            uint64 `json:"media_reads"`
+	MediaWrites           uint64 `json:"media_writes"`
+	MediaTemperature      uint16 `json:"media_temperature"`
+	ControllerTemperature uint16 `json:"controller_temperature"`
+}
+
+type captureSettings struct {
+	ViralPolicy bool `json:"viral_policy"`
+	ViralStatus bool `json:"viral_status"`
+}
+
+// captureDetails holds only the details not taken by the other readings
+type captureDetails struct {
+	FormFactor            int             `json:"form_factor"`
+	DataWidth             uint64          `json:"data_width"`
+	TotalWidth            uint64          `json:"total_width"`
+	Speed                 uint64          `json:"speed"`
+	DeviceLocator         string          `json:"device_locator"`
+	BankLabel             string          `json:"bank_label"`
+	PeakPowerBudget       uint16          `json:"peak_power_budget"`
+	AvgPowerBudget        uint16          `json:"avg_power_budget"`
+	PackageSparingEnabled bool            `json:"package_sparing_enabled"`
+	Settings              captureSettings `json:"settings"`
+}
+
+type captureSequenceNumbers struct {
+	Oldest  uint16 `json:"oldest"`
+	Current uint16 `json:"current"`
+}
+
+type captureErrorLogEntry struct {
+	LogType         int     `json:"log_type"`
+	LogLevel        int     `json:"log_level"`
+	SequenceNumber  uint16  `json:"sequence_number"`
+	DimmID          uint16  `json:"dimm_id"`
+	SystemTimestamp uint64  `json:"system_timestamp"`
+	ErrorType       uint8   `json:"error_type"`
+	OutputData      []uint8 `json:"output_data"`
+}
+
+type captureStatus struct {
+	Health                             uint8  `json:"health"`
+	IsNew                              bool   `json:"is_new"`
+	IsConfigured                       bool   `json:"is_configured"`
+	IsMissing                          bool   `json:"is_missing"`
+	PackageSparesAvailable             uint8  `json:"package_spares_available"`
+	LastShutdownStatusDetails          uint32 `json:"last_shutdown_status_details"`
+	ConfigStatus                       int    `json:"config_status"`
+	LastShutdownTime                   uint64 `json:"last_shutdown_time"`
+	MixedSKU                           bool   `json:"mixed_sku"`
+	SKUViolation                       bool   `json:"sku_violation"`
+	ViralState                         bool   `json:"viral_state"`
+	ARSStatus                          int    `json:"ars_status"`
+	OverwritedimmStatus                int    `json:"overwritedimm_status"`
+	AitDRAMEnabled                     bool   `json:"ait_dram_enabled"`
+	BootStatus                         uint64 `json:"boot_status"`
+	InjectedMediaErrors                uint32 `json:"injected_media_errors"`
+	InjectedNonMediaErrors             uint32 `json:"injected_non_media_errors"`
+	UnlatchedLastShutdownStatusDetails uint32 `json:"unlatched_last_shutdown_status_details"`
+	ThermalThrottlePerformanceLossPCNT uint8  `json:"thermal_throttle_performance_loss_pcnt"`
+}
+
+type captureSensor struct {
+	Type                      int    `json:"type"`
+	Units                     int    `json:"units"`
+	CurrentState              int    `json:"current_state"`
+	Reading                   uint64 `json:"reading"`
+	Enabled                   bool   `json:"enabled"`
+	UpperCriticalThreshold    uint64 `json:"upper_critical_threshold"`
+	LowerCriticalThreshold    uint64 `json:"lower_critical_threshold"`
+	UpperFatalThreshold       uint64 `json:"upper_fatal_threshold"`
+	LowerFatalThreshold       uint64 `json:"lower_fatal_threshold"`
+	UpperNoncriticalThreshold uint64 `json:"upper_noncritical_threshold"`
+	LowerNoncriticalThreshold uint64 `json:"lower_noncritical_threshold"`
+	LowerCriticalSettable     bool   `json:"lower_critical_settable"`
+	UpperCriticalSettable     bool   `json:"upper_critical_settable"`
+	LowerCriticalSupport      bool   `json:"lower_critical_support"`
+	UpperCriticalSupport      bool   `json:"upper_critical_support"`
+	LowerFatalSettable        bool   `json:"lower_fatal_settable"`
+	UpperFatalSettable        bool   `json:"upper_fatal_settable"`
+	LowerFatalSupport         bool   `json:"lower_fatal_support"`
+	UpperFatalSupport         bool   `json:"upper_fatal_support"`
+	LowerNoncriticalSettable  bool   `json:"lower_noncritical_settable"`
+	UpperNoncriticalSettable  bool   `json:"upper_noncritical_settable"`
+	LowerNoncriticalSupport   bool   `json:"lower_noncritical_support"`
+	UpperNoncriticalSupport   bool   `json:"upper_noncritical_support"`
+}
+
+// Recorder writes readings of every reading cycle to the capture file
+type Recorder struct {
+	file    *os.File
+	encoder *json.Encoder
+	lock    sync.Mutex
+}
+
+// NewRecorder creates recorder appending frames to given capture file
+func NewRecorder(path string) (*Recorder, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to open capture file: %v", err)
+	}
+	return &Recorder{file: file, encoder: json.NewEncoder(file)}, nil
+}
+
+// Close flushes and closes the capture file
+func (recorder *Recorder) Close() error {
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+	if err := recorder.file.Sync(); err != nil {
+		recorder.file.Close()
+		return err
+	}
+	return recorder.file.Close()
+}
+
+func (recorder *Recorder) record(timestamp time.Time, devices []device, platform platform) error {
+	frame := captureFrame{
+		Timestamp: timestamp,
+		Devices:   make([]captureDevice, len(devices)),
+		Platform:  newCapturePlatform(platform),
+	}
+	for i, dev := range devices {
+		frame.Devices[i] = newCaptureDevice(dev)
+	}
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+	return recorder.encoder.Encode(frame)
+}
+
+func newCaptureDevice(dev device) captureDevice {
+	result := captureDevice{
+		Discovery:         newCaptureDiscovery(dev.discovery),
+		PerformanceOpstat: int(dev.performanceOpstat),
+		Performance:       newCapturePerformance(dev.performance),
+		StatusOpstat:      newCaptureOpstat(dev.statusOpstat),
+		Status:            newCaptureStatus(dev.status),
+		FwInfoOpstat:      newCaptureOpstat(dev.fwInfoOpstat),
+		FwInfo:            newCaptureFwInfo(dev.fwInfo),
+		CapacitiesOpstat:  newCaptureOpstat(dev.capacitiesOpstat),
+		Capacities:        newCaptureCapacities(dev.capacities),
+		ErrorLogOpstat:    newCaptureOpstat(dev.errorLogStatusOpstat),
+		ErrorLog:          newCaptureErrorLog(dev.errorLogStatus),
+		PMONOpstat:        newCaptureOpstat(dev.pmonOpstat),
+		PMON:              newCaptureRegisters(dev.pmon),
+		SettingsOpstat:    newCaptureOpstat(dev.settingsOpstat),
+		Settings:          newCaptureSettings(dev.settings),
+		DetailsOpstat:     newCaptureOpstat(dev.detailsOpstat),
+		Details:           newCaptureDetails(dev.details),
+		ErrorLogEntries:   make([]captureErrorLogEntry, len(dev.errorLogEntries)),
+	}
+	for i := range dev.sensors {
+		result.SensorsOpstat[i] = int(dev.sensorsOpstat[i])
+		result.Sensors[i] = newCaptureSensor(dev.sensors[i])
+	}
+	for i, entry := range dev.errorLogEntries {
+		result.ErrorLogEntries[i] = newCaptureErrorLogEntry(entry)
+	}
+	return result
+}
+
+func (captured captureDevice) toDevice() device {
+	result := device{
+		discovery:            captured.Discovery.toDeviceDiscovery(),
+		performanceOpstat:    nvmStatusCodeEnumAttr(captured.PerformanceOpstat),
+		performance:          captured.Performance.toDevicePerformance(),
+		statusOpstat:         toCapturedOpstat(captured.StatusOpstat),
+		status:               captured.Status.toDeviceStatus(),
+		fwInfoOpstat:         toCapturedOpstat(captured.FwInfoOpstat),
+		fwInfo:               captured.FwInfo.toDeviceFWInfo(),
+		capacitiesOpstat:     toCapturedOpstat(captured.CapacitiesOpstat),
+		capacities:           captured.Capacities.toDeviceCapacities(),
+		errorLogStatusOpstat: toCapturedOpstat(captured.ErrorLogOpstat),
+		errorLogStatus:       captured.ErrorLog.toDeviceErrorLogStatus(),
+		pmonOpstat:           toCapturedOpstat(captured.PMONOpstat),
+		pmon:                 captured.PMON.toPMONRegisters(),
+		settingsOpstat:       toCapturedOpstat(captured.SettingsOpstat),
+		settings:             captured.Settings.toDeviceSettings(),
+		detailsOpstat:        toCapturedOpstat(captured.DetailsOpstat),
+		details:              captured.Details.toDeviceDetails(),
+		errorLogEntries:      make([]errorLogEntry, len(captured.ErrorLogEntries)),
+	}
+	result.uid = result.discovery.uid
+	result.details.discovery = result.discovery
+	for i := range captured.Sensors {
+		result.sensorsOpstat[i] = nvmStatusCodeEnumAttr(captured.SensorsOpstat[i])
+		result.sensors[i] = captured.Sensors[i].toSensor()
+	}
+	for i, entry := range captured.ErrorLogEntries {
+		result.errorLogEntries[i] = entry.toErrorLogEntry()
+	}
+	return result
+}
+
+func newCaptureOpstat(opstat nvmStatusCodeEnumAttr) *int {
+	result := int(opstat)
+	return &result
+}
+
+// toCapturedOpstat returns status of the operation saved in the capture, the
+// readings missing in the capture are reported as not supported
+func toCapturedOpstat(opstat *int) nvmStatusCodeEnumAttr {
+	if opstat == nil {
+		return nvmStatusCodeEnum.nvmErrAPINotSupported
+	}
+	return nvmStatusCodeEnumAttr(*opstat)
+}
+
+func newCaptureDiscovery(discovery deviceDiscovery) captureDiscovery {
+	result := captureDiscovery{
+		AllPropertiesPopulated:  bool(discovery.allPropertiesPopulated),
+		DeviceHandle:            []byte(discovery.deviceHandle),
+		PhysicalID:              uint16(discovery.physicalID),
+		VendorID:                uint16(discovery.vendorID),
+		DeviceID:                uint16(discovery.deviceID),
+		RevisionID:              uint16(discovery.revisionID),
+		ChannelPos:              uint16(discovery.channelPos),
+		ChannelID:               uint16(discovery.channelID),
+		MemoryControllerID:      uint16(discovery.memoryControllerID),
+		SocketID:                uint16(discovery.socketID),
+		NodeControllerID:        uint16(discovery.nodeControllerID),
+		MemoryType:              int(discovery.memoryType),
+		DimmSKU:                 uint32(discovery.dimmSKU),
+		Manufacturer:            make([]uint8, len(discovery.manufacturer)),
+		SerialNumber:            make([]uint8, len(discovery.serialNumber)),
+		SubsystemVendorID:       uint16(discovery.subsystemVendorID),
+		SubsystemDeviceID:       uint16(discovery.subsystemDeviceID),
+		SubsystemRevisionID:     uint16(discovery.subsystemRevisionID),
+		ManufacturingInfoValid:  bool(discovery.manufacturingInfoValid),
+		ManufacturingLocation:   uint8(discovery.manufacturingLocation),
+		ManufacturingDate:       uint16(discovery.manufacturingDate),
+		PartNumber:              discovery.partNumber,
+		FwRevision:              string(discovery.fwRevision),
+		FwAPIVersion:            string(discovery.fwAPIVersion),
+		Capacity:                uint64(discovery.capacity),
+		PassphraseCapable:       bool(discovery.securityCapabilities.passphraseCapable),
+		UnlockDeviceCapable:     bool(discovery.securityCapabilities.unlockDeviceCapable),
+		EraseCryptoCapable:      bool(discovery.securityCapabilities.eraseCryptoCapable),
+		MasterPassphraseCapable: bool(discovery.securityCapabilities.masterPassphraseCapable),
+		PackageSparingCapable:   bool(discovery.deviceCapabilities.packageSparingCapable),
+		MemoryModeCapable:       bool(discovery.deviceCapabilities.memoryModeCapable),
+		AppDirectModeCapable:    bool(discovery.deviceCapabilities.appDirectModeCapable),
+		UID:                     string(discovery.uid),
+		LockState:               int(discovery.lockState),
+		Manageability:           int(discovery.manageability),
+		ControllerRevisionID:    uint16(discovery.controllerRevisionID),
+		MasterPassphraseEnabled: bool(discovery.masterPassphraseEnabled),
+	}
+	for i, v := range discovery.manufacturer {
+		result.Manufacturer[i] = uint8(v)
+	}
+	for i, v := range discovery.serialNumber {
+		result.SerialNumber[i] = uint8(v)
+	}
+	for i, v := range discovery.interfaceFormatCodes {
+		result.InterfaceFormatCodes[i] = uint16(v)
+	}
+	return result
+}
+
+func (captured captureDiscovery) toDeviceDiscovery() deviceDiscovery {
+	result := deviceDiscovery{
+		allPropertiesPopulated: nvmBool(captured.AllPropertiesPopulated),
+		deviceHandle:           nvmNfitDeviceHandle(captured.DeviceHandle),
+		physicalID:             nvmUint16(captured.PhysicalID),
+		vendorID:               nvmUint16(captured.VendorID),
+		deviceID:               nvmUint16(captured.DeviceID),
+		revisionID:             nvmUint16(captured.RevisionID),
+		channelPos:             nvmUint16(captured.ChannelPos),
+		channelID:              nvmUint16(captured.ChannelID),
+		memoryControllerID:     nvmUint16(captured.MemoryControllerID),
+		socketID:               nvmUint16(captured.SocketID),
+		nodeControllerID:       nvmUint16(captured.NodeControllerID),
+		memoryType:             memoryTypeEnumAttr(captured.MemoryType),
+		dimmSKU:                nvmUint32(captured.DimmSKU),
+		manufacturer:           make(nvmManufacturer, len(captured.Manufacturer)),
+		serialNumber:           make(nvmSerialNumber, len(captured.SerialNumber)),
+		subsystemVendorID:      nvmUint16(captured.SubsystemVendorID),
+		subsystemDeviceID:      nvmUint16(captured.SubsystemDeviceID),
+		subsystemRevisionID:    nvmUint16(captured.SubsystemRevisionID),
+		manufacturingInfoValid: nvmBool(captured.ManufacturingInfoValid),
+		manufacturingLocation:  nvmUint8(captured.ManufacturingLocation),
+		manufacturingDate:      nvmUint16(captured.ManufacturingDate),
+		partNumber:             captured.PartNumber,
+		fwRevision:             nvmVersion(captured.FwRevision),
+		fwAPIVersion:           nvmVersion(captured.FwAPIVersion),
+		capacity:               nvmUint64(captured.Capacity),
+		securityCapabilities: deviceSecurityCapabilities{
+			passphraseCapable:       nvmBool(captured.PassphraseCapable),
+			unlockDeviceCapable:     nvmBool(captured.UnlockDeviceCapable),
+			eraseCryptoCapable:      nvmBool(captured.EraseCryptoCapable),
+			masterPassphraseCapable: nvmBool(captured.MasterPassphraseCapable),
+		},
+		deviceCapabilities: deviceCapabilities{
+			packageSparingCapable: nvmBool(captured.PackageSparingCapable),
+			memoryModeCapable:     nvmBool(captured.MemoryModeCapable),
+			appDirectModeCapable:  nvmBool(captured.AppDirectModeCapable),
+		},
+		uid:                     nvmUID(captured.UID),
+		lockState:               lockStateEnumAttr(captured.LockState),
+		manageability:           manageabilityStateEnumAttr(captured.Manageability),
+		controllerRevisionID:    nvmUint16(captured.ControllerRevisionID),
+		masterPassphraseEnabled: nvmBool(captured.MasterPassphraseEnabled),
+	}
+	for i, v := range captured.Manufacturer {
+		result.manufacturer[i] = nvmUint8(v)
+	}
+	for i, v := range captured.SerialNumber {
+		result.serialNumber[i] = nvmUint8(v)
+	}
+	for i, v := range captured.InterfaceFormatCodes {
+		result.interfaceFormatCodes[i] = nvmUint16(v)
+	}
+	return result
+}
+
+func newCapturePerformance(performance devicePerformance) capturePerformance {
+	return capturePerformance{
+		Time:         uint64(performance.time),
+		BytesRead:    uint64(performance.bytesRead),
+		HostReads:    uint64(performance.hostReads),
+		BytesWritten: uint64(performance.bytesWritten),
+		HostWrites:   uint64(performance.hostWrites),
+		BlockReads:   uint64(performance.blockReads),
+		BlockWrites:  uint64(performance.blockWrites),
+	}
+}
+
+func (captured capturePerformance) toDevicePerformance() devicePerformance {
+	return devicePerformance{
+		time:         timeT(captured.Time),
+		bytesRead:    nvmUint64(captured.BytesRead),
+		hostReads:    nvmUint64(captured.HostReads),
+		bytesWritten: nvmUint64(captured.BytesWritten),
+		hostWrites:   nvmUint64(captured.HostWrites),
+		blockReads:   nvmUint64(captured.BlockReads),
+		blockWrites:  nvmUint64(captured.BlockWrites),
+	}
+}
+
+func newCaptureSensor(s sensor) captureSensor {
+	return captureSensor{
+		Type:                      int(s.stype),
+		Units:                     int(s.units),
+		CurrentState:              int(s.currentState),
+		Reading:                   uint64(s.reading),
+		Enabled:                   bool(s.settings.enabled),
+		UpperCriticalThreshold:    uint64(s.settings.upperCriticalThreshold),
+		LowerCriticalThreshold:    uint64(s.settings.lowerCriticalThreshold),
+		UpperFatalThreshold:       uint64(s.settings.upperFatalThreshold),
+		LowerFatalThreshold:       uint64(s.settings.lowerFatalThreshold),
+		UpperNoncriticalThreshold: uint64(s.settings.upperNoncriticalThreshold),
+		LowerNoncriticalThreshold: uint64(s.settings.lowerNoncriticalThreshold),
+		LowerCriticalSettable:     bool(s.lowerCriticalSettable),
+		UpperCriticalSettable:     bool(s.upperCriticalSettable),
+		LowerCriticalSupport:      bool(s.lowerCriticalSupport),
+		UpperCriticalSupport:      bool(s.upperCriticalSupport),
+		LowerFatalSettable:        bool(s.lowerFatalSettable),
+		UpperFatalSettable:        bool(s.upperFatalSettable),
+		LowerFatalSupport:         bool(s.lowerFatalSupport),
+		UpperFatalSupport:         bool(s.upperFatalSupport),
+		LowerNoncriticalSettable:  bool(s.lowerNoncriticalSettable),
+		UpperNoncriticalSettable:  bool(s.upperNoncriticalSettable),
+		LowerNoncriticalSupport:   bool(s.lowerNoncriticalSupport),
+		UpperNoncriticalSupport:   bool(s.upperNoncriticalSupport),
+	}
+}
+
+func (captured captureSensor) toSensor() sensor {
+	return sensor{
+		stype:        sensorTypeEnumAttr(captured.Type),
+		units:        sensorUnitsEnumAttr(captured.Units),
+		currentState: sensorStatusEnumAttr(captured.CurrentState),
+		reading:      nvmUint64(captured.Reading),
+		settings: sensorSettings{
+			enabled:                   nvmBool(captured.Enabled),
+			upperCriticalThreshold:    nvmUint64(captured.UpperCriticalThreshold),
+			lowerCriticalThreshold:    nvmUint64(captured.LowerCriticalThreshold),
+			upperFatalThreshold:       nvmUint64(captured.UpperFatalThreshold),
+			lowerFatalThreshold:       nvmUint64(captured.LowerFatalThreshold),
+			upperNoncriticalThreshold: nvmUint64(captured.UpperNoncriticalThreshold),
+			lowerNoncriticalThreshold: nvmUint64(captured.LowerNoncriticalThreshold),
+		},
+		lowerCriticalSettable:    nvmBool(captured.LowerCriticalSettable),
+		upperCriticalSettable:    nvmBool(captured.UpperCriticalSettable),
+		lowerCriticalSupport:     nvmBool(captured.LowerCriticalSupport),
+		upperCriticalSupport:     nvmBool(captured.UpperCriticalSupport),
+		lowerFatalSettable:       nvmBool(captured.LowerFatalSettable),
+		upperFatalSettable:       nvmBool(captured.UpperFatalSettable),
+		lowerFatalSupport:        nvmBool(captured.LowerFatalSupport),
+		upperFatalSupport:        nvmBool(captured.UpperFatalSupport),
+		lowerNoncriticalSettable: nvmBool(captured.LowerNoncriticalSettable),
+		upperNoncriticalSettable: nvmBool(captured.UpperNoncriticalSettable),
+		lowerNoncriticalSupport:  nvmBool(captured.LowerNoncriticalSupport),
+		upperNoncriticalSupport:  nvmBool(captured.UpperNoncriticalSupport),
+	}
+}
+
+func newCaptureStatus(status deviceStatus) captureStatus {
+	return captureStatus{
+		Health:                             uint8(status.health),
+		IsNew:                              bool(status.isNew),
+		IsConfigured:                       bool(status.isConfigured),
+		IsMissing:                          bool(status.isMissing),
+		PackageSparesAvailable:             uint8(status.packageSparesAvailable),
+		LastShutdownStatusDetails:          uint32(status.lastShutdownStatusDetails),
+		ConfigStatus:                       int(status.configStatus),
+		LastShutdownTime:                   uint64(status.lastShutdownTime),
+		MixedSKU:                           bool(status.mixedSKU),
+		SKUViolation:                       bool(status.skuViolation),
+		ViralState:                         bool(status.viralState),
+		ARSStatus:                          int(status.arsStatus),
+		OverwritedimmStatus:                int(status.overwritedimmStatus),
+		AitDRAMEnabled:                     bool(status.aitDRAMEnabled),
+		BootStatus:                         uint64(status.bootStatus),
+		InjectedMediaErrors:                uint32(status.injectedMediaErrors),
+		InjectedNonMediaErrors:             uint32(status.injectedNonMediaErrors),
+		UnlatchedLastShutdownStatusDetails: uint32(status.unlachedLastShutdownStatusDetails),
+		ThermalThrottlePerformanceLossPCNT: uint8(status.thermalThrottlePerformanceLossPCNT),
+	}
+}
+
+func (captured captureStatus) toDeviceStatus() deviceStatus {
+	return deviceStatus{
+		health:                             nvmUint8(captured.Health),
+		isNew:                              nvmBool(captured.IsNew),
+		isConfigured:                       nvmBool(captured.IsConfigured),
+		isMissing:                          nvmBool(captured.IsMissing),
+		packageSparesAvailable:             nvmUint8(captured.PackageSparesAvailable),
+		lastShutdownStatusDetails:          nvmUint32(captured.LastShutdownStatusDetails),
+		configStatus:                       configStatusEnumAttr(captured.ConfigStatus),
+		lastShutdownTime:                   nvmUint64(captured.LastShutdownTime),
+		mixedSKU:                           nvmBool(captured.MixedSKU),
+		skuViolation:                       nvmBool(captured.SKUViolation),
+		viralState:                         nvmBool(captured.ViralState),
+		arsStatus:                          deviceARSStatusEnumAttr(captured.ARSStatus),
+		overwritedimmStatus:                deviceOverwriteDIMMStatusEnumAttr(captured.OverwritedimmStatus),
+		aitDRAMEnabled:                     nvmBool(captured.AitDRAMEnabled),
+		bootStatus:                         nvmUint64(captured.BootStatus),
+		injectedMediaErrors:                nvmUint32(captured.InjectedMediaErrors),
+		injectedNonMediaErrors:             nvmUint32(captured.InjectedNonMediaErrors),
+		unlachedLastShutdownStatusDetails:  nvmUint32(captured.UnlatchedLastShutdownStatusDetails),
+		thermalThrottlePerformanceLossPCNT: nvmUint8(captured.ThermalThrottlePerformanceLossPCNT),
+	}
+}
+
+func newCaptureFwInfo(fwInfo deviceFWInfo) captureFwInfo {
+	return captureFwInfo{
+		ActiveFwRevision: string(fwInfo.activeFwRevision),
+		StagedFwRevision: string(fwInfo.stagedFwRevision),
+		FwImageMaxSize:   uint32(fwInfo.fwImageMaxSize),
+		FwUpdateStatus:   int(fwInfo.fwUpdateStatus),
+	}
+}
+
+func (captured captureFwInfo) toDeviceFWInfo() deviceFWInfo {
+	return deviceFWInfo{
+		activeFwRevision: nvmVersion(captured.ActiveFwRevision),
+		stagedFwRevision: nvmVersion(captured.StagedFwRevision),
+		fwImageMaxSize:   nvmUint32(captured.FwImageMaxSize),
+		fwUpdateStatus:   fwUpdateStatusEnumAttr(captured.FwUpdateStatus),
+	}
+}
+
+func newCaptureCapacities(capacities deviceCapacities) captureCapacities {
+	return captureCapacities{
+		Capacity:                  uint64(capacities.capacity),
+		MemoryCapacity:            uint64(capacities.memoryCapacity),
+		AppDirectCapacity:         uint64(capacities.appDirectoryCapacity),
+		MirroredAppDirectCapacity: uint64(capacities.mirroredAPPDirectCapacity),
+		UnconfiguredCapacity:      uint64(capacities.unconfiguredCapacity),
+		InaccessibleCapacity:      uint64(capacities.inaccessibleCapacity),
+		ReservedCapacity:          uint64(capacities.reservedCapacity),
+	}
+}
+
+func (captured captureCapacities) toDeviceCapacities() deviceCapacities {
+	return deviceCapacities{
+		capacity:                  nvmUint64(captured.Capacity),
+		memoryCapacity:            nvmUint64(captured.MemoryCapacity),
+		appDirectoryCapacity:      nvmUint64(captured.AppDirectCapacity),
+		mirroredAPPDirectCapacity: nvmUint64(captured.MirroredAppDirectCapacity),
+		unconfiguredCapacity:      nvmUint64(captured.UnconfiguredCapacity),
+		inaccessibleCapacity:      nvmUint64(captured.InaccessibleCapacity),
+		reservedCapacity:          nvmUint64(captured.ReservedCapacity),
+	}
+}
+
+func newCaptureSequenceNumbers(numbers fwErrorLogSequenceNumbers) captureSequenceNumbers {
+	return captureSequenceNumbers{
+		Oldest:  uint16(numbers.oldest),
+		Current: uint16(numbers.current),
+	}
+}
+
+func (captured captureSequenceNumbers) toSequenceNumbers() fwErrorLogSequenceNumbers {
+	return fwErrorLogSequenceNumbers{
+		oldest:  nvmUint16(captured.Oldest),
+		current: nvmUint16(captured.Current),
+	}
+}
+
+func newCaptureErrorLog(status deviceErrorLogStatus) captureErrorLog {
+	return captureErrorLog{
+		MediaLow:    newCaptureSequenceNumbers(status.mediaLow),
+		MediaHigh:   newCaptureSequenceNumbers(status.mediaHigh),
+		ThermalLow:  newCaptureSequenceNumbers(status.thermLow),
+		ThermalHigh: newCaptureSequenceNumbers(status.thermHigh),
+	}
+}
+
+func (captured captureErrorLog) toDeviceErrorLogStatus() deviceErrorLogStatus {
+	return deviceErrorLogStatus{
+		mediaLow:  captured.MediaLow.toSequenceNumbers(),
+		mediaHigh: captured.MediaHigh.toSequenceNumbers(),
+		thermLow:  captured.ThermalLow.toSequenceNumbers(),
+		thermHigh: captured.ThermalHigh.toSequenceNumbers(),
+	}
+}
+
+func newCaptureRegisters(registers pmonRegisters) captureRegisters {
+	return captureRegisters{
+		SmartDataMask:         registers.smartDataMask,
+		GroupEnabled:          registers.groupEnabled,
+		PMON4Counter:          registers.pmon4Counter,
+		PMON5Counter:          registers.pmon5Counter,
+		PMON7Counter:          registers.pmon7Counter,
+		PMON8Counter:          registers.pmon8Counter,
+		PMON9Counter:          registers.pmon9Counter,
+		PMON14Counter:         registers.pmon14Counter,
+		DDRTReads:             registers.ddrtrd,
+		DDRTWrites:            registers.ddrtwr,
+		MediaReads:            registers.merd,
+		MediaWrites:           registers.mewr,
+		MediaTemperature:      registers.mtp,
+		ControllerTemperature: registers.ctp,
+	}
+}
+
+func (captured captureRegisters) toPMONRegisters() pmonRegisters {
+	return pmonRegisters{
+		smartDataMask: captured.SmartDataMask,
+		groupEnabled:  captured.GroupEnabled,
+		pmon4Counter:  captured.PMON4Counter,
+		pmon5Counter:  captured.PMON5Counter,
+		pmon7Counter:  captured.PMON7Counter,
+		pmon8Counter:  captured.PMON8Counter,
+		pmon9Counter:  captured.PMON9Counter,
+		pmon14Counter: captured.PMON14Counter,
+		ddrtrd:        captured.DDRTReads,
+		ddrtwr:        captured.DDRTWrites,
+		merd:          captured.MediaReads,
+		mewr:          captured.MediaWrites,
+		mtp:           captured.MediaTemperature,
+		ctp:           captured.ControllerTemperature,
+	}
+}
+
+func newCaptureSettings(settings deviceSettings) captureSettings {
+	return captureSettings{
+		ViralPolicy: bool(settings.viralPolicy),
+		ViralStatus: bool(settings.viralStatus),
+	}
+}
+
+func (captured captureSettings) toDeviceSettings() deviceSettings {
+	return deviceSettings{
+		viralPolicy: nvmBool(captured.ViralPolicy),
+		viralStatus: nvmBool(captured.ViralStatus),
+	}
+}
+
+func newCaptureDetails(details deviceDetails) captureDetails {
+	return captureDetails{
+		FormFactor:            int(details.formFactor),
+		DataWidth:             uint64(details.dataWidth),
+		TotalWidth:            uint64(details.totalWidth),
+		Speed:                 uint64(details.speed),
+		DeviceLocator:         details.deviceLocator,
+		BankLabel:             details.bankLabel,
+		PeakPowerBudget:       uint16(details.peakPowerBudget),
+		AvgPowerBudget:        uint16(details.avgPowerBudget),
+		PackageSparingEnabled: bool(details.packageSparingEnabled),
+		Settings:              newCaptureSettings(details.settings),
+	}
+}
+
+func (captured captureDetails) toDeviceDetails() deviceDetails {
+	return deviceDetails{
+		formFactor:            deviceFromFactorEnumAttr(captured.FormFactor),
+		dataWidth:             nvmUint64(captured.DataWidth),
+		totalWidth:            nvmUint64(captured.TotalWidth),
+		speed:                 nvmUint64(captured.Speed),
+		deviceLocator:         captured.DeviceLocator,
+		bankLabel:             captured.BankLabel,
+		peakPowerBudget:       nvmUint16(captured.PeakPowerBudget),
+		avgPowerBudget:        nvmUint16(captured.AvgPowerBudget),
+		packageSparingEnabled: nvmBool(captured.PackageSparingEnabled),
+		settings:              captured.Settings.toDeviceSettings(),
+	}
+}
+
+func newCaptureErrorLogEntry(entry errorLogEntry) captureErrorLogEntry {
+	result := captureErrorLogEntry{
+		LogType:         int(entry.logType),
+		LogLevel:        int(entry.logLevel),
+		SequenceNumber:  uint16(entry.sequenceNumber),
+		DimmID:          uint16(entry.entry.dimmID),
+		SystemTimestamp: uint64(entry.entry.systemTimestamp),
+		ErrorType:       uint8(entry.entry.errorType),
+		OutputData:      make([]uint8, len(entry.entry.outputData)),
+	}
+	for i, value := range entry.entry.outputData {
+		result.OutputData[i] = uint8(value)
+	}
+	return result
+}
+
+func (captured captureErrorLogEntry) toErrorLogEntry() errorLogEntry {
+	result := errorLogEntry{
+		logType:        errorLogTypeEnumAttr(captured.LogType),
+		logLevel:       errorLogLevelEnumAttr(captured.LogLevel),
+		sequenceNumber: nvmUint16(captured.SequenceNumber),
+	}
+	result.entry.dimmID = nvmUint16(captured.DimmID)
+	result.entry.systemTimestamp = nvmUint64(captured.SystemTimestamp)
+	result.entry.errorType = nvmUint8(captured.ErrorType)
+	for i, value := range captured.OutputData {
+		if i < len(result.entry.outputData) {
+			result.entry.outputData[i] = nvmUint8(value)
+		}
+	}
+	return result
+}
+
+func newCapturePlatform(platform platform) capturePlatform {
+	result := capturePlatform{
+		RegionsOpstat:        newCaptureOpstat(platform.regionsOpstat),
+		Regions:              make([]captureRegion, len(platform.regions)),
+		SocketsOpstat:        newCaptureOpstat(platform.socketsOpstat),
+		Sockets:              make([]captureSocket, len(platform.sockets)),
+		TopologyOpstat:       newCaptureOpstat(platform.topologyOpstat),
+		Topology:             make([]captureMemoryTopology, len(platform.topology)),
+		HostOpstat:           newCaptureOpstat(platform.hostOpstat),
+		Host:                 newCaptureHost(platform.host),
+		SwInventoryOpstat:    newCaptureOpstat(platform.swInventoryOpstat),
+		SwInventory:          newCaptureSwInventory(platform.swInventory),
+		LibraryVersionOpstat: newCaptureOpstat(platform.libraryVersionOpstat),
+		LibraryVersion:       newCaptureLibraryVersion(platform.libraryVersion),
+		CapabilitiesOpstat:   newCaptureOpstat(platform.capabilitiesOpstat),
+		Capabilities:         newCaptureCapabilities(&platform.capabilities),
+		EventsOpstat:         newCaptureOpstat(platform.eventsOpstat),
+		Events:               make([]captureEvent, len(platform.events)),
+	}
+	for i, region := range platform.regions {
+		result.Regions[i] = newCaptureRegion(region)
+	}
+	for i, s := range platform.sockets {
+		result.Sockets[i] = newCaptureSocket(s)
+	}
+	for i, t := range platform.topology {
+		result.Topology[i] = newCaptureMemoryTopology(t)
+	}
+	for i, e := range platform.events {
+		result.Events[i] = newCaptureEvent(e)
+	}
+	return result
+}
+
+func (captured capturePlatform) toPlatform() platform {
+	result := platform{
+		regionsOpstat:        toCapturedOpstat(captured.RegionsOpstat),
+		regions:              make([]region, len(captured.Regions)),
+		socketsOpstat:        toCapturedOpstat(captured.SocketsOpstat),
+		sockets:              make([]socket, len(captured.Sockets)),
+		topologyOpstat:       toCapturedOpstat(captured.TopologyOpstat),
+		topology:             make([]memoryTopology, len(captured.Topology)),
+		hostOpstat:           toCapturedOpstat(captured.HostOpstat),
+		swInventoryOpstat:    toCapturedOpstat(captured.SwInventoryOpstat),
+		libraryVersionOpstat: toCapturedOpstat(captured.LibraryVersionOpstat),
+		capabilitiesOpstat:   toCapturedOpstat(captured.CapabilitiesOpstat),
+		eventsOpstat:         toCapturedOpstat(captured.EventsOpstat),
+		events:               make([]event, len(captured.Events)),
+	}
+	for i, region := range captured.Regions {
+		result.regions[i] = region.toRegion()
+	}
+	for i, s := range captured.Sockets {
+		result.sockets[i] = s.toSocket()
+	}
+	for i, t := range captured.Topology {
+		result.topology[i] = t.toMemoryTopology()
+	}
+	for i, e := range captured.Events {
+		result.events[i] = e.toEvent()
+	}
+	if captured.Host != nil {
+		result.host = captured.Host.toHost()
+	}
+	if captured.SwInventory != nil {
+		result.swInventory = captured.SwInventory.toSwInventory()
+	}
+	if captured.LibraryVersion != nil {
+		result.libraryVersion = parseLibraryVersion(nvmVersion(*captured.LibraryVersion))
+	}
+	if captured.Capabilities != nil {
+		result.capabilities = captured.Capabilities.toNVMCapabilities()
+	}
+	return result
+}
+
+func newCaptureSocket(s socket) captureSocket {
+	return captureSocket{
+		ID:                uint16(s.id),
+		MappedMemoryLimit: uint64(s.mappedMemoryLimit),
+		TotalMappedMemory: uint64(s.totalMappedMemory),
+	}
+}
+
+func (captured captureSocket) toSocket() socket {
+	return socket{
+		id:                nvmUint16(captured.ID),
+		mappedMemoryLimit: nvmUint64(captured.MappedMemoryLimit),
+		totalMappedMemory: nvmUint64(captured.TotalMappedMemory),
+	}
+}
+
+func newCaptureMemoryTopology(topology memoryTopology) captureMemoryTopology {
+	return captureMemoryTopology{
+		PhysicalID:    uint16(topology.physicalID),
+		MemoryType:    int(topology.memoryType),
+		DeviceLocator: topology.deviceLocator,
+		BankLabel:     topology.bankLabel,
+	}
+}
+
+func (captured captureMemoryTopology) toMemoryTopology() memoryTopology {
+	return memoryTopology{
+		physicalID:    nvmUint16(captured.PhysicalID),
+		memoryType:    memoryTypeEnumAttr(captured.MemoryType),
+		deviceLocator: captured.DeviceLocator,
+		bankLabel:     captured.BankLabel,
+	}
+}
+
+func newCaptureHost(h host) *captureHost {
+	return &captureHost{
+		Name:         h.name,
+		OsType:       int(h.osType),
+		OsName:       h.osName,
+		OsVersion:    h.osVersion,
+		MixedSKU:     bool(h.mixedSku),
+		SKUViolation: bool(h.skuViolation),
+	}
+}
+
+func (captured captureHost) toHost() host {
+	return host{
+		name:         captured.Name,
+		osType:       osTypeEnumAttr(captured.OsType),
+		osName:       captured.OsName,
+		osVersion:    captured.OsVersion,
+		mixedSku:     nvmBool(captured.MixedSKU),
+		skuViolation: nvmBool(captured.SKUViolation),
+	}
+}
+
+func newCaptureSwInventory(inventory swInventory) *captureSwInventory {
+	return &captureSwInventory{
+		MgmtSwRevision:         string(inventory.mgmtSwRevision),
+		VendorDriverRevision:   string(inventory.vendorDriverRevision),
+		VendorDriverCompatible: bool(inventory.vendorDriverCompatible),
+	}
+}
+
+func (captured captureSwInventory) toSwInventory() swInventory {
+	return swInventory{
+		mgmtSwRevision:         nvmVersion(captured.MgmtSwRevision),
+		vendorDriverRevision:   nvmVersion(captured.VendorDriverRevision),
+		vendorDriverCompatible: nvmBool(captured.VendorDriverCompatible),
+	}
+}
+
+// newCaptureLibraryVersion saves only the version string, all of its parts
+// are parsed from it on replay
+func newCaptureLibraryVersion(version libraryVersion) *string {
+	result := string(version.version)
+	return &result
+}
+
+func newCaptureCapabilities(capabilities *nvmCapabilities) *captureCapabilities {
+	platform := &capabilities.platformCapabilities
+	result := &captureCapabilities{
+		Features:         make(map[string]bool),
+		Platform:         make(map[string]bool),
+		MemoryModes:      make(map[string]captureMemoryCapabilities),
+		MinNamespaceSize: uint64(capabilities.swCapabilities.minNamespaceSize),
+		VolatileMode:     int(platform.currentVolatileMode),
+		AppDirectMode:    int(platform.currentAppDirectMode),
+		MixedSKU:         bool(capabilities.dimmSKUCapabilities.mixedSKU),
+		SKUViolation:     bool(capabilities.dimmSKUCapabilities.skuViolation),
+	}
+	for _, feature := range nvmFeatureNames {
+		result.Features[feature.name] = bool(*feature.value(&capabilities.nvmFeatures))
+	}
+	for _, capability := range platformCapabilityNames {
+		result.Platform[capability.name] = bool(*capability.value(capabilities))
+	}
+	for _, mode := range memoryModeNames {
+		memory := mode.value(platform)
+		count := int(memory.interleaveFormatsCount)
+		if count > len(memory.interleaveFormats) {
+			count = len(memory.interleaveFormats)
+		}
+		captured := captureMemoryCapabilities{
+			Supported:               bool(memory.supported),
+			InterleaveAlignmentSize: uint16(memory.interleaveAlignmentSize),
+			InterleaveFormats:       make([]captureInterleaveFormat, count),
+		}
+		for i := 0; i < count; i++ {
+			format := memory.interleaveFormats[i]
+			captured.InterleaveFormats[i] = captureInterleaveFormat{
+				Recommended: bool(format.recommended),
+				Channel:     int(format.channel),
+				IMC:         int(format.imc),
+				Ways:        int(format.ways),
+			}
+		}
+		result.MemoryModes[mode.name] = captured
+	}
+	return result
+}
+
+func (captured captureCapabilities) toNVMCapabilities() nvmCapabilities {
+	var result nvmCapabilities
+	platform := &result.platformCapabilities
+	result.swCapabilities.minNamespaceSize = nvmUint64(captured.MinNamespaceSize)
+	platform.currentVolatileMode = volatileModeEnumAttr(captured.VolatileMode)
+	platform.currentAppDirectMode = appDirectModeEnumAttr(captured.AppDirectMode)
+	result.dimmSKUCapabilities.mixedSKU = nvmBool(captured.MixedSKU)
+	result.dimmSKUCapabilities.skuViolation = nvmBool(captured.SKUViolation)
+	for _, feature := range nvmFeatureNames {
+		*feature.value(&result.nvmFeatures) = nvmBool(captured.Features[feature.name])
+	}
+	for _, capability := range platformCapabilityNames {
+		*capability.value(&result) = nvmBool(captured.Platform[capability.name])
+	}
+	for _, mode := range memoryModeNames {
+		memory := mode.value(platform)
+		capturedMode := captured.MemoryModes[mode.name]
+		memory.supported = nvmBool(capturedMode.Supported)
+		memory.interleaveAlignmentSize = nvmUint16(capturedMode.InterleaveAlignmentSize)
+		for i, format := range capturedMode.InterleaveFormats {
+			if i >= len(memory.interleaveFormats) {
+				break
+			}
+			memory.interleaveFormats[i] = interleaveFormat{
+				recommended: nvmBool(format.Recommended),
+				channel:     interleaveSizeEnumAttr(format.Channel),
+				imc:         interleaveSizeEnumAttr(format.IMC),
+				ways:        interleaveWaysEnumAttr(format.Ways),
+			}
+			memory.interleaveFormatsCount++
+		}
+	}
+	return result
+}
+
+func newCaptureRegion(region region) captureRegion {
+	result := captureRegion{
+		ISetID:       uint64(region.isetId),
+		Type:         int(region.rtype),
+		Capacity:     uint64(region.capacity),
+		FreeCapacity: uint64(region.freeCapacity),
+		SocketID:     uint16(region.socketID),
+		DIMMs:        make([]uint16, 0, region.dimmCount),
+		Health:       int(region.health),
+	}
+	for _, dimm := range region.memberIDs() {
+		result.DIMMs = append(result.DIMMs, uint16(dimm))
+	}
+	return result
+}
+
+func (captured captureRegion) toRegion() region {
+	result := region{
+		isetId:       nvmUint64(captured.ISetID),
+		rtype:        regionTypeEnumAttr(captured.Type),
+		capacity:     nvmUint64(captured.Capacity),
+		freeCapacity: nvmUint64(captured.FreeCapacity),
+		socketID:     nvmUint16(captured.SocketID),
+		health:       regionHealthEnumAttr(captured.Health),
+	}
+	for i, dimm := range captured.DIMMs {
+		if i < len(result.dimms) {
+			result.dimms[i] = nvmUint16(dimm)
+			result.dimmCount++
+		}
+	}
+	return result
+}
+
+func newCaptureEvent(e event) captureEvent {
+	result := captureEvent{
+		EventID:    uint32(e.eventID),
+		Type:       int(e.etype),
+		Severity:   int(e.severity),
+		Code:       uint16(e.code),
+		UID:        string(e.uid),
+		Time:       uint64(e.time),
+		Message:    string(e.message),
+		Args:       make([]string, len(e.args)),
+		DiagResult: int(e.diagResult),
+	}
+	for i, arg := range e.args {
+		result.Args[i] = string(arg)
+	}
+	return result
+}
+
+func (captured captureEvent) toEvent() event {
+	result := event{
+		eventID:    nvmUint32(captured.EventID),
+		etype:      eventTypeEnumAttr(captured.Type),
+		severity:   eventSeverityEnumAttr(captured.Severity),
+		code:       nvmUint16(captured.Code),
+		uid:        nvmUID(captured.UID),
+		time:       timeT(captured.Time),
+		message:    nvmEventMsg(captured.Message),
+		diagResult: diagnosticResultEnumAttr(captured.DiagResult),
+	}
+	for i, arg := range captured.Args {
+		if i < len(result.args) {
+			result.args[i] = nvmEventArg(arg)
+		}
+	}
+	return result
+}
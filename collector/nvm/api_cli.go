@@ -0,0 +1,1139 @@
+/**
+ * Copyright (c) 2020-2021, Intel Corporation.
+ * SPDX-License-Identifier: BSD-3-Clause
+ **
+ * This package introduces wrapper for ipmctl library written in C.
+ * api_cli.go file contains cliBackend, which takes all the readings by
+ * running ipmctl command line tool and parsing its XML output, instead of
+ * linking libipmctl. Both nvmxml (Linux, Windows) and esxcli (ESXi) flavours
+ * of the output are supported. It lets the exporter to be built without cgo.
+ */
+
+package nvm
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// cliRunner runs ipmctl with given arguments and returns its standard output
+type cliRunner func(args ...string) ([]byte, error)
+
+// cliRecord is a single entity (DIMM, sensor, performance counters) found in
+// ipmctl output, field name is mapped to its raw value
+type cliRecord map[string]string
+
+// cliNode is a generic XML element used to parse ipmctl output
+type cliNode struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Content string     `xml:",chardata"`
+	Nodes   []cliNode  `xml:",any"`
+}
+
+type cliDevice struct {
+	discovery         deviceDiscovery
+	sensors           map[sensorTypeEnumAttr]sensor
+	performance       devicePerformance
+	performanceOpstat nvmStatusCodeEnumAttr
+	status            deviceStatus
+	statusOpstat      nvmStatusCodeEnumAttr
+	fwInfo            deviceFWInfo
+	fwInfoOpstat      nvmStatusCodeEnumAttr
+	capacities        deviceCapacities
+	capacitiesOpstat  nvmStatusCodeEnumAttr
+	settings          deviceSettings
+	settingsOpstat    nvmStatusCodeEnumAttr
+	details           deviceDetails
+	detailsOpstat     nvmStatusCodeEnumAttr
+}
+
+// cliBackend reads DCPMMs with the use of ipmctl command line tool, all
+// the readings are refreshed at the beginning of every reading cycle
+type cliBackend struct {
+	unsupportedBackend
+	run            cliRunner
+	devices        []cliDevice
+	index          map[string]int
+	regions        []region
+	regionsOpstat  nvmStatusCodeEnumAttr
+	sockets        []socket
+	socketsOpstat  nvmStatusCodeEnumAttr
+	topology       []memoryTopology
+	topologyOpstat nvmStatusCodeEnumAttr
+	host           host
+	hostOpstat     nvmStatusCodeEnumAttr
+	version        []byte
+	lock           sync.Mutex
+}
+
+var cliSensorTypes = map[string]sensorTypeEnumAttr{
+	"health":                      sensorTypeEnum.sensorHealth,
+	"mediatemperature":            sensorTypeEnum.sensorMediaTemperature,
+	"controllertemperature":       sensorTypeEnum.sensorControllerTemperature,
+	"percentageremaining":         sensorTypeEnum.sensorPercentageRemaining,
+	"latcheddirtyshutdowncount":   sensorTypeEnum.sensorLatchedDirtyShutdownCount,
+	"powerontime":                 sensorTypeEnum.sensorPowerontime,
+	"uptime":                      sensorTypeEnum.sensorUptime,
+	"powercycles":                 sensorTypeEnum.sensorPowerCycles,
+	"fwerrorcount":                sensorTypeEnum.sensorFWerrorlogcount,
+	"unlatcheddirtyshutdowncount": sensorTypeEnum.sensorUnlachedDirtyShutdownCount,
+}
+
+// NewCLIBackend creates backend running ipmctl binary found at given path
+func NewCLIBackend(ipmctlPath string) Backend {
+	return newCLIBackend(func(args ...string) ([]byte, error) {
+		var stderr bytes.Buffer
+		cmd := exec.Command(ipmctlPath, args...)
+		cmd.Stderr = &stderr
+		output, err := cmd.Output()
+		if err != nil {
+			return output, fmt.Errorf("%s %s failed: %v %s", ipmctlPath,
+				strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+		}
+		return output, nil
+	})
+}
+
+func newCLIBackend(run cliRunner) *cliBackend {
+	return &cliBackend{run: run}
+}
+
+// Init runs ipmctl version to check ipmctl is available, the output is kept
+// for GetLibraryVersion, as the version does not change while running
+func (backend *cliBackend) Init() (bool, error) {
+	output, err := backend.run("version")
+	if err != nil {
+		return false, err
+	}
+	backend.version = output
+	return true, nil
+}
+
+func (backend *cliBackend) Uninit() (bool, error) {
+	return true, nil
+}
+
+// GetNumberOfDevices runs ipmctl to refresh all the readings
+func (backend *cliBackend) GetNumberOfDevices() (nvmStatusCodeEnumAttr, nvmUint8, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	if err := backend.refresh(); err != nil {
+		return nvmStatusCodeEnum.nvmErrOperationFailed, 0, err
+	}
+	return nvmStatusCodeEnum.nvmSuccess, nvmUint8(len(backend.devices)), nil
+}
+
+func (backend *cliBackend) GetDevices(count nvmUint8) (nvmStatusCodeEnumAttr, []deviceDiscovery, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	if int(count) > len(backend.devices) {
+		return nvmStatusCodeEnum.nvmErrInvalidParameter, nil,
+			fmt.Errorf("Requested %d devices, but only %d are present", count, len(backend.devices))
+	}
+	discoveries := make([]deviceDiscovery, count)
+	for i := range discoveries {
+		discoveries[i] = backend.devices[i].discovery
+	}
+	return nvmStatusCodeEnum.nvmSuccess, discoveries, nil
+}
+
+func (backend *cliBackend) GetDeviceDiscovery(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceDiscovery, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	dev, err := backend.find(deviceUID)
+	if err != nil {
+		return nvmStatusCodeEnum.nvmErrDIMMNotFound, deviceDiscovery{}, err
+	}
+	return nvmStatusCodeEnum.nvmSuccess, dev.discovery, nil
+}
+
+func (backend *cliBackend) GetSensor(deviceUID nvmUID,
+	stype sensorTypeEnumAttr) (nvmStatusCodeEnumAttr, sensor, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	dev, err := backend.find(deviceUID)
+	if err != nil {
+		return nvmStatusCodeEnum.nvmErrDIMMNotFound, sensor{}, err
+	}
+	result, found := dev.sensors[stype]
+	if !found {
+		return nvmStatusCodeEnum.nvmErrAPINotSupported, sensor{},
+			fmt.Errorf("Sensor %d of device %s not reported by ipmctl", stype, deviceUID)
+	}
+	return nvmStatusCodeEnum.nvmSuccess, result, nil
+}
+
+func (backend *cliBackend) GetDevicePerformance(deviceUID nvmUID) (nvmStatusCodeEnumAttr, devicePerformance, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	dev, err := backend.find(deviceUID)
+	if err != nil {
+		return nvmStatusCodeEnum.nvmErrDIMMNotFound, devicePerformance{}, err
+	}
+	if nvmStatusCodeEnum.nvmSuccess != dev.performanceOpstat {
+		err = fmt.Errorf("Performance of device %s not reported by ipmctl", deviceUID)
+	}
+	return dev.performanceOpstat, dev.performance, err
+}
+
+func (backend *cliBackend) GetDeviceStatus(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceStatus, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	dev, err := backend.find(deviceUID)
+	if err != nil {
+		return nvmStatusCodeEnum.nvmErrDIMMNotFound, deviceStatus{}, err
+	}
+	if nvmStatusCodeEnum.nvmSuccess != dev.statusOpstat {
+		err = fmt.Errorf("Status of device %s not reported by ipmctl", deviceUID)
+	}
+	return dev.statusOpstat, dev.status, err
+}
+
+func (backend *cliBackend) GetDeviceFwImageInfo(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceFWInfo, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	dev, err := backend.find(deviceUID)
+	if err != nil {
+		return nvmStatusCodeEnum.nvmErrDIMMNotFound, deviceFWInfo{}, err
+	}
+	if nvmStatusCodeEnum.nvmSuccess != dev.fwInfoOpstat {
+		err = fmt.Errorf("Firmware image information of device %s not reported by ipmctl", deviceUID)
+	}
+	return dev.fwInfoOpstat, dev.fwInfo, err
+}
+
+func (backend *cliBackend) GetDeviceCapacities(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceCapacities, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	dev, err := backend.find(deviceUID)
+	if err != nil {
+		return nvmStatusCodeEnum.nvmErrDIMMNotFound, deviceCapacities{}, err
+	}
+	if nvmStatusCodeEnum.nvmSuccess != dev.capacitiesOpstat {
+		err = fmt.Errorf("Capacities of device %s not reported by ipmctl", deviceUID)
+	}
+	return dev.capacitiesOpstat, dev.capacities, err
+}
+
+func (backend *cliBackend) GetDeviceSettings(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceSettings, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	dev, err := backend.find(deviceUID)
+	if err != nil {
+		return nvmStatusCodeEnum.nvmErrDIMMNotFound, deviceSettings{}, err
+	}
+	if nvmStatusCodeEnum.nvmSuccess != dev.settingsOpstat {
+		err = fmt.Errorf("Settings of device %s not reported by ipmctl", deviceUID)
+	}
+	return dev.settingsOpstat, dev.settings, err
+}
+
+func (backend *cliBackend) GetDeviceDetails(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceDetails, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	dev, err := backend.find(deviceUID)
+	if err != nil {
+		return nvmStatusCodeEnum.nvmErrDIMMNotFound, deviceDetails{}, err
+	}
+	if nvmStatusCodeEnum.nvmSuccess != dev.detailsOpstat {
+		err = fmt.Errorf("Details of device %s not reported by ipmctl", deviceUID)
+	}
+	return dev.detailsOpstat, dev.details, err
+}
+
+func (backend *cliBackend) GetRegions() (nvmStatusCodeEnumAttr, []region, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	var err error
+	if nvmStatusCodeEnum.nvmSuccess != backend.regionsOpstat {
+		err = fmt.Errorf("Regions not reported by ipmctl")
+	}
+	return backend.regionsOpstat, backend.regions, err
+}
+
+func (backend *cliBackend) GetSockets() (nvmStatusCodeEnumAttr, []socket, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	var err error
+	if nvmStatusCodeEnum.nvmSuccess != backend.socketsOpstat {
+		err = fmt.Errorf("Sockets not reported by ipmctl")
+	}
+	return backend.socketsOpstat, backend.sockets, err
+}
+
+func (backend *cliBackend) GetMemoryTopology() (nvmStatusCodeEnumAttr, []memoryTopology, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	var err error
+	if nvmStatusCodeEnum.nvmSuccess != backend.topologyOpstat {
+		err = fmt.Errorf("Memory topology not reported by ipmctl")
+	}
+	return backend.topologyOpstat, backend.topology, err
+}
+
+func (backend *cliBackend) GetHost() (nvmStatusCodeEnumAttr, host, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	var err error
+	if nvmStatusCodeEnum.nvmSuccess != backend.hostOpstat {
+		err = fmt.Errorf("Host not reported by ipmctl")
+	}
+	return backend.hostOpstat, backend.host, err
+}
+
+// GetLibraryVersion parses the output of ipmctl version taken by Init, ipmctl
+// reports the version of libipmctl it is linked with as the last word, e.g.:
+// Intel(R) Optane(TM) Persistent Memory Command Line Interface Version 02.00.00.3885
+func (backend *cliBackend) GetLibraryVersion() (nvmStatusCodeEnumAttr, libraryVersion, error) {
+	words := strings.Fields(string(backend.version))
+	if 0 == len(words) {
+		return nvmStatusCodeEnum.nvmErrOperationFailed, libraryVersion{}, fmt.Errorf("Version not reported by ipmctl")
+	}
+	return nvmStatusCodeEnum.nvmSuccess, parseLibraryVersion(nvmVersion(words[len(words)-1])), nil
+}
+
+// cliEventLogSize is the number of the newest events requested from ipmctl,
+// which reports only 50 of them by default
+const cliEventLogSize = 10000
+
+// GetEvents runs ipmctl show -event, events are reported with DimmID, which
+// is translated to the UID of the DCPMM found during the last refresh
+func (backend *cliBackend) GetEvents() (nvmStatusCodeEnumAttr, []event, error) {
+	output, err := backend.run("show", "-o", "nvmxml", "-event", fmt.Sprintf("Count=%d", cliEventLogSize))
+	if err != nil {
+		return nvmStatusCodeEnum.nvmErrOperationFailed, nil, err
+	}
+	records, err := parseCLIOutput(output)
+	if err != nil {
+		return nvmStatusCodeEnum.nvmErrOperationFailed, nil, err
+	}
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	events := make([]event, 0, len(records))
+	for _, record := range records {
+		if record.get("EventID") == "" {
+			continue
+		}
+		e := newCLIEvent(record)
+		if i, found := backend.index[strings.ToLower(record.get("DimmID"))]; found {
+			e.uid = backend.devices[i].discovery.uid
+		}
+		events = append(events, e)
+	}
+	return nvmStatusCodeEnum.nvmSuccess, events, nil
+}
+
+func (backend *cliBackend) find(deviceUID nvmUID) (*cliDevice, error) {
+	for i := range backend.devices {
+		if backend.devices[i].discovery.uid == deviceUID {
+			return &backend.devices[i], nil
+		}
+	}
+	return nil, fmt.Errorf("Device %s not found", deviceUID)
+}
+
+// refresh runs ipmctl show commands and rebuilds all the devices. Every
+// target is a separate ipmctl process (-dimm, -sensor, -performance,
+// -firmware, -region, -socket, -topology and -system -host), so a single
+// reading cycle forks 8 processes, plus one more for the event log
+func (backend *cliBackend) refresh() error {
+	dimms, err := backend.show("-dimm")
+	if err != nil {
+		return err
+	}
+	devices := make([]cliDevice, 0, len(dimms))
+	// sensors and performance are reported with DimmID, which may be either
+	// the handle or the UID of the DCPMM (depending on ipmctl preferences)
+	index := make(map[string]int)
+	for _, record := range dimms {
+		discovery := newCLIDeviceDiscovery(record)
+		if discovery.uid == "" {
+			continue
+		}
+		index[strings.ToLower(record.get("DimmID"))] = len(devices)
+		index[strings.ToLower(string(discovery.uid))] = len(devices)
+		status, statusOpstat := newCLIDeviceStatus(record)
+		capacities, capacitiesOpstat := newCLIDeviceCapacities(record)
+		settings, settingsOpstat := newCLIDeviceSettings(record)
+		details, detailsOpstat := newCLIDeviceDetails(record, discovery, settings)
+		devices = append(devices, cliDevice{
+			discovery:         discovery,
+			sensors:           make(map[sensorTypeEnumAttr]sensor),
+			performanceOpstat: nvmStatusCodeEnum.nvmErrAPINotSupported,
+			status:            status,
+			statusOpstat:      statusOpstat,
+			fwInfoOpstat:      nvmStatusCodeEnum.nvmErrAPINotSupported,
+			capacities:        capacities,
+			capacitiesOpstat:  capacitiesOpstat,
+			settings:          settings,
+			settingsOpstat:    settingsOpstat,
+			details:           details,
+			detailsOpstat:     detailsOpstat,
+		})
+	}
+	// sensors and performance are optional, DCPMMs with unsupported firmware
+	// report only the inventory
+	if sensors, err := backend.show("-sensor"); err == nil {
+		for _, record := range sensors {
+			i, found := index[strings.ToLower(record.get("DimmID"))]
+			stype, known := cliSensorTypes[strings.ToLower(record.get("Type"))]
+			if found && known {
+				devices[i].sensors[stype] = newCLISensor(stype, record)
+			}
+		}
+	}
+	if performance, err := backend.show("-performance"); err == nil {
+		for _, record := range performance {
+			if i, found := index[strings.ToLower(record.get("DimmID"))]; found {
+				devices[i].performance = newCLIDevicePerformance(record)
+				devices[i].performanceOpstat = nvmStatusCodeEnum.nvmSuccess
+			}
+		}
+	}
+	if firmware, err := backend.show("-firmware"); err == nil {
+		for _, record := range firmware {
+			if i, found := index[strings.ToLower(record.get("DimmID"))]; found {
+				devices[i].fwInfo = newCLIDeviceFWInfo(record)
+				devices[i].fwInfoOpstat = nvmStatusCodeEnum.nvmSuccess
+			}
+		}
+	}
+	backend.devices = devices
+	backend.index = index
+	backend.regions = nil
+	backend.regionsOpstat = nvmStatusCodeEnum.nvmErrAPINotSupported
+	if regions, err := backend.show("-region"); err == nil {
+		backend.regions = make([]region, 0, len(regions))
+		for _, record := range regions {
+			backend.regions = append(backend.regions, newCLIRegion(record, devices, index))
+		}
+		backend.regionsOpstat = nvmStatusCodeEnum.nvmSuccess
+	}
+	backend.sockets = nil
+	backend.socketsOpstat = nvmStatusCodeEnum.nvmErrAPINotSupported
+	if sockets, err := backend.show("-socket"); err == nil {
+		backend.sockets = make([]socket, 0, len(sockets))
+		for _, record := range sockets {
+			backend.sockets = append(backend.sockets, newCLISocket(record))
+		}
+		backend.socketsOpstat = nvmStatusCodeEnum.nvmSuccess
+	}
+	backend.topology = nil
+	backend.topologyOpstat = nvmStatusCodeEnum.nvmErrAPINotSupported
+	if topology, err := backend.show("-topology"); err == nil {
+		backend.topology = make([]memoryTopology, 0, len(topology))
+		for _, record := range topology {
+			backend.topology = append(backend.topology, newCLIMemoryTopology(record))
+		}
+		backend.topologyOpstat = nvmStatusCodeEnum.nvmSuccess
+	}
+	backend.host = host{}
+	backend.hostOpstat = nvmStatusCodeEnum.nvmErrAPINotSupported
+	if hosts, err := backend.show("-system", "-host"); err == nil && 0 != len(hosts) {
+		backend.host = newCLIHost(hosts[0])
+		backend.hostOpstat = nvmStatusCodeEnum.nvmSuccess
+	}
+	return nil
+}
+
+func (backend *cliBackend) show(targets ...string) ([]cliRecord, error) {
+	output, err := backend.run(append([]string{"show", "-o", "nvmxml", "-a"}, targets...)...)
+	if err != nil {
+		return nil, err
+	}
+	return parseCLIOutput(output)
+}
+
+// parseCLIOutput returns all records found in ipmctl XML output. A record is
+// any element, which children are all simple values, e.g.:
+//
+//	nvmxml: <Dimm><DimmID>0x0001</DimmID>...</Dimm>
+//	esxcli: <structure><field name="DimmID"><string>0x0001</string></field>...</structure>
+func parseCLIOutput(output []byte) ([]cliRecord, error) {
+	var root cliNode
+	if err := xml.Unmarshal(output, &root); err != nil {
+		return nil, fmt.Errorf("Unable to parse ipmctl output: %v", err)
+	}
+	records := make([]cliRecord, 0)
+	collectCLIRecords(&root, &records)
+	return records, nil
+}
+
+func collectCLIRecords(node *cliNode, records *[]cliRecord) {
+	if 0 == len(node.Nodes) {
+		return
+	}
+	record := make(cliRecord)
+	for i := range node.Nodes {
+		name, value, ok := node.Nodes[i].field()
+		if !ok {
+			for j := range node.Nodes {
+				collectCLIRecords(&node.Nodes[j], records)
+			}
+			return
+		}
+		record[strings.ToLower(name)] = value
+	}
+	*records = append(*records, record)
+}
+
+// field returns name and value of the element, if it holds a simple value
+func (node *cliNode) field() (string, string, bool) {
+	if 0 == len(node.Nodes) {
+		return node.XMLName.Local, strings.TrimSpace(node.Content), true
+	}
+	if node.XMLName.Local != "field" || len(node.Nodes) != 1 || len(node.Nodes[0].Nodes) != 0 {
+		return "", "", false
+	}
+	for _, attr := range node.Attrs {
+		if attr.Name.Local == "name" {
+			return attr.Value, strings.TrimSpace(node.Nodes[0].Content), true
+		}
+	}
+	return "", "", false
+}
+
+func (record cliRecord) get(name string) string {
+	return record[strings.ToLower(name)]
+}
+
+// getUint returns the value of the field as a number, both decimal and
+// hexadecimal values are accepted, any trailing units are ignored
+func (record cliRecord) getUint(name string) uint64 {
+	return parseCLIUint(record.get(name))
+}
+
+func (record cliRecord) getBool(name string) nvmBool {
+	switch strings.ToLower(record.get(name)) {
+	case "1", "true", "yes", "enabled":
+		return true
+	}
+	return false
+}
+
+// parseCLIUint returns the value as a number, 0 is returned for values
+// which do not fit in 64 bits, and the issue is logged
+func parseCLIUint(value string) uint64 {
+	result, err := parseCLINumber(value)
+	if err != nil {
+		log.Warn("ipmctl exporter - ", err)
+	}
+	return result
+}
+
+// parseCLINumber converts decimal or hexadecimal value to a number, any
+// trailing units are ignored and values not starting with a digit (e.g.
+// N/A) are reported as 0. Counters are reported as 128 bit numbers, these
+// are accepted as long as the value fits in 64 bits.
+func parseCLINumber(value string) (uint64, error) {
+	value = strings.TrimSpace(value)
+	base := 10
+	digits := value
+	if strings.HasPrefix(value, "0x") || strings.HasPrefix(value, "0X") {
+		base = 16
+		digits = strings.TrimLeft(value[2:], "0")
+	}
+	end := strings.IndexFunc(digits, func(r rune) bool {
+		if 16 == base {
+			return !strings.ContainsRune("0123456789abcdefABCDEF", r)
+		}
+		return r < '0' || r > '9'
+	})
+	if end >= 0 {
+		digits = digits[:end]
+	}
+	result, err := strconv.ParseUint("0"+digits, base, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Value reported by ipmctl out of range: %s", value)
+	}
+	return result, nil
+}
+
+// parseCLICapacity converts capacity reported with units (e.g. 502.599 GiB)
+// to bytes
+func parseCLICapacity(value string) nvmUint64 {
+	fields := strings.Fields(value)
+	if 0 == len(fields) {
+		return 0
+	}
+	number, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0
+	}
+	multiplier := 1.0
+	if len(fields) > 1 {
+		switch strings.ToLower(fields[1]) {
+		case "kib":
+			multiplier = 1 << 10
+		case "mib":
+			multiplier = 1 << 20
+		case "gib":
+			multiplier = 1 << 30
+		case "tib":
+			multiplier = 1 << 40
+		case "kb":
+			multiplier = 1e3
+		case "mb":
+			multiplier = 1e6
+		case "gb":
+			multiplier = 1e9
+		case "tb":
+			multiplier = 1e12
+		}
+	}
+	return nvmUint64(number * multiplier)
+}
+
+// parseCLIBytes converts hexadecimal value (e.g. serial number 0x0000a1b2)
+// to array of bytes
+func parseCLIBytes(value string) []nvmUint8 {
+	value = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(value), "0x"), "0X")
+	if 1 == len(value)%2 {
+		value = "0" + value
+	}
+	result := make([]nvmUint8, 0, len(value)/2)
+	for i := 0; i+2 <= len(value); i += 2 {
+		b, err := strconv.ParseUint(value[i:i+2], 16, 8)
+		if err != nil {
+			return []nvmUint8{}
+		}
+		result = append(result, nvmUint8(b))
+	}
+	return result
+}
+
+// parseCLIHealth converts health state reported by ipmctl to its value
+func parseCLIHealth(value string) healthStatusEnumAttr {
+	value = strings.ToLower(value)
+	switch {
+	case strings.HasPrefix(value, "healthy"):
+		return healthStatusEnum.healthStatusHealthy
+	case strings.HasPrefix(value, "noncritical"), strings.HasPrefix(value, "non-critical"):
+		return healthStatusEnum.healthStatusNonCriticalFailure
+	case strings.HasPrefix(value, "critical"):
+		return healthStatusEnum.healthStatusCriticalFailure
+	case strings.HasPrefix(value, "fatal"):
+		return healthStatusEnum.healthStatusFatalFailure
+	case strings.HasPrefix(value, "unmanageable"):
+		return healthStatusEnum.healthStatusUnmanageable
+	case strings.HasPrefix(value, "non-functional"), strings.HasPrefix(value, "nonfunctional"):
+		return healthStatusEnum.healthStatusNonFunctional
+	}
+	return healthStatusEnum.healthStatusUnknown
+}
+
+func parseCLISensorState(value string) sensorStatusEnumAttr {
+	switch strings.ToLower(strings.Replace(value, "-", "", -1)) {
+	case "normal":
+		return sensorStatusEnum.sensorNormal
+	case "noncritical":
+		return sensorStatusEnum.sensorNoncritical
+	case "critical":
+		return sensorStatusEnum.sensorCritical
+	case "fatal":
+		return sensorStatusEnum.sensorFatal
+	}
+	return sensorStatusEnum.sensorUnknown
+}
+
+func parseCLILockState(value string) lockStateEnumAttr {
+	value = strings.ToLower(value)
+	switch {
+	case strings.Contains(value, "exceeded"):
+		return lockStateEnum.lockStatePassphraseLimit
+	case strings.Contains(value, "frozen"):
+		return lockStateEnum.lockStateFrozen
+	case strings.Contains(value, "unlocked"):
+		return lockStateEnum.lockStateUnlocked
+	case strings.Contains(value, "locked"):
+		return lockStateEnum.lockStateLocked
+	case strings.Contains(value, "disabled"):
+		return lockStateEnum.lockStateDisable
+	case strings.Contains(value, "not supported"):
+		return lockStateEnum.lockStateNotSupported
+	}
+	return lockStateEnum.lockStateUnknown
+}
+
+func parseCLIManageability(value string) manageabilityStateEnumAttr {
+	switch strings.ToLower(value) {
+	case "manageable":
+		return manageabilityStateEnum.managementValidConfig
+	case "unmanageable":
+		return manageabilityStateEnum.managementInvalidConfig
+	}
+	return manageabilityStateEnum.managementUnknown
+}
+
+func parseCLIMemoryType(value string) memoryTypeEnumAttr {
+	value = strings.ToLower(value)
+	switch {
+	case strings.Contains(value, "non-volatile"), strings.Contains(value, "dcpm"):
+		return memoryTypeEnum.memoryTypeNVMDIMM
+	case strings.Contains(value, "ddr4"):
+		return memoryTypeEnum.memoryTypeDDR4
+	}
+	return memoryTypeEnum.memoryTypeUnknown
+}
+
+// parseCLIManufacturingDate converts YY-WW date to the raw value reported
+// by libipmctl (e.g. 19-41 is 0x1941)
+func parseCLIManufacturingDate(value string) nvmUint16 {
+	if strings.HasPrefix(value, "0x") {
+		return nvmUint16(parseCLIUint(value))
+	}
+	result, _ := strconv.ParseUint(strings.Replace(value, "-", "", -1), 16, 16)
+	return nvmUint16(result)
+}
+
+func newCLIDeviceHandle(handle uint64) nvmNfitDeviceHandle {
+	result := make([]byte, 32)
+	for i := 0; i < 4; i++ {
+		result[i] = byte(handle >> (8 * uint(i)))
+	}
+	return result
+}
+
+func newCLIDeviceDiscovery(record cliRecord) deviceDiscovery {
+	discovery := deviceDiscovery{
+		allPropertiesPopulated: true,
+		deviceHandle:           newCLIDeviceHandle(record.getUint("DimmHandle")),
+		physicalID:             nvmUint16(record.getUint("PhysicalID")),
+		vendorID:               nvmUint16(record.getUint("VendorID")),
+		deviceID:               nvmUint16(record.getUint("DeviceID")),
+		revisionID:             nvmUint16(record.getUint("RevisionID")),
+		channelPos:             nvmUint16(record.getUint("ChannelPos")),
+		channelID:              nvmUint16(record.getUint("ChannelID")),
+		memoryControllerID:     nvmUint16(record.getUint("MemControllerID")),
+		socketID:               nvmUint16(record.getUint("SocketID")),
+		nodeControllerID:       nvmUint16(record.getUint("NodeControllerID")),
+		memoryType:             parseCLIMemoryType(record.get("MemoryType")),
+		dimmSKU:                nvmUint32(record.getUint("SKU")),
+		manufacturer:           nvmManufacturer(parseCLIBytes(record.get("ManufacturerID"))),
+		serialNumber:           nvmSerialNumber(parseCLIBytes(record.get("SerialNumber"))),
+		subsystemVendorID:      nvmUint16(record.getUint("SubsystemVendorID")),
+		subsystemDeviceID:      nvmUint16(record.getUint("SubsystemDeviceID")),
+		subsystemRevisionID:    nvmUint16(record.getUint("SubsystemRevisionID")),
+		manufacturingInfoValid: record.getBool("ManufacturingInfoValid"),
+		manufacturingLocation:  nvmUint8(record.getUint("ManufacturingLocation")),
+		manufacturingDate:      parseCLIManufacturingDate(record.get("ManufacturingDate")),
+		partNumber:             record.get("PartNumber"),
+		fwRevision:             nvmVersion(record.get("FWVersion")),
+		fwAPIVersion:           nvmVersion(record.get("FWAPIVersion")),
+		capacity:               parseCLICapacity(record.get("Capacity")),
+		deviceCapabilities: deviceCapabilities{
+			packageSparingCapable: record.getBool("PackageSparingCapable"),
+			memoryModeCapable:     record.getBool("MemoryModeCapable"),
+			appDirectModeCapable:  record.getBool("AppDirectModeCapable"),
+		},
+		uid:                     nvmUID(record.get("DimmUID")),
+		lockState:               parseCLILockState(record.get("SecurityState") + record.get("LockState")),
+		manageability:           parseCLIManageability(record.get("ManageabilityState")),
+		controllerRevisionID:    nvmUint16(record.getUint("ControllerRevisionID")),
+		masterPassphraseEnabled: record.getBool("MasterPassphraseEnabled"),
+	}
+	securityCapabilities := strings.ToLower(record.get("SecurityCapabilities"))
+	discovery.securityCapabilities.passphraseCapable = nvmBool(strings.Contains(securityCapabilities, "encryption"))
+	discovery.securityCapabilities.unlockDeviceCapable = nvmBool(strings.Contains(securityCapabilities, "encryption"))
+	discovery.securityCapabilities.eraseCryptoCapable = nvmBool(strings.Contains(securityCapabilities, "erase"))
+	discovery.securityCapabilities.masterPassphraseCapable = record.getBool("MasterPassphraseCapable")
+	ifcs := strings.Split(record.get("InterfaceFormatCode"), ",")
+	for i := 0; i < len(ifcs) && i < len(discovery.interfaceFormatCodes); i++ {
+		discovery.interfaceFormatCodes[i] = nvmUint16(parseCLIUint(ifcs[i]))
+	}
+	return discovery
+}
+
+// newCLIDeviceStatus returns status of the DCPMM reported together with its
+// inventory, older ipmctl versions do not report it at all
+func newCLIDeviceStatus(record cliRecord) (deviceStatus, nvmStatusCodeEnumAttr) {
+	if record.get("ConfigurationStatus") == "" {
+		return deviceStatus{}, nvmStatusCodeEnum.nvmErrAPINotSupported
+	}
+	status := deviceStatus{
+		health:                            nvmUint8(parseCLIHealth(record.get("HealthState"))),
+		isNew:                             record.getBool("IsNew"),
+		packageSparesAvailable:            nvmUint8(record.getUint("PackageSparesAvailable")),
+		configStatus:                      parseCLIConfigStatus(record.get("ConfigurationStatus")),
+		lastShutdownTime:                  parseCLITime(record.get("LastShutdownTime")),
+		lastShutdownStatusDetails:         parseLastShutdownStatus(record.get("LatchedLastShutdownStatus")),
+		unlachedLastShutdownStatusDetails: parseLastShutdownStatus(record.get("UnlatchedLastShutdownStatus")),
+		skuViolation:                      record.getBool("SKUViolation"),
+		viralState:                        record.getBool("ViralState"),
+		arsStatus:                         parseCLIARSStatus(record.get("ARSStatus")),
+		overwritedimmStatus:               parseCLIOverwriteStatus(record.get("OverwriteStatus")),
+		aitDRAMEnabled:                    record.getBool("AitDramEnabled"),
+		bootStatus:                        nvmUint64(parseCLIUint(strings.Replace(record.get("BootStatusRegister"), "_", "", -1))),
+	}
+	status.isConfigured = nvmBool(configStatusEnum.configStatusValid == status.configStatus)
+	return status, nvmStatusCodeEnum.nvmSuccess
+}
+
+// newCLIDeviceSettings returns viral policy of the DCPMM reported together
+// with its inventory
+func newCLIDeviceSettings(record cliRecord) (deviceSettings, nvmStatusCodeEnumAttr) {
+	if record.get("ViralPolicy") == "" {
+		return deviceSettings{}, nvmStatusCodeEnum.nvmErrAPINotSupported
+	}
+	return deviceSettings{
+		viralPolicy: record.getBool("ViralPolicy"),
+		viralStatus: record.getBool("ViralState"),
+	}, nvmStatusCodeEnum.nvmSuccess
+}
+
+// newCLIDeviceDetails returns SMBIOS data, power budgets and package sparing
+// policy of the DCPMM reported together with its inventory, average power
+// budget is reported as AvgPowerLimit by newer ipmctl versions
+func newCLIDeviceDetails(record cliRecord,
+	discovery deviceDiscovery,
+	settings deviceSettings) (deviceDetails, nvmStatusCodeEnumAttr) {
+	if record.get("PackageSparingEnabled") == "" {
+		return deviceDetails{}, nvmStatusCodeEnum.nvmErrAPINotSupported
+	}
+	details := deviceDetails{
+		discovery:             discovery,
+		formFactor:            parseCLIFormFactor(record.get("FormFactor")),
+		dataWidth:             nvmUint64(record.getUint("DataWidth")),
+		totalWidth:            nvmUint64(record.getUint("TotalWidth")),
+		speed:                 nvmUint64(record.getUint("Speed")),
+		deviceLocator:         record.get("DeviceLocator"),
+		bankLabel:             record.get("BankLabel"),
+		peakPowerBudget:       nvmUint16(record.getUint("PeakPowerBudget")),
+		avgPowerBudget:        nvmUint16(record.getUint("AvgPowerBudget")),
+		packageSparingEnabled: record.getBool("PackageSparingEnabled"),
+		settings:              settings,
+	}
+	if record.get("AvgPowerBudget") == "" {
+		details.avgPowerBudget = nvmUint16(record.getUint("AvgPowerLimit"))
+	}
+	return details, nvmStatusCodeEnum.nvmSuccess
+}
+
+func parseCLIFormFactor(value string) deviceFromFactorEnumAttr {
+	switch strings.ToLower(value) {
+	case "dimm":
+		return deviceFromFactorEnum.deviceFromFactorDIMM
+	case "sodimm":
+		return deviceFromFactorEnum.deviceFromFactorSODIMM
+	}
+	return deviceFromFactorEnum.deviceFromFactorUnknown
+}
+
+// newCLIDeviceCapacities returns capacities of the DCPMM reported together
+// with its inventory, mirrored app direct capacity is not reported per DCPMM
+func newCLIDeviceCapacities(record cliRecord) (deviceCapacities, nvmStatusCodeEnumAttr) {
+	if record.get("MemoryCapacity") == "" {
+		return deviceCapacities{}, nvmStatusCodeEnum.nvmErrAPINotSupported
+	}
+	capacities := deviceCapacities{
+		capacity:             parseCLICapacity(record.get("Capacity")),
+		memoryCapacity:       parseCLICapacity(record.get("MemoryCapacity")),
+		appDirectoryCapacity: parseCLICapacity(record.get("AppDirectCapacity")),
+		unconfiguredCapacity: parseCLICapacity(record.get("UnconfiguredCapacity")),
+		inaccessibleCapacity: parseCLICapacity(record.get("InaccessibleCapacity")),
+		reservedCapacity:     parseCLICapacity(record.get("ReservedCapacity")),
+	}
+	return capacities, nvmStatusCodeEnum.nvmSuccess
+}
+
+// newCLIRegion returns region reported by ipmctl show -region, its member
+// DCPMMs are listed by DimmID and translated to their physical IDs
+func newCLIRegion(record cliRecord, devices []cliDevice, index map[string]int) region {
+	result := region{
+		isetId:       nvmUint64(record.getUint("ISetID")),
+		rtype:        parseCLIRegionType(record.get("PersistentMemoryType")),
+		capacity:     parseCLICapacity(record.get("Capacity")),
+		freeCapacity: parseCLICapacity(record.get("FreeCapacity")),
+		socketID:     nvmUint16(record.getUint("SocketID")),
+		health:       parseCLIRegionHealth(record.get("HealthState")),
+	}
+	for _, dimmID := range strings.Split(record.get("DimmID"), ",") {
+		i, found := index[strings.ToLower(strings.TrimSpace(dimmID))]
+		if found && int(result.dimmCount) < len(result.dimms) {
+			result.dimms[result.dimmCount] = devices[i].discovery.physicalID
+			result.dimmCount++
+		}
+	}
+	return result
+}
+
+// newCLISocket returns socket reported by ipmctl show -socket
+func newCLISocket(record cliRecord) socket {
+	return socket{
+		id:                nvmUint16(record.getUint("SocketID")),
+		mappedMemoryLimit: parseCLICapacity(record.get("MappedMemoryLimit")),
+		totalMappedMemory: parseCLICapacity(record.get("TotalMappedMemory")),
+	}
+}
+
+// newCLIMemoryTopology returns memory device reported by ipmctl show
+// -topology, DDR4 DIMMs are reported there along with DCPMMs
+func newCLIMemoryTopology(record cliRecord) memoryTopology {
+	return memoryTopology{
+		physicalID:    nvmUint16(record.getUint("PhysicalID")),
+		memoryType:    parseCLIMemoryType(record.get("MemoryType")),
+		deviceLocator: record.get("DeviceLocator"),
+		bankLabel:     record.get("BankLabel"),
+	}
+}
+
+// newCLIHost returns host server reported by ipmctl show -system -host
+func newCLIHost(record cliRecord) host {
+	return host{
+		name:         record.get("Name"),
+		osType:       parseCLIOsType(record.get("OsName")),
+		osName:       record.get("OsName"),
+		osVersion:    record.get("OsVersion"),
+		mixedSku:     record.getBool("MixedSKU"),
+		skuViolation: record.getBool("SKUViolation"),
+	}
+}
+
+func parseCLIOsType(value string) osTypeEnumAttr {
+	value = strings.ToLower(value)
+	switch {
+	case strings.Contains(value, "windows"):
+		return osTypeEnum.osTypeWindows
+	case strings.Contains(value, "esx"):
+		return osTypeEnum.osTypeEsx
+	case strings.Contains(value, "linux"):
+		return osTypeEnum.osTypeLinux
+	}
+	return osTypeEnum.osTypeUnknown
+}
+
+func parseCLIRegionType(value string) regionTypeEnumAttr {
+	value = strings.ToLower(value)
+	switch {
+	case strings.Contains(value, "mirror"):
+		return regionTypeEnum.regionTypePersistentMirror
+	case strings.HasPrefix(value, "appdirect"):
+		return regionTypeEnum.regionTypePersistent
+	case strings.Contains(value, "volatile"):
+		return regionTypeEnum.regionTypeVolatile
+	}
+	return regionTypeEnum.regionTypeUnknown
+}
+
+func parseCLIRegionHealth(value string) regionHealthEnumAttr {
+	switch strings.ToLower(value) {
+	case "healthy", "normal":
+		return regionHealthEnum.regionHealthNormal
+	case "error":
+		return regionHealthEnum.regionHealthError
+	case "pending":
+		return regionHealthEnum.regionHealthPending
+	case "locked":
+		return regionHealthEnum.regionHealthLocked
+	}
+	return regionHealthEnum.regionHealthUnknown
+}
+
+// newCLIDeviceFWInfo returns firmware image information reported by
+// ipmctl show -firmware, N/A is reported if no firmware is staged
+func newCLIDeviceFWInfo(record cliRecord) deviceFWInfo {
+	fwInfo := deviceFWInfo{
+		activeFwRevision: nvmVersion(record.get("ActiveFWVersion")),
+		stagedFwRevision: nvmVersion(record.get("StagedFWVersion")),
+		fwUpdateStatus:   parseCLIFwUpdateStatus(record.get("FWUpdateStatus")),
+	}
+	if strings.EqualFold(string(fwInfo.stagedFwRevision), "N/A") {
+		fwInfo.stagedFwRevision = ""
+	}
+	return fwInfo
+}
+
+func parseCLIFwUpdateStatus(value string) fwUpdateStatusEnumAttr {
+	switch strings.ToLower(value) {
+	case "staged":
+		return fwUpdateStatusEnum.fwUpdateStaged
+	case "success":
+		return fwUpdateStatusEnum.fwUpdateSuccess
+	case "failed", "fail":
+		return fwUpdateStatusEnum.fwUpdateFailed
+	}
+	return fwUpdateStatusEnum.fwUpdateUnknown
+}
+
+func parseCLIConfigStatus(value string) configStatusEnumAttr {
+	value = strings.ToLower(value)
+	switch {
+	case value == "valid":
+		return configStatusEnum.configStatusValid
+	case value == "not configured":
+		return configStatusEnum.configStatusNotConfigured
+	case strings.Contains(value, "bad configuration"):
+		return configStatusEnum.configStatusErrCorrupt
+	case strings.Contains(value, "broken interleave"):
+		return configStatusEnum.configStatusErrBrokenInterleave
+	case strings.Contains(value, "reverted"):
+		return configStatusEnum.configStatusErrReverted
+	case strings.Contains(value, "unsupported"):
+		return configStatusEnum.configStatusErrNotSupported
+	}
+	return configStatusEnum.configStatusUnknown
+}
+
+func parseCLIARSStatus(value string) deviceARSStatusEnumAttr {
+	switch strings.ToLower(value) {
+	case "not started":
+		return deviceARSStatusEnum.deviceARSStatusNotStarted
+	case "in progress":
+		return deviceARSStatusEnum.deviceARSStatusInprogress
+	case "completed":
+		return deviceARSStatusEnum.deviceARSStatusComplete
+	case "aborted":
+		return deviceARSStatusEnum.deviceARSStatusAborted
+	}
+	return deviceARSStatusEnum.deviceARSStatusUnknown
+}
+
+func parseCLIOverwriteStatus(value string) deviceOverwriteDIMMStatusEnumAttr {
+	switch strings.ToLower(value) {
+	case "not started":
+		return deviceOverwriteDIMMStatusEnum.deviceOverwriteDIMMStatusNotstarted
+	case "in progress":
+		return deviceOverwriteDIMMStatusEnum.deviceOverwriteDIMMStatusInprogress
+	case "completed":
+		return deviceOverwriteDIMMStatusEnum.deviceOverwriteDIMMStatusComplete
+	}
+	return deviceOverwriteDIMMStatusEnum.deviceOverwriteDIMMStatusUnknown
+}
+
+// parseCLITime converts date printed by ipmctl (e.g. Tue Apr 14 21:33:56 UTC
+// 2020) to seconds since epoch, 0 is returned for unknown dates
+func parseCLITime(value string) nvmUint64 {
+	result, err := time.Parse(time.UnixDate, strings.TrimSpace(value))
+	if err != nil || result.Unix() < 0 {
+		return 0
+	}
+	return nvmUint64(result.Unix())
+}
+
+func newCLIEvent(record cliRecord) event {
+	return event{
+		eventID:  nvmUint32(record.getUint("EventID")),
+		etype:    parseCLIEventCategory(record.get("Category")),
+		severity: parseCLIEventSeverity(record.get("Severity")),
+		code:     nvmUint16(record.getUint("Code")),
+		uid:      nvmUID(record.get("DimmUID")),
+		time:     timeT(parseCLIEventTime(record.get("Time"))),
+		message:  nvmEventMsg(record.get("Message")),
+	}
+}
+
+func parseCLIEventSeverity(value string) eventSeverityEnumAttr {
+	switch strings.ToLower(value) {
+	case "warning":
+		return eventSeverityEnum.eventSeverityWarn
+	case "error", "critical":
+		return eventSeverityEnum.eventSeverityCritical
+	case "fatal":
+		return eventSeverityEnum.eventSeverityFatal
+	}
+	return eventSeverityEnum.eventSeverityInfo
+}
+
+func parseCLIEventCategory(value string) eventTypeEnumAttr {
+	switch strings.ToLower(value) {
+	case "config":
+		return eventTypeEnum.eventTypeConfig
+	case "health", "sparing":
+		return eventTypeEnum.eventTypeHealth
+	case "mgmt":
+		return eventTypeEnum.eventTypeMgmt
+	case "diag":
+		return eventTypeEnum.eventTypeDiag
+	case "quick":
+		return eventTypeEnum.eventTypeDiagQuick
+	case "pm":
+		return eventTypeEnum.eventTypeDiagPlatformConfig
+	case "security":
+		return eventTypeEnum.eventTypeDiagSecurity
+	case "fw":
+		return eventTypeEnum.eventTypeDiagFWConsistency
+	}
+	return eventTypeEnum.eventTypeAll
+}
+
+// parseCLIEventTime converts time of the event to seconds since epoch, it is
+// printed either as a number of seconds or as a date (e.g. 04/14/2020
+// 21:33:56)
+func parseCLIEventTime(value string) nvmUint64 {
+	value = strings.TrimSpace(value)
+	if seconds, err := strconv.ParseUint(value, 10, 64); err == nil {
+		return nvmUint64(seconds)
+	}
+	if result, err := time.Parse("01/02/2006 15:04:05", value); err == nil {
+		return nvmUint64(result.Unix())
+	}
+	return parseCLITime(value)
+}
+
+func newCLISensor(stype sensorTypeEnumAttr, record cliRecord) sensor {
+	result := sensor{
+		stype:        stype,
+		currentState: parseCLISensorState(record.get("CurrentState")),
+		reading:      nvmUint64(record.getUint("CurrentValue")),
+		settings: sensorSettings{
+			enabled:                   record.getBool("EnabledState"),
+			upperCriticalThreshold:    nvmUint64(record.getUint("UpperThresholdCritical")),
+			lowerCriticalThreshold:    nvmUint64(record.getUint("LowerThresholdCritical")),
+			upperFatalThreshold:       nvmUint64(record.getUint("UpperThresholdFatal")),
+			lowerFatalThreshold:       nvmUint64(record.getUint("LowerThresholdFatal")),
+			upperNoncriticalThreshold: nvmUint64(record.getUint("UpperThresholdNonCritical")),
+			lowerNoncriticalThreshold: nvmUint64(record.getUint("LowerThresholdNonCritical")),
+		},
+	}
+	value := strings.ToLower(record.get("CurrentValue"))
+	switch {
+	case stype == sensorTypeEnum.sensorHealth:
+		result.units = sensorUnitsEnum.unitCount
+		result.reading = nvmUint64(parseCLIHealth(value))
+	case strings.HasSuffix(value, "c"):
+		result.units = sensorUnitsEnum.unitCelsius
+	case strings.HasSuffix(value, "%"):
+		result.units = sensorUnitsEnum.unitPercent
+	case strings.HasSuffix(value, "s"):
+		result.units = sensorUnitsEnum.unitSeconds
+	case stype == sensorTypeEnum.sensorPowerCycles:
+		result.units = sensorUnitsEnum.unitCycles
+	default:
+		result.units = sensorUnitsEnum.unitCount
+	}
+	normalize := strings.NewReplacer(" ", "", "-", "", "_", "")
+	settable := strings.ToLower(normalize.Replace(record.get("SettableThresholds")))
+	supported := strings.ToLower(normalize.Replace(record.get("SupportedThresholds")))
+	result.lowerCriticalSettable = nvmBool(strings.Contains(settable, "lowercritical"))
+	result.upperCriticalSettable = nvmBool(strings.Contains(settable, "uppercritical"))
+	result.lowerFatalSettable = nvmBool(strings.Contains(settable, "lowerfatal"))
+	result.upperFatalSettable = nvmBool(strings.Contains(settable, "upperfatal"))
+	result.lowerNoncriticalSettable = nvmBool(strings.Contains(settable, "lowernoncritical"))
+	result.upperNoncriticalSettable = nvmBool(strings.Contains(settable, "uppernoncritical"))
+	result.lowerCriticalSupport = nvmBool(strings.Contains(supported, "lowercritical"))
+	result.upperCriticalSupport = nvmBool(strings.Contains(supported, "uppercritical"))
+	result.lowerFatalSupport = nvmBool(strings.Contains(supported, "lowerfatal"))
+	result.upperFatalSupport = nvmBool(strings.Contains(supported, "upperfatal"))
+	result.lowerNoncriticalSupport = nvmBool(strings.Contains(supported, "lowernoncritical"))
+	result.upperNoncriticalSupport = nvmBool(strings.Contains(supported, "uppernoncritical"))
+	return result
+}
+
+func newCLIDevicePerformance(record cliRecord) devicePerformance {
+	return devicePerformance{
+		time:         timeT(time.Now().Unix()),
+		bytesRead:    nvmUint64(record.getUint("TotalMediaReads")),
+		hostReads:    nvmUint64(record.getUint("TotalReadRequests")),
+		bytesWritten: nvmUint64(record.getUint("TotalMediaWrites")),
+		hostWrites:   nvmUint64(record.getUint("TotalWriteRequests")),
+	}
+}
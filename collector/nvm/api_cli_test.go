@@ -0,0 +1,304 @@
+/**
+ * Copyright (c) 2020-2021, Intel Corporation.
+ * SPDX-License-Identifier: BSD-3-Clause
+ **
+ * api_cli_test.go file tests cliBackend against canned ipmctl outputs found
+ * in testdata/cli, both nvmxml and esxcli flavours.
+ */
+
+package nvm
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newFixtureCLIBackend creates cliBackend reading ipmctl outputs from given
+// testdata/cli directory, the file is named after the target of ipmctl show
+// (e.g. dimm.xml for -dimm), all the commands run are appended to calls
+func newFixtureCLIBackend(flavour string, calls *[]string) *cliBackend {
+	return newCLIBackend(func(args ...string) ([]byte, error) {
+		*calls = append(*calls, strings.Join(args, " "))
+		name := "version.txt"
+		for _, arg := range args[1:] {
+			if strings.HasPrefix(arg, "-") && arg != "-o" && arg != "-a" {
+				name = strings.TrimPrefix(arg, "-") + ".xml"
+			}
+		}
+		output, err := ioutil.ReadFile(filepath.Join("testdata", "cli", flavour, name))
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("ipmctl %s failed: exit status 1", strings.Join(args, " "))
+		}
+		return output, err
+	})
+}
+
+func readCLIFixture(t *testing.T, flavour string, name string) []cliRecord {
+	output, err := ioutil.ReadFile(filepath.Join("testdata", "cli", flavour, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := parseCLIOutput(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return records
+}
+
+func TestParseCLINumber(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected uint64
+		fails    bool
+	}{
+		{"42", 42, false},
+		{" 36C ", 36, false},
+		{"100%", 100, false},
+		{"0x0101", 257, false},
+		{"0X00ff", 255, false},
+		{"0x0301 (Non-Energy Backed Byte Addressable)", 769, false},
+		{"0x000000000000000000000000015e8b72", 0x15e8b72, false},
+		{"0x0000000000000000ffffffffffffffff", 0xffffffffffffffff, false},
+		{"18446744073709551615", 0xffffffffffffffff, false},
+		{"", 0, false},
+		{"N/A", 0, false},
+		{"0x", 0, false},
+		{"0x00000000000000010000000000000000", 0, true},
+		{"18446744073709551616", 0, true},
+	}
+	for _, test := range tests {
+		result, err := parseCLINumber(test.value)
+		if test.fails != (err != nil) {
+			t.Errorf("%q: expected failure %t, got error %v", test.value, test.fails, err)
+		}
+		if result != test.expected {
+			t.Errorf("%q: expected %d, got %d", test.value, test.expected, result)
+		}
+		if parsed := parseCLIUint(test.value); parsed != test.expected {
+			t.Errorf("%q: expected parseCLIUint to return %d, got %d", test.value, test.expected, parsed)
+		}
+	}
+}
+
+func TestParseCLIOutput(t *testing.T) {
+	nvmxml := readCLIFixture(t, "nvmxml", "dimm.xml")
+	esxcli := readCLIFixture(t, "esxcli", "dimm.xml")
+	if len(nvmxml) != 2 || len(esxcli) != 1 {
+		t.Fatalf("expected 2 nvmxml and 1 esxcli records, got %d and %d", len(nvmxml), len(esxcli))
+	}
+	for name, value := range esxcli[0] {
+		if nvmxml[0][name] != value {
+			t.Errorf("%s: esxcli value %q differs from nvmxml value %q", name, value, nvmxml[0][name])
+		}
+	}
+	if uid := nvmxml[1].get("DIMMUID"); uid != "8089-a2-1951-00005678" {
+		t.Errorf("expected field names to be case insensitive, got DimmUID %q", uid)
+	}
+	if _, err := parseCLIOutput([]byte("<DimmList><Dimm>")); err == nil {
+		t.Errorf("expected truncated output to fail")
+	}
+}
+
+func TestCollectCLIRecords(t *testing.T) {
+	output := []byte(`<SensorList>
+	 <Sensor><DimmID>0x0001</DimmID><Type>Health</Type></Sensor>
+	 <Group><Sensor><DimmID>0x0101</DimmID><Type>Health</Type></Sensor></Group>
+	 <Empty/>
+	</SensorList>`)
+	records, err := parseCLIOutput(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records of nested elements, got %d: %v", len(records), records)
+	}
+	if records[1].get("DimmID") != "0x0101" || records[1].get("Type") != "Health" {
+		t.Errorf("unexpected record %v", records[1])
+	}
+}
+
+func TestNewCLIDeviceDiscovery(t *testing.T) {
+	discovery := newCLIDeviceDiscovery(readCLIFixture(t, "nvmxml", "dimm.xml")[0])
+	if discovery.uid != "8089-a2-1951-00001234" {
+		t.Errorf("unexpected uid %s", discovery.uid)
+	}
+	if discovery.physicalID != 0x20 || discovery.channelPos != 1 || discovery.vendorID != 0x8980 {
+		t.Errorf("unexpected physical ID %#x, channel position %d or vendor ID %#x",
+			discovery.physicalID, discovery.channelPos, discovery.vendorID)
+	}
+	if discovery.deviceHandle[0] != 0x01 || discovery.deviceHandle[1] != 0x00 {
+		t.Errorf("unexpected handle %v", discovery.deviceHandle[:4])
+	}
+	if capacity := 126.688 * float64(1<<30); discovery.capacity != nvmUint64(capacity) {
+		t.Errorf("unexpected capacity %d", discovery.capacity)
+	}
+	if discovery.memoryType != memoryTypeEnum.memoryTypeNVMDIMM {
+		t.Errorf("unexpected memory type %d", discovery.memoryType)
+	}
+	if discovery.manufacturingDate != 0x1951 || discovery.manufacturingLocation != 0xa2 {
+		t.Errorf("unexpected manufacturing date %#x or location %#x",
+			discovery.manufacturingDate, discovery.manufacturingLocation)
+	}
+	if len(discovery.serialNumber) != 4 || discovery.serialNumber[2] != 0x12 || discovery.serialNumber[3] != 0x34 {
+		t.Errorf("unexpected serial number %v", discovery.serialNumber)
+	}
+	if discovery.interfaceFormatCodes[0] != 0x301 {
+		t.Errorf("unexpected interface format code %#x", discovery.interfaceFormatCodes[0])
+	}
+	if discovery.lockState != lockStateEnum.lockStateFrozen ||
+		discovery.manageability != manageabilityStateEnum.managementValidConfig {
+		t.Errorf("unexpected lock state %d or manageability %d", discovery.lockState, discovery.manageability)
+	}
+	if !discovery.deviceCapabilities.appDirectModeCapable || !discovery.securityCapabilities.eraseCryptoCapable {
+		t.Errorf("expected app direct and crypto erase capabilities")
+	}
+}
+
+func TestNewCLISensor(t *testing.T) {
+	sensors := readCLIFixture(t, "nvmxml", "sensor.xml")
+	health := newCLISensor(sensorTypeEnum.sensorHealth, sensors[0])
+	if health.reading != nvmUint64(healthStatusEnum.healthStatusHealthy) || health.units != sensorUnitsEnum.unitCount {
+		t.Errorf("unexpected health reading %d (units %d)", health.reading, health.units)
+	}
+	temperature := newCLISensor(sensorTypeEnum.sensorMediaTemperature, sensors[1])
+	if temperature.reading != 36 || temperature.units != sensorUnitsEnum.unitCelsius ||
+		temperature.currentState != sensorStatusEnum.sensorNormal {
+		t.Errorf("unexpected media temperature %d (units %d, state %d)",
+			temperature.reading, temperature.units, temperature.currentState)
+	}
+	settings := temperature.settings
+	if !settings.enabled || settings.upperNoncriticalThreshold != 82 ||
+		settings.upperCriticalThreshold != 83 || settings.upperFatalThreshold != 85 ||
+		settings.lowerCriticalThreshold != 0 {
+		t.Errorf("unexpected media temperature settings %+v", settings)
+	}
+	if !temperature.upperCriticalSupport || !temperature.upperNoncriticalSupport ||
+		temperature.lowerCriticalSupport || temperature.upperCriticalSettable {
+		t.Errorf("unexpected media temperature capabilities %+v", temperature)
+	}
+	remaining := newCLISensor(sensorTypeEnum.sensorPercentageRemaining, sensors[2])
+	if remaining.reading != 100 || remaining.units != sensorUnitsEnum.unitPercent ||
+		remaining.settings.enabled || remaining.settings.lowerNoncriticalThreshold != 50 ||
+		!remaining.lowerNoncriticalSettable || !remaining.lowerNoncriticalSupport {
+		t.Errorf("unexpected percentage remaining %+v", remaining)
+	}
+	powerOnTime := newCLISensor(sensorTypeEnum.sensorPowerontime, sensors[3])
+	if powerOnTime.reading != 28723968 || powerOnTime.units != sensorUnitsEnum.unitSeconds {
+		t.Errorf("unexpected power on time %d (units %d)", powerOnTime.reading, powerOnTime.units)
+	}
+	powerCycles := newCLISensor(sensorTypeEnum.sensorPowerCycles, sensors[4])
+	if powerCycles.reading != 237 || powerCycles.units != sensorUnitsEnum.unitCycles {
+		t.Errorf("unexpected power cycles %d (units %d)", powerCycles.reading, powerCycles.units)
+	}
+}
+
+func TestNewCLIDevicePerformance(t *testing.T) {
+	for _, flavour := range []string{"nvmxml", "esxcli"} {
+		performance := newCLIDevicePerformance(readCLIFixture(t, flavour, "performance.xml")[0])
+		if performance.bytesRead != 0x15e8b72 || performance.bytesWritten != 0xb24ec4 ||
+			performance.hostReads != 0xa3c87b || performance.hostWrites != 0x3fe0a5 {
+			t.Errorf("%s: unexpected performance %+v", flavour, performance)
+		}
+	}
+	// counters which do not fit in 64 bits are not reported as maximum value
+	performance := newCLIDevicePerformance(readCLIFixture(t, "nvmxml", "performance.xml")[1])
+	if performance.bytesRead != 0 || performance.bytesWritten != 0x10 {
+		t.Errorf("unexpected performance %+v", performance)
+	}
+}
+
+func TestCLIBackend(t *testing.T) {
+	var calls []string
+	backend := newFixtureCLIBackend("nvmxml", &calls)
+	if status, err := backend.Init(); !status {
+		t.Fatalf("Init failed: %v", err)
+	}
+	_, count, err := backend.GetNumberOfDevices()
+	if err != nil || count != 2 {
+		t.Fatalf("expected 2 devices, got %d (%v)", count, err)
+	}
+	_, discoveries, _ := backend.GetDevices(count)
+	uid := discoveries[1].uid
+	if opstat, sensor, _ := backend.GetSensor(uid, sensorTypeEnum.sensorMediaTemperature); opstat != nvmStatusCodeEnum.nvmSuccess ||
+		sensor.reading != 86 || sensor.currentState != sensorStatusEnum.sensorFatal {
+		t.Errorf("unexpected media temperature %d (state %d, status %d)", sensor.reading, sensor.currentState, opstat)
+	}
+	if opstat, _, _ := backend.GetSensor(uid, sensorTypeEnum.sensorPowerCycles); opstat != nvmStatusCodeEnum.nvmErrAPINotSupported {
+		t.Errorf("expected sensor not reported to be not supported, got status %d", opstat)
+	}
+	if opstat, fwInfo, _ := backend.GetDeviceFwImageInfo(uid); opstat != nvmStatusCodeEnum.nvmSuccess ||
+		fwInfo.stagedFwRevision != "01.02.00.5446" || fwInfo.fwUpdateStatus != fwUpdateStatusEnum.fwUpdateStaged {
+		t.Errorf("unexpected firmware %+v (status %d)", fwInfo, opstat)
+	}
+	if opstat, details, _ := backend.GetDeviceDetails(discoveries[0].uid); opstat != nvmStatusCodeEnum.nvmSuccess ||
+		details.avgPowerBudget != 15000 || details.deviceLocator != "CPU1_DIMM_A2" {
+		t.Errorf("unexpected details %+v (status %d)", details, opstat)
+	}
+	if opstat, _, _ := backend.GetDeviceDetails(uid); opstat != nvmStatusCodeEnum.nvmErrAPINotSupported {
+		t.Errorf("expected details not reported to be not supported, got status %d", opstat)
+	}
+	_, regions, _ := backend.GetRegions()
+	if len(regions) != 1 || regions[0].dimmCount != 2 || regions[0].dimms[1] != 0x26 ||
+		regions[0].isetId != 0x2d3c7f48f4e22ccc {
+		t.Errorf("unexpected regions %+v", regions)
+	}
+	_, sockets, _ := backend.GetSockets()
+	if len(sockets) != 1 || sockets[0].mappedMemoryLimit != 1<<40 {
+		t.Errorf("unexpected sockets %+v", sockets)
+	}
+	_, topology, _ := backend.GetMemoryTopology()
+	if len(topology) != 2 || topology[1].memoryType != memoryTypeEnum.memoryTypeDDR4 {
+		t.Errorf("unexpected topology %+v", topology)
+	}
+	_, host, _ := backend.GetHost()
+	if host.name != "pmem-host-01" || host.osType != osTypeEnum.osTypeLinux {
+		t.Errorf("unexpected host %+v", host)
+	}
+	if _, version, _ := backend.GetLibraryVersion(); version.version != "02.00.00.3885" || version.build != 3885 {
+		t.Errorf("unexpected version %+v", version)
+	}
+	_, events, _ := backend.GetEvents()
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(events))
+	}
+	if events[0].uid != uid || events[0].time != 1586900036 || events[0].severity != eventSeverityEnum.eventSeverityWarn {
+		t.Errorf("unexpected event %+v", events[0])
+	}
+	if events[1].severity != eventSeverityEnum.eventSeverityCritical || events[2].uid != "" ||
+		events[2].etype != eventTypeEnum.eventTypeMgmt {
+		t.Errorf("unexpected events %+v", events[1:])
+	}
+	// version is taken only once, a reading cycle runs all the show targets
+	// and the event log
+	if len(calls) != 10 {
+		t.Errorf("expected 10 ipmctl runs, got %d: %v", len(calls), calls)
+	}
+}
+
+func TestCLIBackendESXi(t *testing.T) {
+	var calls []string
+	backend := newFixtureCLIBackend("esxcli", &calls)
+	_, count, err := backend.GetNumberOfDevices()
+	if err != nil || count != 1 {
+		t.Fatalf("expected 1 device, got %d (%v)", count, err)
+	}
+	uid := nvmUID("8089-a2-1951-00001234")
+	if _, sensor, _ := backend.GetSensor(uid, sensorTypeEnum.sensorMediaTemperature); sensor.reading != 36 ||
+		sensor.settings.upperCriticalThreshold != 83 {
+		t.Errorf("unexpected media temperature %+v", sensor)
+	}
+	if opstat, performance, _ := backend.GetDevicePerformance(uid); opstat != nvmStatusCodeEnum.nvmSuccess ||
+		performance.bytesRead != 0x15e8b72 {
+		t.Errorf("unexpected performance %+v (status %d)", performance, opstat)
+	}
+	if opstat, _, _ := backend.GetDeviceFwImageInfo(uid); opstat != nvmStatusCodeEnum.nvmErrAPINotSupported {
+		t.Errorf("expected firmware which failed to be read to be not supported, got status %d", opstat)
+	}
+	if opstat, _, _ := backend.GetRegions(); opstat != nvmStatusCodeEnum.nvmErrAPINotSupported {
+		t.Errorf("expected regions which failed to be read to be not supported, got status %d", opstat)
+	}
+}
@@ -0,0 +1,168 @@
+/**
+ * Copyright (c) 2020-2021, Intel Corporation.
+ * SPDX-License-Identifier: BSD-3-Clause
+ **
+ * This package introduces wrapper for ipmctl library written in C.
+ * api_details.go file exposes external API for exporter to collect
+ * NVM device settings (viral policy) and device details metrics. Details
+ * carry SMBIOS Type 17 data of the DCPMM and its power budgets.
+ */
+
+package nvm
+
+var DeviceDetailsLabelNames = []string{
+	"uid",
+}
+
+var DeviceDetailsInfoLabelNames = []string{
+	"uid",
+	"form_factor",
+	"data_width",
+	"total_width",
+	"speed",
+	"device_locator",
+	"bank_label",
+}
+
+var devDetailsTypeEnum = &devDetailsType{
+	viralPolicy:           0,
+	viralStatus:           1,
+	packageSparingEnabled: 2,
+	detailsInfo:           3,
+	peakPowerBudget:       4,
+	avgPowerBudget:        5,
+	unknown:               0xFF,
+}
+
+type devDetailsReading MetricReading
+type devDetailsLabels MetricLabels
+type devDetailsInfoLabels MetricLabels
+type devDetailsTypeEnumAttr enumAttr
+type devDetailsType struct {
+	viralPolicy           devDetailsTypeEnumAttr
+	viralStatus           devDetailsTypeEnumAttr
+	packageSparingEnabled devDetailsTypeEnumAttr
+	detailsInfo           devDetailsTypeEnumAttr
+	peakPowerBudget       devDetailsTypeEnumAttr
+	avgPowerBudget        devDetailsTypeEnumAttr
+	unknown               devDetailsTypeEnumAttr
+}
+
+func (dl devDetailsLabels) GetLabelValues() []string {
+	return getValuesByName(DeviceDetailsLabelNames, MetricLabels(dl).labels)
+}
+
+func (dl devDetailsLabels) GetLabelNames() []string {
+	return getNamesByLabels(DeviceDetailsLabelNames, MetricLabels(dl).labels)
+}
+
+func (dl devDetailsLabels) addLabel(name string, value string) {
+	MetricLabels(dl).labels[name] = value
+}
+
+func (dl devDetailsInfoLabels) GetLabelValues() []string {
+	return getValuesByName(DeviceDetailsInfoLabelNames, MetricLabels(dl).labels)
+}
+
+func (dl devDetailsInfoLabels) GetLabelNames() []string {
+	return getNamesByLabels(DeviceDetailsInfoLabelNames, MetricLabels(dl).labels)
+}
+
+func (dl devDetailsInfoLabels) addLabel(name string, value string) {
+	MetricLabels(dl).labels[name] = value
+}
+
+func newDevDetailsReading(dimmUID nvmUID,
+	readStatus nvmStatusCodeEnumAttr,
+	metricType devDetailsTypeEnumAttr,
+	metricValue nvmUint64) *devDetailsReading {
+	devDetailsReading := new(devDetailsReading)
+	devDetailsReading.DIMMUID = string(dimmUID)
+	devDetailsReading.ReadStatus = int(readStatus)
+	devDetailsReading.MetricType = uint8(metricType)
+	devDetailsReading.MetricValue = float64(metricValue)
+	devDetailsReading.Labels = devDetailsLabels(*newMetricLabels())
+	return devDetailsReading
+}
+
+func (reader *MetricsReader) getDeviceDetailsReadings(metricType devDetailsTypeEnumAttr) []MetricReading {
+	results := make([]MetricReading, reader.deviceCount)
+	for i, dev := range reader.devices {
+		opstat := dev.detailsOpstat
+		metricValue := nvmUint64(0)
+		switch metricType {
+		case devDetailsTypeEnum.viralPolicy:
+			opstat = dev.settingsOpstat
+			metricValue = dev.settings.viralPolicy.toNvmUint64()
+		case devDetailsTypeEnum.viralStatus:
+			opstat = dev.settingsOpstat
+			metricValue = dev.settings.viralStatus.toNvmUint64()
+		case devDetailsTypeEnum.packageSparingEnabled:
+			metricValue = dev.details.packageSparingEnabled.toNvmUint64()
+		case devDetailsTypeEnum.peakPowerBudget:
+			metricValue = nvmUint64(dev.details.peakPowerBudget)
+		case devDetailsTypeEnum.avgPowerBudget:
+			metricValue = nvmUint64(dev.details.avgPowerBudget)
+		}
+		devDetailsReading := *newDevDetailsReading(dev.uid, opstat, metricType, metricValue)
+		devDetailsReading.Labels.addLabel("uid", string(dev.uid))
+		dev.addLocationLabel(devDetailsReading.Labels)
+		results[i] = MetricReading(devDetailsReading)
+	}
+	return results
+}
+
+// Indicates if viral policy is enabled on the DCPMM, so it enters viral
+// state on fatal errors to prevent corrupted data from being committed
+func (reader *MetricsReader) GetViralPolicyEnabled() []MetricReading {
+	return reader.getDeviceDetailsReadings(devDetailsTypeEnum.viralPolicy)
+}
+
+// Viral status of the DCPMM as reported with its viral policy
+func (reader *MetricsReader) GetViralStatus() []MetricReading {
+	return reader.getDeviceDetailsReadings(devDetailsTypeEnum.viralStatus)
+}
+
+// Indicates if package sparing is enabled on the DCPMM
+func (reader *MetricsReader) GetPackageSparingEnabled() []MetricReading {
+	return reader.getDeviceDetailsReadings(devDetailsTypeEnum.packageSparingEnabled)
+}
+
+// Describes SMBIOS data of the DCPMM: form factor, data and total width in
+// bits, speed in MT/s and physical labels of its slot
+func (reader *MetricsReader) GetDeviceDetailsInfo() []MetricReading {
+	results := make([]MetricReading, reader.deviceCount)
+	for i, dev := range reader.devices {
+		details := dev.details
+		devDetailsReading := *newDevDetailsReading(dev.uid, dev.detailsOpstat, devDetailsTypeEnum.detailsInfo, 1)
+		devDetailsReading.Labels = devDetailsInfoLabels(*newMetricLabels())
+		devDetailsReading.Labels.addLabel("uid", string(dev.uid))
+		devDetailsReading.Labels.addLabel("form_factor", getFormFactorName(details.formFactor))
+		devDetailsReading.Labels.addLabel("data_width", details.dataWidth.toString(10))
+		devDetailsReading.Labels.addLabel("total_width", details.totalWidth.toString(10))
+		devDetailsReading.Labels.addLabel("speed", details.speed.toString(10))
+		devDetailsReading.Labels.addLabel("device_locator", details.deviceLocator)
+		devDetailsReading.Labels.addLabel("bank_label", details.bankLabel)
+		dev.addLocationLabel(devDetailsReading.Labels)
+		results[i] = MetricReading(devDetailsReading)
+	}
+	return results
+}
+
+// milliwattsToWatts converts readings of power budgets reported in mW
+func milliwattsToWatts(readings []MetricReading) []MetricReading {
+	for i := range readings {
+		readings[i].MetricValue /= 1000
+	}
+	return readings
+}
+
+// Instantaneous power budget of the DCPMM in watts
+func (reader *MetricsReader) GetPeakPowerBudget() []MetricReading {
+	return milliwattsToWatts(reader.getDeviceDetailsReadings(devDetailsTypeEnum.peakPowerBudget))
+}
+
+// Average power budget of the DCPMM in watts
+func (reader *MetricsReader) GetAvgPowerBudget() []MetricReading {
+	return milliwattsToWatts(reader.getDeviceDetailsReadings(devDetailsTypeEnum.avgPowerBudget))
+}
@@ -0,0 +1,443 @@
+/**
+ * Copyright (c) 2020-2021, Intel Corporation.
+ * SPDX-License-Identifier: BSD-3-Clause
+ **
+ * This package introduces wrapper for ipmctl library written in C.
+ * api_errorlog.go file exposes external API for exporter to collect the
+ * firmware error log of NVM devices. Every DCPMM keeps four logs (media and
+ * thermal, each with low and high priority level). Every reading cycle the
+ * sequence numbers of the logs are read, and the entries logged since the
+ * previous reading cycle are fetched and kept in a bounded history, which
+ * is served as JSON by the error log endpoint.
+ */
+
+package nvm
+
+import (
+	"encoding/binary"
+	"sort"
+	"sync"
+	"time"
+)
+
+// errorLogHistoryCapacity is the number of the newest entries of every log
+// kept by the exporter for the error log endpoint, older entries are never
+// fetched
+const errorLogHistoryCapacity = 64
+
+// errorLogEntriesPerCycle is the number of entries fetched from a single log
+// in one reading cycle, the backend is kept locked while they are fetched
+const errorLogEntriesPerCycle = 4
+
+var FwErrorLogLabelNames = []string{
+	"uid",
+	"log_type",
+	"log_level",
+}
+
+var errorLogTypeEnum = &errorLogType{
+	media:   0,
+	thermal: 1,
+}
+
+var errorLogLevelEnum = &errorLogLevel{
+	low:  0,
+	high: 1,
+}
+
+var fwErrorLogTypeEnum = &fwErrorLogMetricType{
+	entries:        0,
+	sequenceNumber: 1,
+	unknown:        0xFF,
+}
+
+type fwErrorLogReading MetricReading
+type fwErrorLogLabels MetricLabels
+type errorLogTypeEnumAttr enumAttr
+type errorLogType struct {
+	media   errorLogTypeEnumAttr
+	thermal errorLogTypeEnumAttr
+}
+type errorLogLevelEnumAttr enumAttr
+type errorLogLevel struct {
+	low  errorLogLevelEnumAttr
+	high errorLogLevelEnumAttr
+}
+type fwErrorLogMetricTypeEnumAttr enumAttr
+type fwErrorLogMetricType struct {
+	entries        fwErrorLogMetricTypeEnumAttr
+	sequenceNumber fwErrorLogMetricTypeEnumAttr
+	unknown        fwErrorLogMetricTypeEnumAttr
+}
+
+func (fl fwErrorLogLabels) GetLabelValues() []string {
+	return getValuesByName(FwErrorLogLabelNames, MetricLabels(fl).labels)
+}
+
+func (fl fwErrorLogLabels) GetLabelNames() []string {
+	return getNamesByLabels(FwErrorLogLabelNames, MetricLabels(fl).labels)
+}
+
+func (fl fwErrorLogLabels) addLabel(name string, value string) {
+	MetricLabels(fl).labels[name] = value
+}
+
+// errorLogKey identifies one of the logs of the DCPMM
+type errorLogKey struct {
+	logType  errorLogTypeEnumAttr
+	logLevel errorLogLevelEnumAttr
+}
+
+// errorLogKeys lists all the logs of the DCPMM in the order they are read
+var errorLogKeys = []errorLogKey{
+	{errorLogTypeEnum.media, errorLogLevelEnum.low},
+	{errorLogTypeEnum.media, errorLogLevelEnum.high},
+	{errorLogTypeEnum.thermal, errorLogLevelEnum.low},
+	{errorLogTypeEnum.thermal, errorLogLevelEnum.high},
+}
+
+// errorLogEntry is an entry of the firmware error log fetched from the
+// backend, along with the log it was read from
+type errorLogEntry struct {
+	logType        errorLogTypeEnumAttr
+	logLevel       errorLogLevelEnumAttr
+	sequenceNumber nvmUint16
+	entry          errorLog
+}
+
+// ErrorLogEntry is a decoded entry of the firmware error log as served by
+// the error log endpoint, either media or thermal details are set
+type ErrorLogEntry struct {
+	LogType        string                `json:"log_type"`
+	LogLevel       string                `json:"log_level"`
+	SequenceNumber uint16                `json:"sequence_number"`
+	Time           time.Time             `json:"time"`
+	Media          *MediaErrorLogEntry   `json:"media,omitempty"`
+	Thermal        *ThermalErrorLogEntry `json:"thermal,omitempty"`
+}
+
+// MediaErrorLogEntry holds details of the media error log entry
+type MediaErrorLogEntry struct {
+	DPA      uint64 `json:"dpa"`
+	DPAValid bool   `json:"dpa_valid"`
+	PDA      uint64 `json:"pda"`
+	PDAValid bool   `json:"pda_valid"`
+	// length of the address space affected by the error
+	Range           uint8  `json:"range"`
+	ErrorType       string `json:"error_type"`
+	TransactionType string `json:"transaction_type"`
+	Interrupt       bool   `json:"interrupt"`
+	Viral           bool   `json:"viral"`
+}
+
+// ThermalErrorLogEntry holds details of the thermal error log entry
+type ThermalErrorLogEntry struct {
+	// temperature in Celsius degrees
+	Temperature int16  `json:"temperature"`
+	Reported    string `json:"reported"`
+	Sensor      string `json:"sensor"`
+}
+
+// DeviceErrorLog holds the entries of the firmware error log of single
+// DCPMM kept by the exporter
+type DeviceErrorLog struct {
+	UID     string          `json:"uid"`
+	Entries []ErrorLogEntry `json:"entries"`
+}
+
+// names of media error types and transaction types as defined by the
+// firmware interface specification, indexed by their raw values
+var mediaErrorTypeNames = []string{
+	"uncorrectable",
+	"dpa_mismatch",
+	"ait_error",
+	"data_path_error",
+	"locked_illegal_access",
+	"percentage_remaining_alarm",
+	"smart_health_change",
+}
+
+var mediaTransactionTypeNames = []string{
+	"2lm_read",
+	"2lm_write",
+	"pm_read",
+	"pm_write",
+	"reserved",
+	"reserved",
+	"ait_read",
+	"ait_write",
+	"wear_level_move",
+	"patrol_scrub",
+	"csr_read",
+	"csr_write",
+	"ars",
+	"unavailable",
+}
+
+var thermalReportedNames = []string{
+	"user_alarm",
+	"low",
+	"high",
+	"critical",
+}
+
+var thermalSensorNames = []string{
+	"media",
+	"controller",
+}
+
+func getRawValueName(names []string, value nvmUint8) string {
+	if int(value) < len(names) {
+		return names[value]
+	}
+	return "unknown"
+}
+
+// sequenceNumbers returns oldest and current sequence number of given log
+func (status *deviceErrorLogStatus) sequenceNumbers(key errorLogKey) fwErrorLogSequenceNumbers {
+	switch key {
+	case errorLogKey{errorLogTypeEnum.media, errorLogLevelEnum.low}:
+		return status.mediaLow
+	case errorLogKey{errorLogTypeEnum.media, errorLogLevelEnum.high}:
+		return status.mediaHigh
+	case errorLogKey{errorLogTypeEnum.thermal, errorLogLevelEnum.low}:
+		return status.thermLow
+	case errorLogKey{errorLogTypeEnum.thermal, errorLogLevelEnum.high}:
+		return status.thermHigh
+	}
+	return fwErrorLogSequenceNumbers{}
+}
+
+// count returns number of entries in the log. Sequence numbers wrap around,
+// current sequence number 0 means the log is empty.
+func (numbers fwErrorLogSequenceNumbers) count() uint64 {
+	if 0 == numbers.current {
+		return 0
+	}
+	return uint64(numbers.current-numbers.oldest) + 1
+}
+
+// contains indicates if entry of given sequence number is still in the log
+func (numbers fwErrorLogSequenceNumbers) contains(sequenceNumber nvmUint16) bool {
+	return uint64(sequenceNumber-numbers.oldest) < numbers.count()
+}
+
+// export decodes the entry to its representation served by the error log
+// endpoint, output data is laid out as THERMAL_ERROR_LOG or MEDIA_ERROR_LOG
+// structure of libipmctl depending on the type of the log
+func (e *errorLogEntry) export() ErrorLogEntry {
+	data := make([]byte, len(e.entry.outputData))
+	for i, value := range e.entry.outputData {
+		data[i] = byte(value)
+	}
+	result := ErrorLogEntry{
+		LogType:        getErrorLogTypeName(e.logType),
+		LogLevel:       getErrorLogLevelName(e.logLevel),
+		SequenceNumber: uint16(e.sequenceNumber),
+		Time:           time.Unix(int64(e.entry.systemTimestamp), 0).UTC(),
+	}
+	switch e.logType {
+	case errorLogTypeEnum.media:
+		result.Media = &MediaErrorLogEntry{
+			DPA:             binary.LittleEndian.Uint64(data[0:8]),
+			PDA:             binary.LittleEndian.Uint64(data[8:16]),
+			Range:           data[16],
+			ErrorType:       getRawValueName(mediaErrorTypeNames, nvmUint8(data[17])),
+			PDAValid:        0 != data[18],
+			DPAValid:        0 != data[19],
+			Interrupt:       0 != data[20],
+			Viral:           0 != data[21],
+			TransactionType: getRawValueName(mediaTransactionTypeNames, nvmUint8(data[22])),
+		}
+	case errorLogTypeEnum.thermal:
+		result.Thermal = &ThermalErrorLogEntry{
+			Temperature: int16(binary.LittleEndian.Uint16(data[0:2])),
+			Reported:    getRawValueName(thermalReportedNames, nvmUint8(data[2])),
+			Sensor:      getRawValueName(thermalSensorNames, nvmUint8(data[3])),
+		}
+	}
+	return result
+}
+
+// deviceErrorLogHistory keeps the entries of the logs of single DCPMM and
+// the sequence number of the last entry fetched from every log
+type deviceErrorLogHistory struct {
+	last    map[errorLogKey]nvmUint16
+	entries map[errorLogKey][]ErrorLogEntry
+}
+
+// errorLogHistory keeps the firmware error log entries fetched by the
+// exporter, it is shared with the error log endpoint, so all the fields are
+// guarded by the lock
+type errorLogHistory struct {
+	lock    sync.Mutex
+	devices map[nvmUID]*deviceErrorLogHistory
+}
+
+func newErrorLogHistory() *errorLogHistory {
+	return &errorLogHistory{
+		devices: make(map[nvmUID]*deviceErrorLogHistory),
+	}
+}
+
+// lastSequenceNumbers returns copy of the sequence numbers of the last
+// entries fetched from the logs of given DCPMM
+func (history *errorLogHistory) lastSequenceNumbers(uid nvmUID) map[errorLogKey]nvmUint16 {
+	history.lock.Lock()
+	defer history.lock.Unlock()
+	result := make(map[errorLogKey]nvmUint16)
+	if current, found := history.devices[uid]; found {
+		for key, sequenceNumber := range current.last {
+			result[key] = sequenceNumber
+		}
+	}
+	return result
+}
+
+// readErrorLogEntries fetches the entries of the firmware error log of the
+// DCPMM, which were logged since the previous reading cycle. Only the newest
+// entry of the log is fetched, when the log is read for the first time, and
+// no more than errorLogEntriesPerCycle entries afterwards, the entries left
+// are fetched in the next reading cycles. Entries, which were rolled out of
+// the log before they were fetched, are skipped. The sequence numbers of the
+// last entries fetched are returned along with them.
+func (reader *MetricsReader) readErrorLogEntries(dev *device) ([]errorLogEntry, map[errorLogKey]nvmUint16) {
+	last := reader.errorLogs.lastSequenceNumbers(dev.uid)
+	entries := make([]errorLogEntry, 0)
+	for _, key := range errorLogKeys {
+		numbers := dev.errorLogStatus.sequenceNumbers(key)
+		if 0 == numbers.count() {
+			// all the entries logged from now on are new
+			last[key] = numbers.current
+			continue
+		}
+		first := numbers.oldest
+		if sequenceNumber, found := last[key]; !found {
+			first = numbers.current
+		} else if numbers.contains(sequenceNumber) {
+			first = sequenceNumber + 1
+		}
+		if !numbers.contains(first) {
+			continue
+		}
+		if uint64(numbers.current-first) >= errorLogHistoryCapacity {
+			first = numbers.current - errorLogHistoryCapacity + 1
+		}
+		for sequenceNumber, fetched := first, 0; fetched < errorLogEntriesPerCycle; sequenceNumber, fetched = sequenceNumber+1, fetched+1 {
+			opstat, entry, _ := reader.backend.GetFwErrorLogEntry(dev.uid, sequenceNumber, key.logLevel, key.logType)
+			if nvmStatusCodeEnum.nvmSuccess == opstat {
+				entries = append(entries, errorLogEntry{key.logType, key.logLevel, sequenceNumber, entry})
+			} else if nvmStatusCodeEnum.nvmSuccessNoErrorLogEntry != opstat {
+				// the entries left are fetched in the next reading cycle
+				break
+			}
+			last[key] = sequenceNumber
+			if sequenceNumber == numbers.current {
+				break
+			}
+		}
+	}
+	return entries, last
+}
+
+// updateErrorLogHistory fetches new entries of the firmware error log of
+// all the devices read in the current reading cycle. Devices, which are no
+// longer present, are forgotten.
+func (reader *MetricsReader) updateErrorLogHistory() {
+	type update struct {
+		entries []errorLogEntry
+		last    map[errorLogKey]nvmUint16
+	}
+	updates := make(map[nvmUID]update, len(reader.devices))
+	for i := range reader.devices {
+		dev := &reader.devices[i]
+		dev.errorLogEntries = nil
+		if nvmStatusCodeEnum.nvmSuccess != dev.errorLogStatusOpstat {
+			updates[dev.uid] = update{nil, reader.errorLogs.lastSequenceNumbers(dev.uid)}
+			continue
+		}
+		entries, last := reader.readErrorLogEntries(dev)
+		dev.errorLogEntries = entries
+		updates[dev.uid] = update{entries, last}
+	}
+	history := reader.errorLogs
+	history.lock.Lock()
+	defer history.lock.Unlock()
+	devices := make(map[nvmUID]*deviceErrorLogHistory, len(updates))
+	for uid, u := range updates {
+		current, found := history.devices[uid]
+		if !found {
+			current = &deviceErrorLogHistory{entries: make(map[errorLogKey][]ErrorLogEntry)}
+		}
+		current.last = u.last
+		for _, e := range u.entries {
+			key := errorLogKey{e.logType, e.logLevel}
+			logEntries := append(current.entries[key], e.export())
+			if len(logEntries) > errorLogHistoryCapacity {
+				logEntries = append([]ErrorLogEntry{}, logEntries[len(logEntries)-errorLogHistoryCapacity:]...)
+			}
+			current.entries[key] = logEntries
+		}
+		devices[uid] = current
+	}
+	history.devices = devices
+}
+
+// GetErrorLogEntries returns the firmware error log entries kept by the
+// exporter for every DCPMM, or only for the DCPMM of given UID (if not empty)
+func (reader *MetricsReader) GetErrorLogEntries(uid string) []DeviceErrorLog {
+	history := reader.errorLogs
+	history.lock.Lock()
+	defer history.lock.Unlock()
+	results := make([]DeviceErrorLog, 0, len(history.devices))
+	for deviceUID, current := range history.devices {
+		if uid != "" && uid != string(deviceUID) {
+			continue
+		}
+		result := DeviceErrorLog{UID: string(deviceUID), Entries: make([]ErrorLogEntry, 0)}
+		for _, key := range errorLogKeys {
+			result.Entries = append(result.Entries, current.entries[key]...)
+		}
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].UID < results[j].UID
+	})
+	return results
+}
+
+func (reader *MetricsReader) getFwErrorLogReadings(metricType fwErrorLogMetricTypeEnumAttr) []MetricReading {
+	results := make([]MetricReading, 0, len(reader.devices)*len(errorLogKeys))
+	for _, dev := range reader.devices {
+		for _, key := range errorLogKeys {
+			numbers := dev.errorLogStatus.sequenceNumbers(key)
+			fwErrorLogReading := new(fwErrorLogReading)
+			fwErrorLogReading.DIMMUID = string(dev.uid)
+			fwErrorLogReading.ReadStatus = int(dev.errorLogStatusOpstat)
+			fwErrorLogReading.MetricType = uint8(metricType)
+			switch metricType {
+			case fwErrorLogTypeEnum.entries:
+				fwErrorLogReading.MetricValue = float64(numbers.count())
+			case fwErrorLogTypeEnum.sequenceNumber:
+				fwErrorLogReading.MetricValue = float64(numbers.current)
+			}
+			fwErrorLogReading.Labels = fwErrorLogLabels(*newMetricLabels())
+			fwErrorLogReading.Labels.addLabel("uid", string(dev.uid))
+			fwErrorLogReading.Labels.addLabel("log_type", getErrorLogTypeName(key.logType))
+			fwErrorLogReading.Labels.addLabel("log_level", getErrorLogLevelName(key.logLevel))
+			dev.addLocationLabel(fwErrorLogReading.Labels)
+			results = append(results, MetricReading(*fwErrorLogReading))
+		}
+	}
+	return results
+}
+
+// Number of entries currently stored in the firmware error log
+func (reader *MetricsReader) GetFwErrorLogEntries() []MetricReading {
+	return reader.getFwErrorLogReadings(fwErrorLogTypeEnum.entries)
+}
+
+// Sequence number of the newest entry of the firmware error log
+func (reader *MetricsReader) GetFwErrorLogSequenceNumber() []MetricReading {
+	return reader.getFwErrorLogReadings(fwErrorLogTypeEnum.sequenceNumber)
+}
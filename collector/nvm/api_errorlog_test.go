@@ -0,0 +1,118 @@
+/**
+ * Copyright (c) 2020-2021, Intel Corporation.
+ * SPDX-License-Identifier: BSD-3-Clause
+ **
+ * api_errorlog_test.go file tests the sequence numbers arithmetic of the
+ * firmware error log and the entries fetched in every reading cycle.
+ */
+
+package nvm
+
+import (
+	"testing"
+)
+
+func TestFwErrorLogSequenceNumbers(t *testing.T) {
+	tests := []struct {
+		name         string
+		numbers      fwErrorLogSequenceNumbers
+		count        uint64
+		contained    []nvmUint16
+		notContained []nvmUint16
+	}{
+		{"empty", fwErrorLogSequenceNumbers{oldest: 0, current: 0}, 0,
+			[]nvmUint16{}, []nvmUint16{0, 1}},
+		{"single entry", fwErrorLogSequenceNumbers{oldest: 1, current: 1}, 1,
+			[]nvmUint16{1}, []nvmUint16{0, 2}},
+		{"entries", fwErrorLogSequenceNumbers{oldest: 3, current: 10}, 8,
+			[]nvmUint16{3, 7, 10}, []nvmUint16{2, 11, 65535}},
+		{"wrapped around", fwErrorLogSequenceNumbers{oldest: 65534, current: 3}, 6,
+			[]nvmUint16{65534, 65535, 0, 3}, []nvmUint16{65533, 4, 10}},
+	}
+	for _, test := range tests {
+		if count := test.numbers.count(); count != test.count {
+			t.Errorf("%s: expected %d entries, got %d", test.name, test.count, count)
+		}
+		for _, sequenceNumber := range test.contained {
+			if !test.numbers.contains(sequenceNumber) {
+				t.Errorf("%s: expected entry %d in the log", test.name, sequenceNumber)
+			}
+		}
+		for _, sequenceNumber := range test.notContained {
+			if test.numbers.contains(sequenceNumber) {
+				t.Errorf("%s: expected entry %d not in the log", test.name, sequenceNumber)
+			}
+		}
+	}
+}
+
+// errorLogBackend is stubBackend with firmware error logs, entries of the
+// sequence numbers out of the log status are reported as rolled out
+type errorLogBackend struct {
+	stubBackend
+	status deviceErrorLogStatus
+}
+
+func (backend *errorLogBackend) GetFwErrLogStats(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceErrorLogStatus, error) {
+	return nvmStatusCodeEnum.nvmSuccess, backend.status, nil
+}
+
+func (backend *errorLogBackend) GetFwErrorLogEntry(deviceUID nvmUID,
+	sequenceNumber nvmUint16,
+	logLevel errorLogLevelEnumAttr,
+	logType errorLogTypeEnumAttr) (nvmStatusCodeEnumAttr, errorLog, error) {
+	if !backend.status.sequenceNumbers(errorLogKey{logType, logLevel}).contains(sequenceNumber) {
+		return nvmStatusCodeEnum.nvmSuccessNoErrorLogEntry, errorLog{}, nil
+	}
+	return nvmStatusCodeEnum.nvmSuccess, errorLog{systemTimestamp: nvmUint64(sequenceNumber)}, nil
+}
+
+func TestReadErrorLogEntries(t *testing.T) {
+	backend := &errorLogBackend{stubBackend: *newStubBackend(1, "8089-a2-1901-00001000")}
+	reader := NewMetricsReader(backend, false)
+	numbers := func(oldest, current nvmUint16) fwErrorLogSequenceNumbers {
+		return fwErrorLogSequenceNumbers{oldest: oldest, current: current}
+	}
+	steps := []struct {
+		name     string
+		status   deviceErrorLogStatus
+		expected []nvmUint16
+	}{
+		{"first cycle fetches newest only", deviceErrorLogStatus{mediaLow: numbers(1, 10)},
+			[]nvmUint16{10}},
+		{"new entries", deviceErrorLogStatus{mediaLow: numbers(1, 13)},
+			[]nvmUint16{11, 12, 13}},
+		{"entries over budget", deviceErrorLogStatus{mediaLow: numbers(1, 23)},
+			[]nvmUint16{14, 15, 16, 17}},
+		{"entries rolled out before fetch", deviceErrorLogStatus{mediaLow: numbers(20, 23)},
+			[]nvmUint16{20, 21, 22, 23}},
+		{"nothing new", deviceErrorLogStatus{mediaLow: numbers(20, 23)},
+			[]nvmUint16{}},
+		{"wrapped around", deviceErrorLogStatus{mediaLow: numbers(65534, 3)},
+			[]nvmUint16{65534, 65535, 0, 1}},
+		// thermal log was read empty before, so its first entries are new
+		{"entries left and empty log filled", deviceErrorLogStatus{mediaLow: numbers(65534, 3), thermHigh: numbers(1, 2)},
+			[]nvmUint16{2, 3, 1, 2}},
+	}
+	for _, step := range steps {
+		backend.status = step.status
+		if status, err := reader.GetRequiredReadings(); !status {
+			t.Fatalf("%s: GetRequiredReadings failed: %v", step.name, err)
+		}
+		entries := reader.devices[0].errorLogEntries
+		fetched := make([]nvmUint16, len(entries))
+		for i, entry := range entries {
+			fetched[i] = entry.sequenceNumber
+		}
+		if len(fetched) != len(step.expected) {
+			t.Errorf("%s: expected entries %v, got %v", step.name, step.expected, fetched)
+			continue
+		}
+		for i := range fetched {
+			if fetched[i] != step.expected[i] {
+				t.Errorf("%s: expected entries %v, got %v", step.name, step.expected, fetched)
+				break
+			}
+		}
+	}
+}
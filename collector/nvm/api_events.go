@@ -0,0 +1,241 @@
+/**
+ * Copyright (c) 2020-2021, Intel Corporation.
+ * SPDX-License-Identifier: BSD-3-Clause
+ **
+ * This package introduces wrapper for ipmctl library written in C.
+ * api_events.go file exposes external API for exporter to collect events
+ * stored in the event log of the backend (e.g. libipmctl event database).
+ * Every reading cycle the whole event log is read, and the events not seen
+ * before are counted and kept in a bounded history, which is served as JSON
+ * by the events endpoint.
+ */
+
+package nvm
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// eventHistoryCapacity is the number of the newest events kept by the
+// exporter for the events endpoint
+const eventHistoryCapacity = 1000
+
+var EventLabelNames = []string{
+	"uid",
+	"type",
+	"severity",
+}
+
+var eventMetricTypeEnum = &eventMetricType{
+	count:   0,
+	unknown: 0xFF,
+}
+
+type eventReading MetricReading
+type eventLabels MetricLabels
+type eventMetricTypeEnumAttr enumAttr
+type eventMetricType struct {
+	count   eventMetricTypeEnumAttr
+	unknown eventMetricTypeEnumAttr
+}
+
+func (el eventLabels) GetLabelValues() []string {
+	return getValuesByName(EventLabelNames, MetricLabels(el).labels)
+}
+
+func (el eventLabels) GetLabelNames() []string {
+	return EventLabelNames
+}
+
+func (el eventLabels) addLabel(name string, value string) {
+	MetricLabels(el).labels[name] = value
+}
+
+// Event is a single entry of the event log as served by the events endpoint.
+// Cursor is assigned by the exporter and grows with every event observed,
+// even if the event log was purged and its event IDs started over.
+type Event struct {
+	Cursor     uint64    `json:"cursor"`
+	EventID    uint32    `json:"event_id"`
+	Type       string    `json:"type"`
+	Severity   string    `json:"severity"`
+	Code       uint16    `json:"code"`
+	UID        string    `json:"uid"`
+	Time       time.Time `json:"time"`
+	Message    string    `json:"message"`
+	Args       []string  `json:"args"`
+	DiagResult string    `json:"diag_result,omitempty"`
+}
+
+// EventBatch holds events observed after given cursor
+type EventBatch struct {
+	// cursor of the newest event observed, to be passed with the next request
+	Cursor uint64 `json:"cursor"`
+	// number of events dropped from the history before they were served
+	Missed uint64  `json:"missed"`
+	Events []Event `json:"events"`
+}
+
+// eventCountKey identifies a counter of events
+type eventCountKey struct {
+	uid      nvmUID
+	etype    eventTypeEnumAttr
+	severity eventSeverityEnumAttr
+}
+
+// eventHistory keeps the events observed by the exporter, it is shared with
+// the events endpoint, so all the fields are guarded by the lock
+type eventHistory struct {
+	lock   sync.Mutex
+	seeded bool
+	lastID nvmUint32
+	cursor uint64
+	events []Event
+	counts map[eventCountKey]uint64
+}
+
+func newEventHistory() *eventHistory {
+	return &eventHistory{
+		events: make([]Event, 0),
+		counts: make(map[eventCountKey]uint64),
+	}
+}
+
+// newerEvents returns the events of the event log, which are newer than the
+// last one seen, sorted by their IDs, and the ID of the newest event in the
+// log. Event log which newest event is older than the last one seen is
+// considered purged, so all the events found in it are taken as new. Empty
+// event log (e.g. failed to be read by ipmctl) does not change the last one
+// seen.
+func newerEvents(events []event, lastID nvmUint32) ([]event, nvmUint32) {
+	if 0 == len(events) {
+		return []event{}, lastID
+	}
+	sorted := make([]event, len(events))
+	copy(sorted, events)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].eventID < sorted[j].eventID
+	})
+	newestID := sorted[len(sorted)-1].eventID
+	if newestID < lastID {
+		lastID = 0
+	}
+	fresh := make([]event, 0)
+	for _, e := range sorted {
+		if e.eventID > lastID {
+			fresh = append(fresh, e)
+		}
+	}
+	return fresh, newestID
+}
+
+// update takes the whole event log read in the current reading cycle and
+// returns the events, which were not seen before. The log found by the first
+// reading cycle seeds the history: its events were logged before the
+// exporter started, so they are kept for the events endpoint, but they are
+// neither counted nor returned.
+func (history *eventHistory) update(events []event) []event {
+	history.lock.Lock()
+	defer history.lock.Unlock()
+	fresh, newestID := newerEvents(events, history.lastID)
+	seeding := !history.seeded
+	history.seeded = true
+	for _, e := range fresh {
+		if !seeding {
+			history.counts[eventCountKey{e.uid, e.etype, e.severity}]++
+		}
+		history.cursor++
+		history.events = append(history.events, e.export(history.cursor))
+	}
+	if len(history.events) > eventHistoryCapacity {
+		history.events = append([]Event{}, history.events[len(history.events)-eventHistoryCapacity:]...)
+	}
+	history.lastID = newestID
+	if seeding {
+		return []event{}
+	}
+	return fresh
+}
+
+// export converts the event to its representation served by the events
+// endpoint
+func (e *event) export(cursor uint64) Event {
+	result := Event{
+		Cursor:   cursor,
+		EventID:  uint32(e.eventID),
+		Type:     getEventTypeName(e.etype),
+		Severity: getEventSeverityName(e.severity),
+		Code:     uint16(e.code),
+		UID:      string(e.uid),
+		Time:     time.Unix(int64(e.time), 0).UTC(),
+		Message:  string(e.message),
+		Args:     make([]string, 0, len(e.args)),
+	}
+	for _, arg := range e.args {
+		if arg != "" {
+			result.Args = append(result.Args, string(arg))
+		}
+	}
+	if e.isDiagnostic() {
+		result.DiagResult = getDiagnosticResultName(e.diagResult)
+	}
+	return result
+}
+
+// isDiagnostic indicates if the event was logged by a diagnostic test
+func (e *event) isDiagnostic() bool {
+	switch e.etype {
+	case eventTypeEnum.eventTypeDiag,
+		eventTypeEnum.eventTypeDiagQuick,
+		eventTypeEnum.eventTypeDiagPlatformConfig,
+		eventTypeEnum.eventTypeDiagSecurity,
+		eventTypeEnum.eventTypeDiagFWConsistency:
+		return true
+	}
+	return false
+}
+
+// GetEventsSince returns all the events kept in the history, which were
+// observed after given cursor. Cursor newer than the last event observed
+// (e.g. given by a client before the exporter was restarted) is reset.
+func (reader *MetricsReader) GetEventsSince(cursor uint64) EventBatch {
+	history := reader.events
+	history.lock.Lock()
+	defer history.lock.Unlock()
+	if cursor > history.cursor {
+		cursor = 0
+	}
+	batch := EventBatch{Cursor: history.cursor, Events: make([]Event, 0)}
+	if 0 != len(history.events) && history.events[0].Cursor > cursor+1 {
+		batch.Missed = history.events[0].Cursor - cursor - 1
+	}
+	for _, e := range history.events {
+		if e.Cursor > cursor {
+			batch.Events = append(batch.Events, e)
+		}
+	}
+	return batch
+}
+
+// Number of events observed by the exporter by device, type and severity
+func (reader *MetricsReader) GetEventCounts() []MetricReading {
+	history := reader.events
+	history.lock.Lock()
+	defer history.lock.Unlock()
+	results := make([]MetricReading, 0, len(history.counts))
+	for key, count := range history.counts {
+		eventReading := new(eventReading)
+		eventReading.DIMMUID = string(key.uid)
+		eventReading.ReadStatus = int(nvmStatusCodeEnum.nvmSuccess)
+		eventReading.MetricType = uint8(eventMetricTypeEnum.count)
+		eventReading.MetricValue = float64(count)
+		eventReading.Labels = eventLabels(*newMetricLabels())
+		eventReading.Labels.addLabel("uid", string(key.uid))
+		eventReading.Labels.addLabel("type", getEventTypeName(key.etype))
+		eventReading.Labels.addLabel("severity", getEventSeverityName(key.severity))
+		results = append(results, MetricReading(*eventReading))
+	}
+	return results
+}
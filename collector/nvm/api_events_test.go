@@ -0,0 +1,73 @@
+/**
+ * Copyright (c) 2020-2021, Intel Corporation.
+ * SPDX-License-Identifier: BSD-3-Clause
+ **
+ * api_events_test.go file tests how the event history follows the event log
+ * read in every reading cycle.
+ */
+
+package nvm
+
+import (
+	"testing"
+)
+
+func newTestEvents(ids ...nvmUint32) []event {
+	events := make([]event, len(ids))
+	for i, id := range ids {
+		events[i] = event{
+			eventID:  id,
+			etype:    eventTypeEnum.eventTypeHealth,
+			severity: eventSeverityEnum.eventSeverityWarn,
+			uid:      "8089-a2-1901-00001000",
+		}
+	}
+	return events
+}
+
+func countEvents(history *eventHistory) uint64 {
+	var total uint64
+	for _, count := range history.counts {
+		total += count
+	}
+	return total
+}
+
+func TestEventHistory(t *testing.T) {
+	history := newEventHistory()
+	steps := []struct {
+		name    string
+		log     []event
+		fresh   int
+		counted uint64
+	}{
+		// events logged before the exporter started are not counted
+		{"seed", newTestEvents(3, 1, 2), 0, 0},
+		{"new events", newTestEvents(1, 2, 3, 4, 5), 2, 2},
+		{"no new events", newTestEvents(1, 2, 3, 4, 5), 0, 2},
+		{"empty log", newTestEvents(), 0, 2},
+		{"log read again after empty one", newTestEvents(1, 2, 3, 4, 5), 0, 2},
+		{"log purged", newTestEvents(1, 2), 2, 4},
+	}
+	for _, step := range steps {
+		fresh := history.update(step.log)
+		if len(fresh) != step.fresh {
+			t.Errorf("%s: expected %d new events, got %d", step.name, step.fresh, len(fresh))
+		}
+		if counted := countEvents(history); counted != step.counted {
+			t.Errorf("%s: expected %d events counted, got %d", step.name, step.counted, counted)
+		}
+	}
+	// seeded events are served by the events endpoint anyway
+	if batch := (&MetricsReader{events: history}).GetEventsSince(0); len(batch.Events) != 7 || batch.Cursor != 7 {
+		t.Errorf("expected 7 events in the history, got %d (cursor %d)", len(batch.Events), batch.Cursor)
+	}
+}
+
+func TestEventHistoryEmptySeed(t *testing.T) {
+	history := newEventHistory()
+	history.update(newTestEvents())
+	if fresh := history.update(newTestEvents(1)); len(fresh) != 1 || countEvents(history) != 1 {
+		t.Errorf("expected event logged after empty log was seeded to be counted, got %d", len(fresh))
+	}
+}
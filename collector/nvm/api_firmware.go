@@ -0,0 +1,157 @@
+/**
+ * Copyright (c) 2020-2021, Intel Corporation.
+ * SPDX-License-Identifier: BSD-3-Clause
+ **
+ * This package introduces wrapper for ipmctl library written in C.
+ * api_firmware.go file exposes external API for exporter to collect
+ * firmware image information of NVM devices.
+ */
+
+package nvm
+
+var FirmwareLabelNames = []string{
+	"uid",
+}
+
+var FirmwareInfoLabelNames = []string{
+	"uid",
+	"active",
+	"staged",
+}
+
+var FirmwareStateLabelNames = []string{
+	"uid",
+	"state",
+}
+
+var firmwareTypeEnum = &firmwareType{
+	info:              0,
+	updateStatus:      1,
+	activationPending: 2,
+	unknown:           0xFF,
+}
+
+type firmwareReading MetricReading
+type firmwareLabels MetricLabels
+type firmwareInfoLabels MetricLabels
+type firmwareStateLabels MetricLabels
+type firmwareTypeEnumAttr enumAttr
+type firmwareType struct {
+	info              firmwareTypeEnumAttr
+	updateStatus      firmwareTypeEnumAttr
+	activationPending firmwareTypeEnumAttr
+	unknown           firmwareTypeEnumAttr
+}
+
+func (fl firmwareLabels) GetLabelValues() []string {
+	return getValuesByName(FirmwareLabelNames, MetricLabels(fl).labels)
+}
+
+func (fl firmwareLabels) GetLabelNames() []string {
+	return getNamesByLabels(FirmwareLabelNames, MetricLabels(fl).labels)
+}
+
+func (fl firmwareLabels) addLabel(name string, value string) {
+	MetricLabels(fl).labels[name] = value
+}
+
+func (fl firmwareInfoLabels) GetLabelValues() []string {
+	return getValuesByName(FirmwareInfoLabelNames, MetricLabels(fl).labels)
+}
+
+func (fl firmwareInfoLabels) GetLabelNames() []string {
+	return getNamesByLabels(FirmwareInfoLabelNames, MetricLabels(fl).labels)
+}
+
+func (fl firmwareInfoLabels) addLabel(name string, value string) {
+	MetricLabels(fl).labels[name] = value
+}
+
+func (fl firmwareStateLabels) GetLabelValues() []string {
+	return getValuesByName(FirmwareStateLabelNames, MetricLabels(fl).labels)
+}
+
+func (fl firmwareStateLabels) GetLabelNames() []string {
+	return getNamesByLabels(FirmwareStateLabelNames, MetricLabels(fl).labels)
+}
+
+func (fl firmwareStateLabels) addLabel(name string, value string) {
+	MetricLabels(fl).labels[name] = value
+}
+
+func newFirmwareReading(dev device,
+	metricType firmwareTypeEnumAttr,
+	metricValue nvmUint64,
+	labels Labels) *firmwareReading {
+	firmwareReading := new(firmwareReading)
+	firmwareReading.DIMMUID = string(dev.uid)
+	firmwareReading.ReadStatus = int(dev.fwInfoOpstat)
+	firmwareReading.MetricType = uint8(metricType)
+	firmwareReading.MetricValue = float64(metricValue)
+	firmwareReading.Labels = labels
+	firmwareReading.Labels.addLabel("uid", string(dev.uid))
+	dev.addLocationLabel(firmwareReading.Labels)
+	return firmwareReading
+}
+
+// isActivationPending reports firmware, which has been staged, but is not
+// active yet, as it requires the host to be rebooted
+func (fwInfo *deviceFWInfo) isActivationPending() bool {
+	if fwUpdateStatusEnum.fwUpdateStaged == fwInfo.fwUpdateStatus {
+		return true
+	}
+	return fwInfo.stagedFwRevision != "" && fwInfo.stagedFwRevision != fwInfo.activeFwRevision
+}
+
+// Active and staged firmware revisions of the DCPMM
+func (reader *MetricsReader) GetFirmwareInfo() []MetricReading {
+	results := make([]MetricReading, reader.deviceCount)
+	for i, dev := range reader.devices {
+		firmwareReading := *newFirmwareReading(dev, firmwareTypeEnum.info, 1,
+			firmwareInfoLabels(*newMetricLabels()))
+		firmwareReading.Labels.addLabel("active", string(dev.fwInfo.activeFwRevision))
+		firmwareReading.Labels.addLabel("staged", string(dev.fwInfo.stagedFwRevision))
+		results[i] = MetricReading(firmwareReading)
+	}
+	return results
+}
+
+// Status of the last firmware update operation of the DCPMM
+func (reader *MetricsReader) GetFirmwareUpdateStatus() []MetricReading {
+	states := []fwUpdateStatusEnumAttr{
+		fwUpdateStatusEnum.fwUpdateUnknown,
+		fwUpdateStatusEnum.fwUpdateStaged,
+		fwUpdateStatusEnum.fwUpdateSuccess,
+		fwUpdateStatusEnum.fwUpdateFailed,
+	}
+	results := make([]MetricReading, 0, int(reader.deviceCount)*len(states))
+	for _, dev := range reader.devices {
+		for _, state := range states {
+			metricValue := nvmUint64(0)
+			if state == dev.fwInfo.fwUpdateStatus {
+				metricValue = 1
+			}
+			firmwareReading := *newFirmwareReading(dev, firmwareTypeEnum.updateStatus, metricValue,
+				firmwareStateLabels(*newMetricLabels()))
+			firmwareReading.Labels.addLabel("state", getFwUpdateStatusName(state))
+			results = append(results, MetricReading(firmwareReading))
+		}
+	}
+	return results
+}
+
+// Indicates if the DCPMM has staged firmware, which requires reboot to be
+// activated
+func (reader *MetricsReader) GetFirmwareActivationPending() []MetricReading {
+	results := make([]MetricReading, reader.deviceCount)
+	for i, dev := range reader.devices {
+		metricValue := nvmUint64(0)
+		if dev.fwInfo.isActivationPending() {
+			metricValue = 1
+		}
+		firmwareReading := *newFirmwareReading(dev, firmwareTypeEnum.activationPending, metricValue,
+			firmwareLabels(*newMetricLabels()))
+		results[i] = MetricReading(firmwareReading)
+	}
+	return results
+}
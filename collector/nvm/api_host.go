@@ -0,0 +1,138 @@
+/**
+ * Copyright (c) 2020-2021, Intel Corporation.
+ * SPDX-License-Identifier: BSD-3-Clause
+ **
+ * This package introduces wrapper for ipmctl library written in C.
+ * api_host.go file exposes external API for exporter to collect
+ * information about the host server (operating system, DCPMM SKU issues)
+ * and versions of the software used to manage DCPMMs.
+ */
+
+package nvm
+
+var HostLabelNames = []string{
+	"name",
+	"os_type",
+	"os_name",
+	"os_version",
+}
+
+// host wide gauges (SKU issues) do not need any label
+var HostStateLabelNames = []string{}
+
+var SwInventoryLabelNames = []string{
+	"mgmt_sw_revision",
+	"vendor_driver_revision",
+	"vendor_driver_compatible",
+}
+
+var hostMetricTypeEnum = &hostMetricType{
+	info:         0,
+	mixedSKU:     1,
+	skuViolation: 2,
+	swInventory:  3,
+	unknown:      0xFF,
+}
+
+type hostReading MetricReading
+type hostLabels MetricLabels
+type hostStateLabels MetricLabels
+type swInventoryLabels MetricLabels
+type hostMetricTypeEnumAttr enumAttr
+type hostMetricType struct {
+	info         hostMetricTypeEnumAttr
+	mixedSKU     hostMetricTypeEnumAttr
+	skuViolation hostMetricTypeEnumAttr
+	swInventory  hostMetricTypeEnumAttr
+	unknown      hostMetricTypeEnumAttr
+}
+
+func (hl hostLabels) GetLabelValues() []string {
+	return getValuesByName(HostLabelNames, MetricLabels(hl).labels)
+}
+
+func (hl hostLabels) GetLabelNames() []string {
+	return HostLabelNames
+}
+
+func (hl hostLabels) addLabel(name string, value string) {
+	MetricLabels(hl).labels[name] = value
+}
+
+func (hl hostStateLabels) GetLabelValues() []string {
+	return getValuesByName(HostStateLabelNames, MetricLabels(hl).labels)
+}
+
+func (hl hostStateLabels) GetLabelNames() []string {
+	return HostStateLabelNames
+}
+
+func (hl hostStateLabels) addLabel(name string, value string) {
+	MetricLabels(hl).labels[name] = value
+}
+
+func (sl swInventoryLabels) GetLabelValues() []string {
+	return getValuesByName(SwInventoryLabelNames, MetricLabels(sl).labels)
+}
+
+func (sl swInventoryLabels) GetLabelNames() []string {
+	return SwInventoryLabelNames
+}
+
+func (sl swInventoryLabels) addLabel(name string, value string) {
+	MetricLabels(sl).labels[name] = value
+}
+
+func newHostReading(readStatus nvmStatusCodeEnumAttr,
+	metricType hostMetricTypeEnumAttr,
+	metricValue nvmUint64,
+	labels Labels) *hostReading {
+	hostReading := new(hostReading)
+	hostReading.ReadStatus = int(readStatus)
+	hostReading.MetricType = uint8(metricType)
+	hostReading.MetricValue = float64(metricValue)
+	hostReading.Labels = labels
+	return hostReading
+}
+
+// Describes the host server, its name and operating system
+func (reader *MetricsReader) GetHostInfo() []MetricReading {
+	platform := reader.platform
+	hostReading := *newHostReading(platform.hostOpstat, hostMetricTypeEnum.info, 1,
+		hostLabels(*newMetricLabels()))
+	hostReading.Labels.addLabel("name", platform.host.name)
+	hostReading.Labels.addLabel("os_type", getOsTypeName(platform.host.osType))
+	hostReading.Labels.addLabel("os_name", platform.host.osName)
+	hostReading.Labels.addLabel("os_version", platform.host.osVersion)
+	return []MetricReading{MetricReading(hostReading)}
+}
+
+// Indicates if DCPMMs of different SKUs are installed in the host
+func (reader *MetricsReader) GetMixedSKU() []MetricReading {
+	platform := reader.platform
+	hostReading := *newHostReading(platform.hostOpstat, hostMetricTypeEnum.mixedSKU,
+		platform.host.mixedSku.toNvmUint64(), hostStateLabels(*newMetricLabels()))
+	return []MetricReading{MetricReading(hostReading)}
+}
+
+// Indicates if configuration of DCPMMs is not supported due to a license
+// issue (SKU violation)
+func (reader *MetricsReader) GetSKUViolation() []MetricReading {
+	platform := reader.platform
+	hostReading := *newHostReading(platform.hostOpstat, hostMetricTypeEnum.skuViolation,
+		platform.host.skuViolation.toNvmUint64(), hostStateLabels(*newMetricLabels()))
+	return []MetricReading{MetricReading(hostReading)}
+}
+
+// Describes versions of the software used to manage DCPMMs (libipmctl and
+// vendor driver), and if the driver is compatible with it
+func (reader *MetricsReader) GetSwInventoryInfo() []MetricReading {
+	platform := reader.platform
+	hostReading := *newHostReading(platform.swInventoryOpstat, hostMetricTypeEnum.swInventory, 1,
+		swInventoryLabels(*newMetricLabels()))
+	inventory := platform.swInventory
+	hostReading.Labels.addLabel("mgmt_sw_revision", string(inventory.mgmtSwRevision))
+	hostReading.Labels.addLabel("vendor_driver_revision", string(inventory.vendorDriverRevision))
+	hostReading.Labels.addLabel("vendor_driver_compatible", inventory.vendorDriverCompatible.toString(10))
+	return []MetricReading{MetricReading(hostReading)}
+}
@@ -89,50 +89,137 @@ func GetConfigInt(paramName string, defaultVal int) (nvmStatusCodeEnumAttr, erro
 	return opstat, fmt.Errorf("Method is not implemented")
 }
 
-// GetHostName - stubbed - implement if needed
+// @brief Retrieve the name of the host server the native API library is
+// running on.
+// @return host name, operation status:
+// ::NVM_SUCCESS @n
+// ::NVM_ERR_INVALID_PARAMETER @n
+// ::NVM_ERR_UNKNOWN @n
 func GetHostName() (nvmStatusCodeEnumAttr, string, error) {
-	opstat := nvmStatusCodeEnum.nvmErrAPINotSupported
-	hostname := "localhost"
-	return opstat, hostname, fmt.Errorf("Method is not implemented")
+	cHostName := make([]C.char, C.NVM_COMPUTERNAME_LEN)
+	cOpstat := C.nvm_get_host_name(&cHostName[0], C.NVM_COMPUTERNAME_LEN)
+	opstat := nvmStatusCodeEnumAttr(cOpstat)
+	if C.NVM_SUCCESS != cOpstat {
+		return opstat, "", fmt.Errorf("Unable to get host name")
+	}
+	return opstat, C.GoString(&cHostName[0]), nil
 }
 
-// GetHost - stubbed - implement if needed
+// @brief Retrieve basic information about the host server the native API
+// library is running on.
+// @return #host structure, operation status:
+// ::NVM_SUCCESS @n
+// ::NVM_ERR_INVALID_PARAMETER @n
+// ::NVM_ERR_UNKNOWN @n
 func GetHost() (nvmStatusCodeEnumAttr, host, error) {
-	opstat := nvmStatusCodeEnum.nvmErrAPINotSupported
-	return opstat, host{}, fmt.Errorf("Method is not implemented")
+	cResult := C.struct_host{}
+	cOpstat := C.nvm_get_host(&cResult)
+	opstat := nvmStatusCodeEnumAttr(cOpstat)
+	if C.NVM_SUCCESS != cOpstat {
+		return opstat, host{}, fmt.Errorf("Unable to get host information")
+	}
+	return opstat, *newHost(cResult), nil
 }
 
-// GetSwInventory - stubbed - implement if needed
-func GetSwInventory() (nvmStatusCodeEnumAttr, host, error) {
-	opstat := nvmStatusCodeEnum.nvmErrAPINotSupported
-	return opstat, host{}, fmt.Errorf("Method is not implemented")
+// @brief Retrieve a list of installed software versions related to DCPMM
+// management.
+// @return #sw_inventory structure, operation status:
+// ::NVM_SUCCESS @n
+// ::NVM_ERR_INVALID_PARAMETER @n
+// ::NVM_ERR_UNKNOWN @n
+func GetSwInventory() (nvmStatusCodeEnumAttr, swInventory, error) {
+	cResult := C.struct_sw_inventory{}
+	cOpstat := C.nvm_get_sw_inventory(&cResult)
+	opstat := nvmStatusCodeEnumAttr(cOpstat)
+	if C.NVM_SUCCESS != cOpstat {
+		return opstat, swInventory{}, fmt.Errorf("Unable to get software inventory")
+	}
+	return opstat, *newSwInventory(cResult), nil
 }
 
-// GetNumberOfSockets - stubbed - implement if needed
+// @brief Retrieves the number of physical processors (NUMA nodes) in the
+// system.
+// @pre The OS must support its respective NUMA implementation.
+// @return number of sockets, operation status:
+// ::NVM_SUCCESS @n
+// ::NVM_ERR_INVALID_PARAMETER @n
+// ::NVM_ERR_UNKNOWN @n
 func GetNumberOfSockets() (nvmStatusCodeEnumAttr, int, error) {
-	opstat := nvmStatusCodeEnum.nvmErrAPINotSupported
-	count := int(0)
-	return opstat, count, fmt.Errorf("Method is not implemented")
+	cCount := C.int(0)
+	cOpstat := C.nvm_get_number_of_sockets(&cCount)
+	opstat := nvmStatusCodeEnumAttr(cOpstat)
+	count := int(cCount)
+	if C.NVM_SUCCESS != cOpstat {
+		return opstat, count, fmt.Errorf("Unable to get number of sockets")
+	}
+	return opstat, count, nil
 }
 
-// GetSockets - stubbed - implement if needed
-func GetSockets() (nvmStatusCodeEnumAttr, socket, nvmUint16, error) {
-	opstat := nvmStatusCodeEnum.nvmErrAPINotSupported
-	count := nvmUint16(0)
-	return opstat, socket{}, count, fmt.Errorf("Method is not implemented")
+// @brief Retrieves #socket information about each processor socket in the
+// system.
+// @param[in] count: The number of elements in array.
+// @pre The OS must support its respective NUMA implementation.
+// @remarks To allocate the array of #socket structures,
+// call #nvm_get_number_of_sockets before calling this method.
+// @return array of #socket structures, operation status:
+// ::NVM_SUCCESS @n
+// ::NVM_ERR_INVALID_PARAMETER @n
+// ::NVM_ERR_UNKNOWN @n
+func GetSockets(count nvmUint16) (nvmStatusCodeEnumAttr, []socket, error) {
+	if 0 == count {
+		return nvmStatusCodeEnum.nvmSuccess, []socket{}, nil
+	}
+	cSockets := make([]C.struct_socket, count)
+	cOpstat := C.nvm_get_sockets(&cSockets[0], C.NVM_UINT16(count))
+	opstat := nvmStatusCodeEnumAttr(cOpstat)
+	if C.NVM_SUCCESS != cOpstat {
+		return opstat, []socket{}, fmt.Errorf("Unable to get sockets")
+	}
+	return opstat, newSockets(cSockets), nil
 }
 
-// GetNumberOfMemoryTopologyDevices - stubbed - implement if needed
+// @brief Retrieve the number of memory devices installed in the system.
+// This count includes both DCPMMs and other memory devices, such as DRAM.
+// @pre The caller must have administrative privileges.
+// @return number of memory devices, operation status:
+// ::NVM_SUCCESS @n
+// ::NVM_ERR_INVALID_PARAMETER @n
+// ::NVM_ERR_UNKNOWN @n
 func GetNumberOfMemoryTopologyDevices() (nvmStatusCodeEnumAttr, uint, error) {
-	opstat := nvmStatusCodeEnum.nvmErrAPINotSupported
-	count := uint(0)
-	return opstat, count, fmt.Errorf("Method is not implemented")
+	cCount := C.uint(0)
+	cOpstat := C.nvm_get_number_of_memory_topology_devices(&cCount)
+	opstat := nvmStatusCodeEnumAttr(cOpstat)
+	count := uint(cCount)
+	if C.NVM_SUCCESS != cOpstat {
+		return opstat, count, fmt.Errorf("Unable to get number of memory devices")
+	}
+	return opstat, count, nil
 }
 
-// GetMemoryTopology - stubbed - implement if needed
-func GetMemoryTopology(count uint8) (nvmStatusCodeEnumAttr, memoryTopology, error) {
-	opstat := nvmStatusCodeEnum.nvmErrAPINotSupported
-	return opstat, memoryTopology{}, fmt.Errorf("Method is not implemented")
+// @brief Retrieves basic topology information about all memory devices
+// installed in the system, including both DCPMMs and other memory devices,
+// such as DRAM.
+// @param[in] count: The number of elements in array.
+// @pre The caller must have administrative privileges.
+// @remarks To allocate the array of #memory_topology structures,
+// call #nvm_get_number_of_memory_topology_devices before calling this
+// method.
+// @return array of #memory_topology structures, operation status:
+// ::NVM_SUCCESS @n
+// ::NVM_ERR_INVALID_PARAMETER @n
+// ::NVM_ERR_BAD_SIZE @n
+// ::NVM_ERR_UNKNOWN @n
+func GetMemoryTopology(count nvmUint8) (nvmStatusCodeEnumAttr, []memoryTopology, error) {
+	if 0 == count {
+		return nvmStatusCodeEnum.nvmSuccess, []memoryTopology{}, nil
+	}
+	cDevices := make([]C.struct_memory_topology, count)
+	cOpstat := C.nvm_get_memory_topology(&cDevices[0], C.NVM_UINT8(count))
+	opstat := nvmStatusCodeEnumAttr(cOpstat)
+	if C.NVM_SUCCESS != cOpstat {
+		return opstat, []memoryTopology{}, fmt.Errorf("Unable to get memory topology")
+	}
+	return opstat, newMemoryTopologies(cDevices), nil
 }
 
 // @brief Retrieves #device_discovery information
@@ -172,41 +259,127 @@ func GetDevicesNfit(count nvmUint8) (nvmStatusCodeEnumAttr, deviceDiscovery, err
 	return opstat, deviceDiscovery{}, fmt.Errorf("Method is not implemented")
 }
 
-// GetDeviceDiscovery - stubbed - implement if needed
+// @brief Retrieves #device_discovery information about the device specified
+// @param[in] deviceUID: The device identifier.
+// @pre The caller must have administrative privileges.
+// @return #DeviceDiscovery structure, operation status:
+// ::NVM_SUCCESS @n
+// ::NVM_ERR_INVALID_PARAMETER @n
+// ::NVM_ERR_DIMM_NOT_FOUND @n
+// ::NVM_ERR_UNKNOWN @n
 func GetDeviceDiscovery(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceDiscovery, error) {
-	opstat := nvmStatusCodeEnum.nvmErrAPINotSupported
-	return opstat, deviceDiscovery{}, fmt.Errorf("Method is not implemented")
+	cResult := C.struct_device_discovery{}
+	cDeviceUID := deviceUID.toCharArray()
+	cOpstat := C.nvm_get_device_discovery(&cDeviceUID[0], &cResult)
+	opstat := nvmStatusCodeEnumAttr(cOpstat)
+	if C.NVM_SUCCESS != cOpstat {
+		return opstat, deviceDiscovery{},
+			fmt.Errorf("Unable to get discovery information of DIMM: %s", deviceUID)
+	}
+	return opstat, *newDeviceDiscovery(cResult), nil
 }
 
-// GetDeviceStatus - stubbed - implement if needed
+// @brief Retrieves current status of the device specified
+// @param[in] deviceUID: The device identifier.
+// @pre The caller must have administrative privileges.
+// @return #DeviceStatus structure, operation status:
+// ::NVM_SUCCESS @n
+// ::NVM_ERR_INVALID_PARAMETER @n
+// ::NVM_ERR_DIMM_NOT_FOUND @n
+// ::NVM_ERR_UNKNOWN @n
 func GetDeviceStatus(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceStatus, error) {
-	opstat := nvmStatusCodeEnum.nvmErrAPINotSupported
-	return opstat, deviceStatus{}, fmt.Errorf("Method is not implemented")
+	cResult := C.struct_device_status{}
+	cDeviceUID := deviceUID.toCharArray()
+	cOpstat := C.nvm_get_device_status(&cDeviceUID[0], &cResult)
+	opstat := nvmStatusCodeEnumAttr(cOpstat)
+	if C.NVM_SUCCESS != cOpstat {
+		return opstat, deviceStatus{},
+			fmt.Errorf("Unable to get status of DIMM: %s", deviceUID)
+	}
+	return opstat, *newDeviceStatus(cResult), nil
 }
 
-// GetPMOMRegister - stubbed - implement if needed
+// @brief Retrieve the PMON Registers of device specified.
+// @param[in] deviceUID
+// 		The device identifier.
+// @param[in] smartDataMask
+// 		This will specify whether or not to return the extra smart data
+// 		along with the PMON Counter data (0x3 - DDRT & Media Data).
+// @return #pmonRegisters structure, operation status:
+// ::NVM_SUCCESS @n
+// ::NVM_ERR_INVALID_PARAMETER @n
+// ::NVM_ERR_DIMM_NOT_FOUND @n
 func GetPMOMRegister(deviceUID nvmUID,
 	smartDataMask uint8) (nvmStatusCodeEnumAttr, pmonRegisters, error) {
-	opstat := nvmStatusCodeEnum.nvmErrAPINotSupported
-	return opstat, pmonRegisters{}, fmt.Errorf("Method is not implemented")
+	cResult := C.PMON_REGISTERS{}
+	cDeviceUID := deviceUID.toCharArray()
+	cOpstat := C.nvm_get_pmon_registers(&cDeviceUID[0], C.NVM_UINT8(smartDataMask), &cResult)
+	opstat := nvmStatusCodeEnumAttr(cOpstat)
+	if C.NVM_SUCCESS != cOpstat {
+		return opstat, pmonRegisters{},
+			fmt.Errorf("Unable to get PMON registers of DIMM: %s", deviceUID)
+	}
+	return opstat, *newPMONRegisters(cResult), nil
 }
 
-// SetPMONRegisters - stubbed - implement if needed
+// @brief Set the PMON Registers of device specified.
+// @param[in] deviceUID
+// 		The device identifier.
+// @param[in] pmonGroupEnable
+// 		Specifies which PMON Group to enable.
+// @return operation status:
+// ::NVM_SUCCESS @n
+// ::NVM_ERR_INVALID_PARAMETER @n
+// ::NVM_ERR_DIMM_NOT_FOUND @n
 func SetPMONRegisters(deviceUID nvmUID, pmonGroupEnable uint8) (nvmStatusCodeEnumAttr, error) {
-	opstat := nvmStatusCodeEnum.nvmErrAPINotSupported
-	return opstat, fmt.Errorf("Method is not implemented")
+	cDeviceUID := deviceUID.toCharArray()
+	cOpstat := C.nvm_set_pmon_registers(&cDeviceUID[0], C.NVM_UINT8(pmonGroupEnable))
+	opstat := nvmStatusCodeEnumAttr(cOpstat)
+	if C.NVM_SUCCESS != cOpstat {
+		return opstat, fmt.Errorf("Unable to set PMON registers of DIMM: %s", deviceUID)
+	}
+	return opstat, nil
 }
 
-// GetDeviceSettings - stubbed - implement if needed
+// @brief Retrieves settings (viral policy) of the device specified
+// @param[in] deviceUID: The device identifier.
+// @pre The caller must have administrative privileges.
+// @pre The device is manageable.
+// @return #DeviceSettings structure, operation status:
+// ::NVM_SUCCESS @n
+// ::NVM_ERR_INVALID_PARAMETER @n
+// ::NVM_ERR_UNKNOWN @n
 func GetDeviceSettings(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceSettings, error) {
-	opstat := nvmStatusCodeEnum.nvmErrAPINotSupported
-	return opstat, deviceSettings{}, fmt.Errorf("Method is not implemented")
+	cResult := C.struct_device_settings{}
+	cDeviceUID := deviceUID.toCharArray()
+	cOpstat := C.nvm_get_device_settings(&cDeviceUID[0], &cResult)
+	opstat := nvmStatusCodeEnumAttr(cOpstat)
+	if C.NVM_SUCCESS != cOpstat {
+		return opstat, deviceSettings{},
+			fmt.Errorf("Unable to get settings of DIMM: %s", deviceUID)
+	}
+	return opstat, *newDeviceSettings(cResult), nil
 }
 
-// GetDeviceDetails - stubbed - implement if needed
+// @brief Retrieves detailed information about the device specified
+// @param[in] deviceUID: The device identifier.
+// @pre The caller must have administrative privileges.
+// @pre The device is manageable.
+// @return #DeviceDetails structure, operation status:
+// ::NVM_SUCCESS @n
+// ::NVM_ERR_INVALID_PARAMETER @n
+// ::NVM_ERR_DIMM_NOT_FOUND @n
+// ::NVM_ERR_UNKNOWN @n
 func GetDeviceDetails(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceDetails, error) {
-	opstat := nvmStatusCodeEnum.nvmErrAPINotSupported
-	return opstat, deviceDetails{}, fmt.Errorf("Method is not implemented")
+	cResult := C.struct_device_details{}
+	cDeviceUID := deviceUID.toCharArray()
+	cOpstat := C.nvm_get_device_details(&cDeviceUID[0], &cResult)
+	opstat := nvmStatusCodeEnumAttr(cOpstat)
+	if C.NVM_SUCCESS != cOpstat {
+		return opstat, deviceDetails{},
+			fmt.Errorf("Unable to get details of DIMM: %s", deviceUID)
+	}
+	return opstat, *newDeviceDetails(cResult), nil
 }
 
 // @brief Retrieves a current snapshot of the performance metrics
@@ -234,10 +407,25 @@ func GetDevicePerformance(deviceUID nvmUID) (nvmStatusCodeEnumAttr,
 	return opstat, result, nil
 }
 
-// GetDeviceFwImageInfo - stubbed - implement if needed
+// @brief Retrieves the firmware image information of the device specified
+// @param[in] deviceUID: The device identifier.
+// @pre The caller must have administrative privileges.
+// @pre The device is manageable.
+// @return #DeviceFWInfo structure, operation status:
+// ::NVM_SUCCESS @n
+// ::NVM_ERR_INVALID_PARAMETER @n
+// ::NVM_ERR_DIMM_NOT_FOUND @n
+// ::NVM_ERR_UNKNOWN @n
 func GetDeviceFwImageInfo(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceFWInfo, error) {
-	opstat := nvmStatusCodeEnum.nvmErrAPINotSupported
-	return opstat, deviceFWInfo{}, fmt.Errorf("Method is not implemented")
+	cResult := C.struct_device_fw_info{}
+	cDeviceUID := deviceUID.toCharArray()
+	cOpstat := C.nvm_get_device_fw_image_info(&cDeviceUID[0], &cResult)
+	opstat := nvmStatusCodeEnumAttr(cOpstat)
+	if C.NVM_SUCCESS != cOpstat {
+		return opstat, deviceFWInfo{},
+			fmt.Errorf("Unable to get firmware image information of DIMM: %s", deviceUID)
+	}
+	return opstat, *newDeviceFWInfo(cResult), nil
 }
 
 // UpdateDeviceFw - stubbed - implement if needed
@@ -259,16 +447,38 @@ func ExamineDeviceFw(deviceUID nvmUID,
 	return opstat, fmt.Errorf("Method is not implemented")
 }
 
-// GetNVMCapabilities - stubbed - implement if needed
+// @brief Retrieves supported features and capabilities of the host
+// software, the platform (BIOS) and the DCPMMs installed in the system.
+// @return #nvm_capabilities structure, operation status:
+// ::NVM_SUCCESS @n
+// ::NVM_ERR_INVALID_PARAMETER @n
+// ::NVM_ERR_UNKNOWN @n
 func GetNVMCapabilities() (nvmStatusCodeEnumAttr, nvmCapabilities, error) {
-	opstat := nvmStatusCodeEnum.nvmErrAPINotSupported
-	return opstat, nvmCapabilities{}, fmt.Errorf("Method is not implemented")
+	cResult := C.struct_nvm_capabilities{}
+	cOpstat := C.nvm_get_nvm_capabilities(&cResult)
+	opstat := nvmStatusCodeEnumAttr(cOpstat)
+	if C.NVM_SUCCESS != cOpstat {
+		return opstat, nvmCapabilities{}, fmt.Errorf("Unable to get NVM capabilities")
+	}
+	return opstat, *newNVMCapabilities(cResult), nil
 }
 
-// GetNVMCapacities - stubbed - implement if needed
+// @brief Retrieves the aggregate capacities across all manageable DCPMMs
+// in the system.
+// @pre The caller must have administrative privileges.
+// @return #DeviceCapacities structure, operation status:
+// ::NVM_SUCCESS @n
+// ::NVM_ERR_INVALID_PARAMETER @n
+// ::NVM_ERR_UNKNOWN @n
 func GetNVMCapacities() (nvmStatusCodeEnumAttr, deviceCapacities, error) {
-	opstat := nvmStatusCodeEnum.nvmErrAPINotSupported
-	return opstat, deviceCapacities{}, fmt.Errorf("Method is not implemented")
+	cResult := C.struct_device_capacities{}
+	cOpstat := C.nvm_get_nvm_capacities(&cResult)
+	opstat := nvmStatusCodeEnumAttr(cOpstat)
+	if C.NVM_SUCCESS != cOpstat {
+		return opstat, deviceCapacities{},
+			fmt.Errorf("Unable to get capacities of DIMMs")
+	}
+	return opstat, *newDeviceCapacities(cResult), nil
 }
 
 // GetSensors - stubbed - implement if needed
@@ -296,7 +506,7 @@ func GetSensor(deviceUID nvmUID,
 	if C.NVM_SUCCESS != cOpstat {
 		opstat := nvmStatusCodeEnumAttr(cOpstat)
 		return opstat, sensor{},
-			fmt.Errorf("Unable to get readings from sensor number: %d, DIMM: %s", stype, cDeviceUID)
+			fmt.Errorf("Unable to get readings from sensor number: %d, DIMM: %s", stype, deviceUID)
 	}
 	result := *newSensor(cResult)
 	opstat := nvmStatusCodeEnumAttr(cOpstat)
@@ -361,57 +571,179 @@ func SetMasterPassphrase(deviceUID nvmUID,
 	return opstat, fmt.Errorf("Method is not implemented")
 }
 
-// GetNumberOfEvents - stubbed - implement if needed
+// @brief Retrieve the number of events in the native API library event
+// database.
+// @param[in] filter: event_filter structure to optionally filter the event
+// count.
+// @pre The caller must have administrative privileges.
+// @return number of events, operation status:
+// ::NVM_SUCCESS @n
+// ::NVM_ERR_INVALID_PARAMETER @n
+// ::NVM_ERR_OPERATION_NOT_SUPPORTED @n
+// ::NVM_ERR_API_NOT_SUPPORTED @n
+// ::NVM_ERR_UNKNOWN @n
 func GetNumberOfEvents(filter eventFilter) (nvmStatusCodeEnumAttr, int, error) {
-	opstat := nvmStatusCodeEnum.nvmErrAPINotSupported
-	count := int(0)
-	return opstat, count, fmt.Errorf("Method is not implemented")
+	cFilter := newCEventFilter(filter)
+	cCount := C.int(0)
+	cOpstat := C.nvm_get_number_of_events(&cFilter, &cCount)
+	opstat := nvmStatusCodeEnumAttr(cOpstat)
+	count := int(cCount)
+	if C.NVM_SUCCESS != cOpstat {
+		return opstat, count, fmt.Errorf("Unable to get number of events")
+	}
+	return opstat, count, nil
 }
 
-// GetEvents - stubbed - implement if needed
+// @brief Retrieve a list of stored events from the native API library
+// database and optionally filter the results.
+// @param[in] filter: event_filter structure to optionally limit the results.
+// @param[in] count: The number of events to retrieve, call GetNumberOfEvents
+// to get it.
+// @pre The caller must have administrative privileges.
+// @remarks The native API library stores a maximum of 10,000 events in the
+// table, rolling the table once the maximum is reached.
+// @return list of #event structures, operation status:
+// ::NVM_SUCCESS @n
+// ::NVM_ERR_INVALID_PARAMETER @n
+// ::NVM_ERR_OPERATION_NOT_SUPPORTED @n
+// ::NVM_ERR_API_NOT_SUPPORTED @n
+// ::NVM_ERR_UNKNOWN @n
 func GetEvents(filter eventFilter, count nvmUint16) (nvmStatusCodeEnumAttr, []event, error) {
-	opstat := nvmStatusCodeEnum.nvmErrAPINotSupported
-	return opstat, []event{}, fmt.Errorf("Method is not implemented")
+	if 0 == count {
+		return nvmStatusCodeEnum.nvmSuccess, []event{}, nil
+	}
+	cFilter := newCEventFilter(filter)
+	cEvents := make([]C.struct_event, count)
+	cOpstat := C.nvm_get_events(&cFilter, &cEvents[0], C.NVM_UINT16(count))
+	opstat := nvmStatusCodeEnumAttr(cOpstat)
+	if C.NVM_SUCCESS != cOpstat {
+		return opstat, []event{}, fmt.Errorf("Unable to get events")
+	}
+	return opstat, newEvents(cEvents), nil
 }
 
-// PurgeEvents - stubbed - implement if needed
+// @brief Purge stored events from the native API library database.
+// @param[in] filter: event_filter structure to optionally purge only
+// specific events.
+// @pre The caller must have administrative privileges.
+// @return operation status:
+// ::NVM_SUCCESS @n
+// ::NVM_ERR_INVALID_PARAMETER @n
+// ::NVM_ERR_OPERATION_NOT_SUPPORTED @n
+// ::NVM_ERR_API_NOT_SUPPORTED @n
+// ::NVM_ERR_UNKNOWN @n
 func PurgeEvents(filter eventFilter) (nvmStatusCodeEnumAttr, error) {
-	opstat := nvmStatusCodeEnum.nvmErrAPINotSupported
-	return opstat, fmt.Errorf("Method is not implemented")
+	cFilter := newCEventFilter(filter)
+	cOpstat := C.nvm_purge_events(&cFilter)
+	opstat := nvmStatusCodeEnumAttr(cOpstat)
+	if C.NVM_SUCCESS != cOpstat {
+		return opstat, fmt.Errorf("Unable to purge events")
+	}
+	return opstat, nil
 }
 
-// AcknowledgeEvent - stubbed - implement if needed
+// @brief Acknowledge an event from the native API library database.
+// @param[in] eventID: Event ID of the event to be acknowledged.
+// @pre The caller must have administrative privileges.
+// @return operation status:
+// ::NVM_SUCCESS @n
+// ::NVM_ERR_INVALID_PARAMETER @n
+// ::NVM_ERR_OPERATION_NOT_SUPPORTED @n
+// ::NVM_ERR_API_NOT_SUPPORTED @n
+// ::NVM_ERR_UNKNOWN @n
 func AcknowledgeEvent(eventID nvmUint32) (nvmStatusCodeEnumAttr, error) {
-	opstat := nvmStatusCodeEnum.nvmErrAPINotSupported
-	return opstat, fmt.Errorf("Method is not implemented")
+	cOpstat := C.nvm_acknowledge_event(C.NVM_UINT32(eventID))
+	opstat := nvmStatusCodeEnumAttr(cOpstat)
+	if C.NVM_SUCCESS != cOpstat {
+		return opstat, fmt.Errorf("Unable to acknowledge event %d", eventID)
+	}
+	return opstat, nil
 }
 
-// GetNumberOfRegions - stubbed - implement if needed
+// @brief Retrieves the number of configured persistent memory regions in
+// the host server.
+// @pre The caller must have administrative privileges.
+// @return number of regions, operation status:
+// ::NVM_SUCCESS @n
+// ::NVM_ERR_INVALID_PARAMETER @n
+// ::NVM_ERR_UNKNOWN @n
 func GetNumberOfRegions() (nvmStatusCodeEnumAttr, nvmUint8, error) {
-	opstat := nvmStatusCodeEnum.nvmErrAPINotSupported
-	count := nvmUint8(0)
-	return opstat, count, fmt.Errorf("Method is not implemented")
+	cCount := C.NVM_UINT8(0)
+	cOpstat := C.nvm_get_number_of_regions(&cCount)
+	opstat := nvmStatusCodeEnumAttr(cOpstat)
+	count := nvmUint8(cCount)
+	if C.NVM_SUCCESS != cOpstat {
+		return opstat, count, fmt.Errorf("Unable to get number of regions")
+	}
+	return opstat, count, nil
 }
 
-// GetNumberOfRegionsEx - - stubbed - implement if needed
+// @brief Retrieves the number of configured persistent memory regions in
+// the host server.
+// @param[in] useNfit: Use NFIT table instead of PCD data to get region
+// information.
+// @pre The caller must have administrative privileges.
+// @return number of regions, operation status:
+// ::NVM_SUCCESS @n
+// ::NVM_ERR_INVALID_PARAMETER @n
+// ::NVM_ERR_UNKNOWN @n
 func GetNumberOfRegionsEx(useNfit nvmBool) (nvmStatusCodeEnumAttr, nvmUint8, error) {
-	opstat := nvmStatusCodeEnum.nvmErrAPINotSupported
-	count := nvmUint8(0)
-	return opstat, count, fmt.Errorf("Method is not implemented")
+	cCount := C.NVM_UINT8(0)
+	cOpstat := C.nvm_get_number_of_regions_ex(C.NVM_BOOL(useNfit.toNvmUint64()), &cCount)
+	opstat := nvmStatusCodeEnumAttr(cOpstat)
+	count := nvmUint8(cCount)
+	if C.NVM_SUCCESS != cOpstat {
+		return opstat, count, fmt.Errorf("Unable to get number of regions")
+	}
+	return opstat, count, nil
 }
 
-// GetRegions - stubbed - implement if needed
+// @brief Retrieves a list of the configured persistent memory regions in
+// the host server.
+// @pre The caller must have administrative privileges.
+// @return list of #Region structures, number of regions, operation status:
+// ::NVM_SUCCESS @n
+// ::NVM_ERR_INVALID_PARAMETER @n
+// ::NVM_ERR_UNKNOWN @n
+// ::NVM_ERR_NO_MEM @n
 func GetRegions() (nvmStatusCodeEnumAttr, []region, nvmUint8, error) {
-	opstat := nvmStatusCodeEnum.nvmErrAPINotSupported
-	count := nvmUint8(0)
-	return opstat, []region{}, count, fmt.Errorf("Method is not implemented")
+	opstat, count, err := GetNumberOfRegions()
+	if nvmStatusCodeEnum.nvmSuccess != opstat || 0 == count {
+		return opstat, []region{}, count, err
+	}
+	cRegions := make([]C.struct_region, count)
+	cCount := C.NVM_UINT8(count)
+	cOpstat := C.nvm_get_regions(&cRegions[0], &cCount)
+	opstat = nvmStatusCodeEnumAttr(cOpstat)
+	if C.NVM_SUCCESS != cOpstat {
+		return opstat, []region{}, 0, fmt.Errorf("Unable to get regions")
+	}
+	return opstat, newRegions(cRegions[:cCount]), nvmUint8(cCount), nil
 }
 
-// GetRegionsEx - stubbed - implement if needed
+// @brief Retrieves a list of the configured persistent memory regions in
+// the host server.
+// @param[in] useNfit: Use NFIT table instead of PCD data to get region
+// information.
+// @pre The caller must have administrative privileges.
+// @return list of #Region structures, number of regions, operation status:
+// ::NVM_SUCCESS @n
+// ::NVM_ERR_INVALID_PARAMETER @n
+// ::NVM_ERR_UNKNOWN @n
+// ::NVM_ERR_NO_MEM @n
 func GetRegionsEx(useNfit nvmBool) (nvmStatusCodeEnumAttr, []region, nvmUint8, error) {
-	opstat := nvmStatusCodeEnum.nvmErrAPINotSupported
-	count := nvmUint8(0)
-	return opstat, []region{}, count, fmt.Errorf("Method is not implemented")
+	opstat, count, err := GetNumberOfRegionsEx(useNfit)
+	if nvmStatusCodeEnum.nvmSuccess != opstat || 0 == count {
+		return opstat, []region{}, count, err
+	}
+	cRegions := make([]C.struct_region, count)
+	cCount := C.NVM_UINT8(count)
+	cOpstat := C.nvm_get_regions_ex(C.NVM_BOOL(useNfit.toNvmUint64()), &cRegions[0], &cCount)
+	opstat = nvmStatusCodeEnumAttr(cOpstat)
+	if C.NVM_SUCCESS != cOpstat {
+		return opstat, []region{}, 0, fmt.Errorf("Unable to get regions")
+	}
+	return opstat, newRegions(cRegions[:cCount]), nvmUint8(cCount), nil
 }
 
 // CreateConfigGoal - stubbed - implement if needed
@@ -447,35 +779,50 @@ func LoadGoalConfig(file nvmPath, fileLen nvmSize) (nvmStatusCodeEnumAttr, error
 	return opstat, fmt.Errorf("Method is not implemented")
 }
 
-// GetMajorVersion - stubbed - implement if needed
+// @brief Retrieve the native API library major version number.
+// @return the native API library major version number
 func GetMajorVersion() int {
-	majorVersion := int(0)
-	return majorVersion
+	return int(C.nvm_get_major_version())
 }
 
-// GetMinorVersion - stubbed - implement if needed
+// @brief Retrieve the native API library minor version number.
+// @return the native API library minor version number
 func GetMinorVersion() int {
-	minorVersion := int(0)
-	return minorVersion
+	return int(C.nvm_get_minor_version())
 }
 
-// GetHotfixNumber - stubbed - implement if needed
+// @brief Retrieve the native API library hot fix version number.
+// @return the native API library hot fix version number
 func GetHotfixNumber() int {
-	hotfixVersionNumber := int(0)
-	return hotfixVersionNumber
+	return int(C.nvm_get_hotfix_number())
 }
 
-// GetBuildNumber - stubbed - implement if needed
+// @brief Retrieve the native API library build version number.
+// @return the native API library build version number
 func GetBuildNumber() int {
-	buildVersionNumber := int(0)
-	return buildVersionNumber
+	return int(C.nvm_get_build_number())
 }
 
-// GetVersion - stubbed - implement if needed
+// @brief Retrieve native API library version as a string in the format
+// MM.mm.hh.bbbb, where MM is the major version, mm is the minor version, hh
+// is the hotfix number and bbbb is the build number.
+// @param[in] strLen
+// 		Size of the version string buffer, should be NVM_VERSION_LEN.
+// @return version string, operation status:
+// ::NVM_SUCCESS @n
+// ::NVM_ERR_INVALID_PARAMETER @n
+// ::NVM_ERR_UNKNOWN @n
 func GetVersion(strLen nvmSize) (nvmStatusCodeEnumAttr, nvmVersion, error) {
-	opstat := nvmStatusCodeEnum.nvmErrAPINotSupported
-	versionStr := nvmVersion("")
-	return opstat, versionStr, fmt.Errorf("Method is not implemented")
+	if 0 == strLen {
+		return nvmStatusCodeEnum.nvmErrInvalidParameter, "", fmt.Errorf("Version string length has to be positive")
+	}
+	cVersion := make([]C.char, strLen)
+	cOpstat := C.nvm_get_version(&cVersion[0], C.NVM_SIZE(strLen))
+	opstat := nvmStatusCodeEnumAttr(cOpstat)
+	if C.NVM_SUCCESS != cOpstat {
+		return opstat, "", fmt.Errorf("Unable to get native API library version")
+	}
+	return opstat, nvmVersion(C.GoString(&cVersion[0])), nil
 }
 
 // GatherSupport - stubbed - implement if needed
@@ -554,19 +901,66 @@ func SendDevicePassthroughCmd(deviceUID nvmUID, cmd devicePTCmd) (nvmStatusCodeE
 	return opstat, fmt.Errorf("Method is not implemented")
 }
 
-// GetFwErrorLogEntryCmd - stubbed - implement if needed
+// @brief Retrieve a FW error log entry
+// @param[in] deviceUID: The device identifier.
+// @param[in] seqNum: Log entry sequence number.
+// @param[in] logLevel: Log entry log level (0: Low, 1: High).
+// @param[in] logType: Log entry log type (0: Media, 1: Thermal).
+// @return #ERROR_LOG structure, operation status:
+// ::NVM_SUCCESS @n
+// ::NVM_SUCCESS_NO_ERROR_LOG_ENTRY @n
+// ::NVM_ERR_INVALID_PARAMETER @n
+// ::NVM_ERR_INVALID_PERMISSIONS @n
+// ::NVM_ERR_OPERATION_NOT_SUPPORTED @n
+// ::NVM_ERR_NO_MEM @n
+// ::NVM_ERR_UNKNOWN @n
+// ::NVM_ERR_BAD_DEVICE @n
+// ::NVM_ERR_DRIVER_FAILED @n
+// ::NVM_ERR_GENERAL_DEV_FAILURE @n
+// ::NVM_ERR_BUSY_DEVICE @n
 func GetFwErrorLogEntryCmd(deviceUID nvmUID,
 	seqNum uint,
 	logLevel uint8,
 	logType uint8) (nvmStatusCodeEnumAttr, errorLog, error) {
-	opstat := nvmStatusCodeEnum.nvmErrAPINotSupported
-	return opstat, errorLog{}, fmt.Errorf("method is not implemented")
+	cResult := C.ERROR_LOG{}
+	cDeviceUID := deviceUID.toCharArray()
+	cOpstat := C.nvm_get_fw_error_log_entry_cmd(&cDeviceUID[0], C.ushort(seqNum),
+		C.uchar(logLevel), C.uchar(logType), &cResult)
+	opstat := nvmStatusCodeEnumAttr(cOpstat)
+	if nvmStatusCodeEnum.nvmSuccessNoErrorLogEntry == opstat {
+		return opstat, errorLog{}, nil
+	}
+	if C.NVM_SUCCESS != cOpstat {
+		return opstat, errorLog{},
+			fmt.Errorf("Unable to get firmware error log entry %d of DIMM: %s", seqNum, deviceUID)
+	}
+	return opstat, *newErrorLog(cResult), nil
 }
 
-// GetFwErrLogStats - stubbed - implement if needed
+// @brief Retrieve a FW error log counters: current and oldest sequence
+// number for each log type.
+// @param[in] deviceUID: The device identifier.
+// @return #device_error_log_status structure, operation status:
+// ::NVM_SUCCESS @n
+// ::NVM_ERR_INVALID_PARAMETER @n
+// ::NVM_ERR_INVALID_PERMISSIONS @n
+// ::NVM_ERR_OPERATION_NOT_SUPPORTED @n
+// ::NVM_ERR_NO_MEM @n
+// ::NVM_ERR_UNKNOWN @n
+// ::NVM_ERR_BAD_DEVICE @n
+// ::NVM_ERR_DRIVER_FAILED @n
+// ::NVM_ERR_GENERAL_DEV_FAILURE @n
+// ::NVM_ERR_BUSY_DEVICE @n
 func GetFwErrLogStats(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceErrorLogStatus, error) {
-	opstat := nvmStatusCodeEnum.nvmErrAPINotSupported
-	return opstat, deviceErrorLogStatus{}, fmt.Errorf("method is not implemented")
+	cResult := C.struct_device_error_log_status{}
+	cDeviceUID := deviceUID.toCharArray()
+	cOpstat := C.nvm_get_fw_err_log_stats(&cDeviceUID[0], &cResult)
+	opstat := nvmStatusCodeEnumAttr(cOpstat)
+	if C.NVM_SUCCESS != cOpstat {
+		return opstat, deviceErrorLogStatus{},
+			fmt.Errorf("Unable to get firmware error log statistics of DIMM: %s", deviceUID)
+	}
+	return opstat, *newDeviceErrorLogStatus(cResult), nil
 }
 
 // SyncLockAPI - stubbed - implement if needed
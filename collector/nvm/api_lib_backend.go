@@ -0,0 +1,170 @@
+// +build cgo
+
+/**
+ * Copyright (c) 2020-2021, Intel Corporation.
+ * SPDX-License-Identifier: BSD-3-Clause
+ **
+ * This package introduces wrapper for ipmctl library written in C.
+ * api_lib_backend.go file contains libBackend, the default Backend which
+ * forwards every call to the libipmctl wrappers from api_lib.go.
+ */
+
+package nvm
+
+import (
+	"math"
+)
+
+// pmonSmartDataMask asks libipmctl to return the DDRT and media data along
+// with the PMON counters
+const pmonSmartDataMask = 0x3
+
+// libBackend reads all the data from DCPMMs installed in the system with
+// the use of libipmctl
+type libBackend struct{}
+
+// NewLibBackend creates backend linked to the libipmctl library
+func NewLibBackend() Backend {
+	return &libBackend{}
+}
+
+func (backend *libBackend) Init() (bool, error) {
+	return Init()
+}
+
+func (backend *libBackend) Uninit() (bool, error) {
+	return Uninit()
+}
+
+func (backend *libBackend) GetNumberOfDevices() (nvmStatusCodeEnumAttr, nvmUint8, error) {
+	return GetNumberOfDevices()
+}
+
+func (backend *libBackend) GetDevices(count nvmUint8) (nvmStatusCodeEnumAttr, []deviceDiscovery, error) {
+	return GetDevices(count)
+}
+
+func (backend *libBackend) GetDeviceDiscovery(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceDiscovery, error) {
+	return GetDeviceDiscovery(deviceUID)
+}
+
+func (backend *libBackend) GetSensor(deviceUID nvmUID,
+	stype sensorTypeEnumAttr) (nvmStatusCodeEnumAttr, sensor, error) {
+	return GetSensor(deviceUID, stype)
+}
+
+func (backend *libBackend) GetDevicePerformance(deviceUID nvmUID) (nvmStatusCodeEnumAttr,
+	devicePerformance,
+	error) {
+	return GetDevicePerformance(deviceUID)
+}
+
+func (backend *libBackend) GetDeviceStatus(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceStatus, error) {
+	return GetDeviceStatus(deviceUID)
+}
+
+func (backend *libBackend) GetDeviceFwImageInfo(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceFWInfo, error) {
+	return GetDeviceFwImageInfo(deviceUID)
+}
+
+// GetDeviceCapacities takes capacities from device details, as libipmctl
+// reports only the aggregate capacities of all DCPMMs on its own. The reader
+// calls it only if the details were not read, see embedsDetailsReadings.
+func (backend *libBackend) GetDeviceCapacities(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceCapacities, error) {
+	opstat, details, err := GetDeviceDetails(deviceUID)
+	return opstat, details.capacities, err
+}
+
+func (backend *libBackend) GetDeviceSettings(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceSettings, error) {
+	return GetDeviceSettings(deviceUID)
+}
+
+func (backend *libBackend) GetDeviceDetails(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceDetails, error) {
+	return GetDeviceDetails(deviceUID)
+}
+
+// embedsDetailsReadings reports that nvm_get_device_details fills the
+// capacities and all the sensors of the device, so the reader does not call
+// nvm_get_device_details and nvm_get_sensor again
+func (backend *libBackend) embedsDetailsReadings() bool {
+	return true
+}
+
+func (backend *libBackend) GetRegions() (nvmStatusCodeEnumAttr, []region, error) {
+	opstat, regions, _, err := GetRegions()
+	return opstat, regions, err
+}
+
+func (backend *libBackend) GetEvents() (nvmStatusCodeEnumAttr, []event, error) {
+	filter := eventFilter{}
+	opstat, count, err := GetNumberOfEvents(filter)
+	if nvmStatusCodeEnum.nvmSuccess != opstat {
+		return opstat, nil, err
+	}
+	// event log of libipmctl is limited to 10,000 rows by default
+	if count > math.MaxUint16 {
+		count = math.MaxUint16
+	}
+	return GetEvents(filter, nvmUint16(count))
+}
+
+func (backend *libBackend) GetSockets() (nvmStatusCodeEnumAttr, []socket, error) {
+	opstat, count, err := GetNumberOfSockets()
+	if nvmStatusCodeEnum.nvmSuccess != opstat {
+		return opstat, nil, err
+	}
+	return GetSockets(nvmUint16(count))
+}
+
+func (backend *libBackend) GetMemoryTopology() (nvmStatusCodeEnumAttr, []memoryTopology, error) {
+	opstat, count, err := GetNumberOfMemoryTopologyDevices()
+	if nvmStatusCodeEnum.nvmSuccess != opstat {
+		return opstat, nil, err
+	}
+	// libipmctl takes the number of memory devices as 8 bit value
+	if count > math.MaxUint8 {
+		count = math.MaxUint8
+	}
+	return GetMemoryTopology(nvmUint8(count))
+}
+
+func (backend *libBackend) GetHost() (nvmStatusCodeEnumAttr, host, error) {
+	return GetHost()
+}
+
+func (backend *libBackend) GetSwInventory() (nvmStatusCodeEnumAttr, swInventory, error) {
+	return GetSwInventory()
+}
+
+func (backend *libBackend) GetNVMCapabilities() (nvmStatusCodeEnumAttr, nvmCapabilities, error) {
+	return GetNVMCapabilities()
+}
+
+func (backend *libBackend) GetLibraryVersion() (nvmStatusCodeEnumAttr, libraryVersion, error) {
+	opstat, version, err := GetVersion(nvmVersionLen)
+	if nvmStatusCodeEnum.nvmSuccess != opstat {
+		return opstat, libraryVersion{}, err
+	}
+	return opstat, libraryVersion{
+		version: version,
+		major:   GetMajorVersion(),
+		minor:   GetMinorVersion(),
+		hotfix:  GetHotfixNumber(),
+		build:   GetBuildNumber(),
+	}, nil
+}
+
+func (backend *libBackend) GetPMONRegisters(deviceUID nvmUID) (nvmStatusCodeEnumAttr, pmonRegisters, error) {
+	return GetPMOMRegister(deviceUID, pmonSmartDataMask)
+}
+
+func (backend *libBackend) GetFwErrLogStats(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceErrorLogStatus, error) {
+	return GetFwErrLogStats(deviceUID)
+}
+
+func (backend *libBackend) GetFwErrorLogEntry(deviceUID nvmUID,
+	sequenceNumber nvmUint16,
+	logLevel errorLogLevelEnumAttr,
+	logType errorLogTypeEnumAttr) (nvmStatusCodeEnumAttr, errorLog, error) {
+	return GetFwErrorLogEntryCmd(deviceUID, uint(sequenceNumber), uint8(logLevel), uint8(logType))
+}
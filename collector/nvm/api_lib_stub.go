@@ -0,0 +1,32 @@
+// +build !cgo
+
+/**
+ * Copyright (c) 2020-2021, Intel Corporation.
+ * SPDX-License-Identifier: BSD-3-Clause
+ **
+ * This package introduces wrapper for ipmctl library written in C.
+ * api_lib_stub.go file replaces libBackend when exporter is built without
+ * cgo (CGO_ENABLED=0). Such a binary is not linked with libipmctl, so all
+ * readings have to be taken by one of the other backends.
+ */
+
+package nvm
+
+import (
+	"fmt"
+)
+
+// libBackend is not available without cgo, it fails to initialize
+type libBackend struct {
+	unsupportedBackend
+}
+
+// NewLibBackend creates backend, which reports that libipmctl is not available
+func NewLibBackend() Backend {
+	return &libBackend{}
+}
+
+func (backend *libBackend) Init() (bool, error) {
+	return false, fmt.Errorf("ipmctl exporter was built without libipmctl support (cgo disabled), " +
+		"use other backend, e.g. -backend cli")
+}
@@ -0,0 +1,494 @@
+// +build cgo
+
+/**
+ * Copyright (c) 2020-2021, Intel Corporation.
+ * SPDX-License-Identifier: BSD-3-Clause
+ **
+ * This package introduces wrapper for ipmctl library written in C.
+ * api_lib_utils.go file contains all helper functions used to convert
+ * C types returned by ipmctl library into internal structures defined by
+ * api_types.go file
+ */
+
+package nvm
+
+// #cgo pkg-config: libipmctl
+// #include <include/nvm_management.h>
+import "C"
+
+func strGo2C(str string, length uint) []C.char {
+	result := make([]C.char, length)
+	for i, c := range str {
+		result[i] = C.char(c)
+	}
+	// terminate string
+	result[len(str)] = 0
+	return result
+}
+
+func (uid *nvmUID) toCharArray() []C.char {
+	uidStr := string(*uid)
+	return strGo2C(uidStr, nvmMaxUIDLen)
+}
+
+func makeNVMBool(cValue C.uchar) nvmBool {
+	if 0 == cValue {
+		return nvmBool(false)
+	}
+	return nvmBool(true)
+}
+
+func makeNVMUint8Array(cValue []C.uchar) []nvmUint8 {
+	result := make([]nvmUint8, len(cValue))
+	for i := 0; i < len(cValue); i++ {
+		result[i] = nvmUint8(cValue[i])
+	}
+	return result
+}
+
+func makeNVMUint16Array(cValue []C.ushort) []nvmUint16 {
+	result := make([]nvmUint16, len(cValue))
+	for i := 0; i < len(cValue); i++ {
+		result[i] = nvmUint16(cValue[i])
+	}
+	return result
+}
+
+func makeNVMNfitDeviceHandle(cValue C.NVM_NFIT_DEVICE_HANDLE) []byte {
+	result := make([]byte, 32)
+	for i, cval := range cValue {
+		result[i] = byte(cval)
+	}
+	return result
+}
+
+func newSensorSettings(cValue C.struct_sensor_settings) *sensorSettings {
+	sensorSettings := new(sensorSettings)
+	sensorSettings.enabled = makeNVMBool(cValue.enabled)
+	sensorSettings.upperCriticalThreshold = nvmUint64(cValue.upper_critical_threshold)
+	sensorSettings.lowerCriticalThreshold = nvmUint64(cValue.lower_critical_threshold)
+	sensorSettings.upperFatalThreshold = nvmUint64(cValue.upper_fatal_threshold)
+	sensorSettings.lowerFatalThreshold = nvmUint64(cValue.lower_fatal_threshold)
+	sensorSettings.upperNoncriticalThreshold = nvmUint64(cValue.upper_noncritical_threshold)
+	sensorSettings.lowerNoncriticalThreshold = nvmUint64(cValue.lower_noncritical_threshold)
+	copy(sensorSettings.reserved[:], makeNVMUint8Array(cValue.reserved[:]))
+	return sensorSettings
+}
+
+func newDeviceSecurityCapabilities(cValue C.struct_device_security_capabilities) *deviceSecurityCapabilities {
+	devSecCap := new(deviceSecurityCapabilities)
+	devSecCap.passphraseCapable = makeNVMBool(cValue.passphrase_capable)
+	devSecCap.unlockDeviceCapable = makeNVMBool(cValue.unlock_device_capable)
+	devSecCap.eraseCryptoCapable = makeNVMBool(cValue.erase_crypto_capable)
+	devSecCap.masterPassphraseCapable = makeNVMBool(cValue.master_passphrase_capable)
+	copy(devSecCap.reserved[:], makeNVMUint8Array(cValue.reserved[:]))
+	return devSecCap
+}
+
+func newDeviceCapabilities(cValue C.struct_device_capabilities) *deviceCapabilities {
+	devCap := new(deviceCapabilities)
+	devCap.packageSparingCapable = makeNVMBool(cValue.package_sparing_capable)
+	devCap.memoryModeCapable = makeNVMBool(cValue.memory_mode_capable)
+	devCap.appDirectModeCapable = makeNVMBool(cValue.app_direct_mode_capable)
+	copy(devCap.reserved[:], makeNVMUint8Array(cValue.reserved[:]))
+	return devCap
+}
+
+func newDeviceDiscovery(cValue C.struct_device_discovery) *deviceDiscovery {
+	devDisc := new(deviceDiscovery)
+	devDisc.allPropertiesPopulated = makeNVMBool(cValue.all_properties_populated)
+	devDisc.deviceHandle = makeNVMNfitDeviceHandle(cValue.device_handle)
+	devDisc.physicalID = nvmUint16(cValue.physical_id)
+	devDisc.vendorID = nvmUint16(cValue.vendor_id)
+	devDisc.deviceID = nvmUint16(cValue.device_id)
+	devDisc.revisionID = nvmUint16(cValue.revision_id)
+	devDisc.channelPos = nvmUint16(cValue.channel_pos)
+	devDisc.channelID = nvmUint16(cValue.channel_id)
+	devDisc.memoryControllerID = nvmUint16(cValue.memory_controller_id)
+	devDisc.socketID = nvmUint16(cValue.socket_id)
+	devDisc.nodeControllerID = nvmUint16(cValue.node_controller_id)
+	devDisc.memoryType = memoryTypeEnumAttr(cValue.memory_type)
+	devDisc.dimmSKU = nvmUint32(cValue.dimm_sku)
+	devDisc.manufacturer = nvmManufacturer(makeNVMUint8Array(cValue.manufacturer[:]))
+	devDisc.serialNumber = nvmSerialNumber(makeNVMUint8Array(cValue.serial_number[:]))
+	devDisc.subsystemVendorID = nvmUint16(cValue.subsystem_vendor_id)
+	devDisc.subsystemDeviceID = nvmUint16(cValue.subsystem_device_id)
+	devDisc.subsystemRevisionID = nvmUint16(cValue.subsystem_revision_id)
+	devDisc.manufacturingInfoValid = makeNVMBool(cValue.manufacturing_info_valid)
+	devDisc.manufacturingLocation = nvmUint8(cValue.manufacturing_location)
+	devDisc.manufacturingDate = nvmUint16(cValue.manufacturing_date)
+	devDisc.partNumber = C.GoString(&cValue.part_number[0])
+	devDisc.fwRevision = nvmVersion(C.GoString(&cValue.fw_revision[0]))
+	devDisc.fwAPIVersion = nvmVersion(C.GoString(&cValue.fw_api_version[0]))
+	devDisc.capacity = nvmUint64(cValue.capacity)
+	copy(devDisc.interfaceFormatCodes[:], makeNVMUint16Array(cValue.interface_format_codes[:]))
+	devDisc.securityCapabilities = *newDeviceSecurityCapabilities(cValue.security_capabilities)
+	devDisc.deviceCapabilities = *newDeviceCapabilities(cValue.device_capabilities)
+	devDisc.uid = nvmUID(C.GoString(&cValue.uid[0]))
+	devDisc.lockState = lockStateEnumAttr(cValue.lock_state)
+	devDisc.manageability = manageabilityStateEnumAttr(cValue.manageability)
+	devDisc.controllerRevisionID = nvmUint16(cValue.controller_revision_id)
+	devDisc.masterPassphraseEnabled = makeNVMBool(cValue.master_passphrase_enabled)
+	copy(devDisc.reserved[:], makeNVMUint8Array(cValue.reserved[:]))
+	return devDisc
+}
+
+func newDeviceStatus(cValue C.struct_device_status) *deviceStatus {
+	devStatus := new(deviceStatus)
+	devStatus.health = nvmUint8(cValue.health)
+	devStatus.isNew = makeNVMBool(cValue.is_new)
+	devStatus.isConfigured = makeNVMBool(cValue.is_configured)
+	devStatus.isMissing = makeNVMBool(cValue.is_missing)
+	devStatus.packageSparesAvailable = nvmUint8(cValue.package_spares_available)
+	devStatus.lastShutdownStatusDetails = nvmUint32(cValue.last_shutdown_status_details)
+	devStatus.configStatus = configStatusEnumAttr(cValue.config_status)
+	devStatus.lastShutdownTime = nvmUint64(cValue.last_shutdown_time)
+	devStatus.mixedSKU = makeNVMBool(cValue.mixed_sku)
+	devStatus.skuViolation = makeNVMBool(cValue.sku_violation)
+	devStatus.viralState = makeNVMBool(cValue.viral_state)
+	devStatus.arsStatus = deviceARSStatusEnumAttr(cValue.ars_status)
+	devStatus.overwritedimmStatus = deviceOverwriteDIMMStatusEnumAttr(cValue.overwritedimm_status)
+	devStatus.aitDRAMEnabled = makeNVMBool(cValue.ait_dram_enabled)
+	devStatus.bootStatus = nvmUint64(cValue.boot_status)
+	devStatus.injectedMediaErrors = nvmUint32(cValue.injected_media_errors)
+	devStatus.injectedNonMediaErrors = nvmUint32(cValue.injected_non_media_errors)
+	devStatus.unlachedLastShutdownStatusDetails = nvmUint32(cValue.unlatched_last_shutdown_status_details)
+	devStatus.thermalThrottlePerformanceLossPCNT = nvmUint8(cValue.thermal_throttle_performance_loss_pcnt)
+	copy(devStatus.reserved[:], makeNVMUint8Array(cValue.reserved[:]))
+	return devStatus
+}
+
+func newDeviceFWInfo(cValue C.struct_device_fw_info) *deviceFWInfo {
+	fwInfo := new(deviceFWInfo)
+	fwInfo.activeFwRevision = nvmVersion(C.GoString(&cValue.active_fw_revision[0]))
+	fwInfo.stagedFwRevision = nvmVersion(C.GoString(&cValue.staged_fw_revision[0]))
+	fwInfo.fwImageMaxSize = nvmUint32(cValue.FWImageMaxSize)
+	fwInfo.fwUpdateStatus = fwUpdateStatusEnumAttr(cValue.fw_update_status)
+	copy(fwInfo.reserved[:], makeNVMUint8Array(cValue.reserved[:]))
+	return fwInfo
+}
+
+func newDeviceCapacities(cValue C.struct_device_capacities) *deviceCapacities {
+	capacities := new(deviceCapacities)
+	capacities.capacity = nvmUint64(cValue.capacity)
+	capacities.memoryCapacity = nvmUint64(cValue.memory_capacity)
+	capacities.appDirectoryCapacity = nvmUint64(cValue.app_direct_capacity)
+	capacities.mirroredAPPDirectCapacity = nvmUint64(cValue.mirrored_app_direct_capacity)
+	capacities.unconfiguredCapacity = nvmUint64(cValue.unconfigured_capacity)
+	capacities.inaccessibleCapacity = nvmUint64(cValue.inaccessible_capacity)
+	capacities.reservedCapacity = nvmUint64(cValue.reserved_capacity)
+	copy(capacities.reserved[:], makeNVMUint8Array(cValue.reserved[:]))
+	return capacities
+}
+
+func newDeviceSettings(cValue C.struct_device_settings) *deviceSettings {
+	settings := new(deviceSettings)
+	settings.viralPolicy = makeNVMBool(cValue.viral_policy)
+	settings.viralStatus = makeNVMBool(cValue.viral_status)
+	copy(settings.reserved[:], makeNVMUint8Array(cValue.reserved[:]))
+	return settings
+}
+
+func newDeviceDetails(cValue C.struct_device_details) *deviceDetails {
+	details := new(deviceDetails)
+	details.discovery = *newDeviceDiscovery(cValue.discovery)
+	details.status = *newDeviceStatus(cValue.status)
+	details.fwInfo = *newDeviceFWInfo(cValue.fw_info)
+	details.performance = *newDevicePerformance(cValue.performance)
+	for i := range details.sensors {
+		details.sensors[i] = *newSensor(cValue.sensors[i])
+	}
+	details.capacities = *newDeviceCapacities(cValue.capacities)
+	details.formFactor = deviceFromFactorEnumAttr(cValue.form_factor)
+	details.dataWidth = nvmUint64(cValue.data_width)
+	details.totalWidth = nvmUint64(cValue.total_width)
+	details.speed = nvmUint64(cValue.speed)
+	details.deviceLocator = C.GoString(&cValue.device_locator[0])
+	details.bankLabel = C.GoString(&cValue.bank_label[0])
+	details.peakPowerBudget = nvmUint16(cValue.peak_power_budget)
+	details.avgPowerBudget = nvmUint16(cValue.avg_power_budget)
+	details.packageSparingEnabled = makeNVMBool(cValue.package_sparing_enabled)
+	details.settings = *newDeviceSettings(cValue.settings)
+	copy(details.reserved[:], makeNVMUint8Array(cValue.reserved[:]))
+	return details
+}
+
+func newRegion(cValue C.struct_region) *region {
+	region := new(region)
+	region.isetId = nvmUint64(cValue.isetId)
+	region.rtype = regionTypeEnumAttr(cValue._type)
+	region.capacity = nvmUint64(cValue.capacity)
+	region.freeCapacity = nvmUint64(cValue.free_capacity)
+	region.socketID = nvmUint16(cValue.socket_id)
+	region.dimmCount = nvmUint16(cValue.dimm_count)
+	copy(region.dimms[:], makeNVMUint16Array(cValue.dimms[:]))
+	region.health = regionHealthEnumAttr(cValue.health)
+	copy(region.reserved[:], makeNVMUint8Array(cValue.reserved[:]))
+	return region
+}
+
+func newRegions(cValues []C.struct_region) []region {
+	regions := make([]region, len(cValues))
+	for i, cValue := range cValues {
+		regions[i] = *newRegion(cValue)
+	}
+	return regions
+}
+
+func newHost(cValue C.struct_host) *host {
+	host := new(host)
+	host.name = C.GoString(&cValue.name[0])
+	host.osType = osTypeEnumAttr(cValue.os_type)
+	host.osName = C.GoString(&cValue.os_name[0])
+	host.osVersion = C.GoString(&cValue.os_version[0])
+	host.mixedSku = makeNVMBool(cValue.mixed_sku)
+	host.skuViolation = makeNVMBool(cValue.sku_violation)
+	return host
+}
+
+func newSwInventory(cValue C.struct_sw_inventory) *swInventory {
+	inventory := new(swInventory)
+	inventory.mgmtSwRevision = nvmVersion(C.GoString(&cValue.mgmt_sw_revision[0]))
+	inventory.vendorDriverRevision = nvmVersion(C.GoString(&cValue.vendor_driver_revision[0]))
+	inventory.vendorDriverCompatible = makeNVMBool(cValue.vendor_driver_compatible)
+	copy(inventory.reserved[:], makeNVMUint8Array(cValue.reserved[:]))
+	return inventory
+}
+
+func newNVMFeatures(cValue C.struct_nvm_features) nvmFeatures {
+	return nvmFeatures{
+		getPlatformCapabilities:  makeNVMBool(cValue.get_platform_capabilities),
+		getDevices:               makeNVMBool(cValue.get_devices),
+		getDeviceSMBIOS:          makeNVMBool(cValue.get_device_smbios),
+		getDeviceHealth:          makeNVMBool(cValue.get_device_health),
+		getDeviceSettings:        makeNVMBool(cValue.get_device_settings),
+		modifyDeviceSettings:     makeNVMBool(cValue.modify_device_settings),
+		getDeviceSecurity:        makeNVMBool(cValue.get_device_security),
+		modifyDeviceSecurity:     makeNVMBool(cValue.modify_device_security),
+		getDevicePerformance:     makeNVMBool(cValue.get_device_performance),
+		getDeviceDeviceFirmware:  makeNVMBool(cValue.get_device_firmware),
+		updateDeviceFirmware:     makeNVMBool(cValue.update_device_firmware),
+		getSensors:               makeNVMBool(cValue.get_sensors),
+		modifySensors:            makeNVMBool(cValue.modify_sensors),
+		getDeviceCapacity:        makeNVMBool(cValue.get_device_capacity),
+		modifyDeviceCapacity:     makeNVMBool(cValue.modify_device_capacity),
+		getRegions:               makeNVMBool(cValue.get_regions),
+		getNamespaces:            makeNVMBool(cValue.get_namespaces),
+		getNamespaceDetails:      makeNVMBool(cValue.get_namespace_details),
+		createNamespace:          makeNVMBool(cValue.create_namespace),
+		enableNamespace:          makeNVMBool(cValue.enable_namespace),
+		disableNamespace:         makeNVMBool(cValue.disable_namespace),
+		deleteNamespace:          makeNVMBool(cValue.delete_namespace),
+		getAddressScrubData:      makeNVMBool(cValue.get_address_scrub_data),
+		startAddressScrub:        makeNVMBool(cValue.start_address_scrub),
+		quickDiagnostic:          makeNVMBool(cValue.quick_diagnostic),
+		platformConfigDiagnostic: makeNVMBool(cValue.platform_config_diagnostic),
+		pmMetadataDiagnostic:     makeNVMBool(cValue.pm_metadata_diagnostic),
+		securityDiagnostic:       makeNVMBool(cValue.security_diagnostic),
+		fwConsistencyDiagnostic:  makeNVMBool(cValue.fw_consistency_diagnostic),
+		memoryMode:               makeNVMBool(cValue.memory_mode),
+		appDirectMode:            makeNVMBool(cValue.app_direct_mode),
+		errorInjection:           makeNVMBool(cValue.error_injection),
+	}
+}
+
+func newMemoryCapabilities(cValue C.struct_memory_capabilities) memoryCapabilities {
+	capabilities := memoryCapabilities{
+		supported:               makeNVMBool(cValue.supported),
+		interleaveAlignmentSize: nvmUint16(cValue.interleave_alignment_size),
+		interleaveFormatsCount:  nvmUint16(cValue.interleave_formats_count),
+	}
+	for i, cFormat := range cValue.interleave_formats {
+		capabilities.interleaveFormats[i] = interleaveFormat{
+			recommended: makeNVMBool(cFormat.recommended),
+			channel:     interleaveSizeEnumAttr(cFormat.channel),
+			imc:         interleaveSizeEnumAttr(cFormat.imc),
+			ways:        interleaveWaysEnumAttr(cFormat.ways),
+		}
+	}
+	return capabilities
+}
+
+func newNVMCapabilities(cValue C.struct_nvm_capabilities) *nvmCapabilities {
+	capabilities := new(nvmCapabilities)
+	capabilities.nvmFeatures = newNVMFeatures(cValue.nvm_features)
+	capabilities.swCapabilities.minNamespaceSize = nvmUint64(cValue.sw_capabilities.min_namespace_size)
+	capabilities.swCapabilities.namespaceMemoryPageAllocationCapable =
+		makeNVMBool(cValue.sw_capabilities.namespace_memory_page_allocation_capable)
+	cPlatform := cValue.platform_capabilities
+	capabilities.platformCapabilities = platformCapabilities{
+		biosConfigSupport:        makeNVMBool(cPlatform.bios_config_support),
+		biosRuntimeSupport:       makeNVMBool(cPlatform.bios_runtime_support),
+		memoryMirrorSupported:    makeNVMBool(cPlatform.memory_mirror_supported),
+		memorySpareSupported:     makeNVMBool(cPlatform.memory_spare_supported),
+		memoryMigrationSupported: makeNVMBool(cPlatform.memory_migration_supported),
+		oneLMMode:                newMemoryCapabilities(cPlatform.one_lm_mode),
+		memoryMode:               newMemoryCapabilities(cPlatform.memory_mode),
+		appDirectMode:            newMemoryCapabilities(cPlatform.app_direct_mode),
+		currentVolatileMode:      volatileModeEnumAttr(cPlatform.current_volatile_mode),
+		currentAppDirectMode:     appDirectModeEnumAttr(cPlatform.current_app_direct_mode),
+	}
+	capabilities.dimmSKUCapabilities = skuCapabilities{
+		mixedSKU:     makeNVMBool(cValue.sku_capabilities.mixed_sku),
+		skuViolation: makeNVMBool(cValue.sku_capabilities.sku_violation),
+		memorySKU:    makeNVMBool(cValue.sku_capabilities.memory_sku),
+		appDirectSKU: makeNVMBool(cValue.sku_capabilities.app_direct_sku),
+	}
+	return capabilities
+}
+
+func newSocket(cValue C.struct_socket) *socket {
+	socket := new(socket)
+	socket.id = nvmUint16(cValue.id)
+	socket.mappedMemoryLimit = nvmUint64(cValue.mapped_memory_limit)
+	socket.totalMappedMemory = nvmUint64(cValue.total_mapped_memory)
+	copy(socket.reserved[:], makeNVMUint8Array(cValue.reserved[:]))
+	return socket
+}
+
+func newSockets(cValues []C.struct_socket) []socket {
+	sockets := make([]socket, len(cValues))
+	for i, cValue := range cValues {
+		sockets[i] = *newSocket(cValue)
+	}
+	return sockets
+}
+
+func newMemoryTopology(cValue C.struct_memory_topology) *memoryTopology {
+	topology := new(memoryTopology)
+	topology.physicalID = nvmUint16(cValue.physical_id)
+	topology.memoryType = memoryTypeEnumAttr(cValue.memory_type)
+	topology.deviceLocator = C.GoString(&cValue.device_locator[0])
+	topology.bankLabel = C.GoString(&cValue.bank_label[0])
+	copy(topology.reserved[:], makeNVMUint8Array(cValue.reserved[:]))
+	return topology
+}
+
+func newMemoryTopologies(cValues []C.struct_memory_topology) []memoryTopology {
+	topologies := make([]memoryTopology, len(cValues))
+	for i, cValue := range cValues {
+		topologies[i] = *newMemoryTopology(cValue)
+	}
+	return topologies
+}
+
+func newErrorLog(cValue C.ERROR_LOG) *errorLog {
+	errorLog := new(errorLog)
+	errorLog.dimmID = nvmUint16(cValue.DimmID)
+	errorLog.systemTimestamp = nvmUint64(cValue.SystemTimestamp)
+	errorLog.errorType = nvmUint8(cValue.ErrorType)
+	copy(errorLog.outputData[:], makeNVMUint8Array(cValue.OutputData[:]))
+	return errorLog
+}
+
+func newFwErrorLogSequenceNumbers(cValue C.struct_fw_error_log_sequence_numbers) fwErrorLogSequenceNumbers {
+	sequenceNumbers := fwErrorLogSequenceNumbers{}
+	sequenceNumbers.oldest = nvmUint16(cValue.oldest)
+	sequenceNumbers.current = nvmUint16(cValue.current)
+	copy(sequenceNumbers.reserved[:], makeNVMUint8Array(cValue.reserved[:]))
+	return sequenceNumbers
+}
+
+func newDeviceErrorLogStatus(cValue C.struct_device_error_log_status) *deviceErrorLogStatus {
+	status := new(deviceErrorLogStatus)
+	status.thermLow = newFwErrorLogSequenceNumbers(cValue.therm_low)
+	status.thermHigh = newFwErrorLogSequenceNumbers(cValue.therm_high)
+	status.mediaLow = newFwErrorLogSequenceNumbers(cValue.media_low)
+	status.mediaHigh = newFwErrorLogSequenceNumbers(cValue.media_high)
+	copy(status.reserved[:], makeNVMUint8Array(cValue.reserved[:]))
+	return status
+}
+
+func newCEventFilter(filter eventFilter) C.struct_event_filter {
+	cFilter := C.struct_event_filter{}
+	cFilter.filter_mask = C.NVM_UINT8(filter.filterMask)
+	cFilter._type = C.enum_event_type(filter.etype)
+	cFilter.severity = C.enum_event_severity(filter.severity)
+	cUID := filter.uid.toCharArray()
+	for i := range cFilter.uid {
+		cFilter.uid[i] = cUID[i]
+	}
+	cFilter.event_id = C.int(filter.eventID)
+	return cFilter
+}
+
+func newEvent(cValue C.struct_event) *event {
+	event := new(event)
+	event.eventID = nvmUint32(cValue.event_id)
+	event.etype = eventTypeEnumAttr(cValue._type)
+	event.severity = eventSeverityEnumAttr(cValue.severity)
+	event.code = nvmUint16(cValue.code)
+	event.reserved1 = makeNVMBool(cValue.Reserved)
+	event.uid = nvmUID(C.GoString(&cValue.uid[0]))
+	event.time = timeT(cValue.time)
+	event.message = nvmEventMsg(C.GoString(&cValue.message[0]))
+	for i := range event.args {
+		event.args[i] = nvmEventArg(C.GoString(&cValue.args[i][0]))
+	}
+	event.diagResult = diagnosticResultEnumAttr(cValue.diag_result)
+	copy(event.reserved2[:], makeNVMUint8Array(cValue.reserved[:]))
+	return event
+}
+
+func newEvents(cValues []C.struct_event) []event {
+	events := make([]event, len(cValues))
+	for i, cValue := range cValues {
+		events[i] = *newEvent(cValue)
+	}
+	return events
+}
+
+func newSensor(cValue C.struct_sensor) *sensor {
+	sensor := new(sensor)
+	sensor.stype = sensorTypeEnumAttr(cValue._type)
+	sensor.units = sensorUnitsEnumAttr(cValue.units)
+	sensor.currentState = sensorStatusEnumAttr(cValue.current_state)
+	sensor.reading = nvmUint64(cValue.reading)
+	sensor.settings = *newSensorSettings(cValue.settings)
+	sensor.lowerCriticalSettable = makeNVMBool(cValue.lower_critical_settable)
+	sensor.upperCriticalSettable = makeNVMBool(cValue.upper_critical_settable)
+	sensor.lowerCriticalSupport = makeNVMBool(cValue.lower_critical_support)
+	sensor.upperCriticalSupport = makeNVMBool(cValue.upper_critical_support)
+	sensor.lowerFatalSettable = makeNVMBool(cValue.lower_fatal_settable)
+	sensor.upperFatalSettable = makeNVMBool(cValue.upper_fatal_settable)
+	sensor.lowerFatalSupport = makeNVMBool(cValue.lower_fatal_support)
+	sensor.upperFatalSupport = makeNVMBool(cValue.upper_fatal_support)
+	sensor.lowerNoncriticalSettable = makeNVMBool(cValue.lower_noncritical_settable)
+	sensor.upperNoncriticalSettable = makeNVMBool(cValue.upper_noncritical_settable)
+	sensor.lowerNoncriticalSupport = makeNVMBool(cValue.lower_noncritical_support)
+	sensor.upperNoncriticalSupport = makeNVMBool(cValue.upper_noncritical_support)
+	copy(sensor.reserved[:], makeNVMUint8Array(cValue.reserved[:]))
+	return sensor
+}
+
+func newPMONRegisters(cValue C.PMON_REGISTERS) *pmonRegisters {
+	registers := new(pmonRegisters)
+	registers.smartDataMask = uint8(cValue.SmartDataMask)
+	registers.groupEnabled = uint8(cValue.GroupEnabled)
+	registers.pmon4Counter = uint(cValue.PMON4Counter)
+	registers.pmon5Counter = uint(cValue.PMON5Counter)
+	registers.pmon7Counter = uint(cValue.PMON7Counter)
+	registers.pmon8Counter = uint(cValue.PMON8Counter)
+	registers.pmon9Counter = uint(cValue.PMON9Counter)
+	registers.pmon14Counter = uint(cValue.PMON14Counter)
+	registers.ddrtrd = uint64(cValue.DDRTRD)
+	registers.ddrtwr = uint64(cValue.DDRTWR)
+	registers.merd = uint64(cValue.MERD)
+	registers.mewr = uint64(cValue.MEWR)
+	registers.mtp = uint16(cValue.MTP)
+	registers.ctp = uint16(cValue.CTP)
+	return registers
+}
+
+func newDevicePerformance(cValue C.struct_device_performance) *devicePerformance {
+	devPerf := new(devicePerformance)
+	devPerf.time = timeT(nvmUint64(cValue.time))
+	devPerf.bytesRead = nvmUint64(cValue.bytes_read)
+	devPerf.hostReads = nvmUint64(cValue.host_reads)
+	devPerf.bytesWritten = nvmUint64(cValue.bytes_written)
+	devPerf.hostWrites = nvmUint64(cValue.host_writes)
+	devPerf.blockReads = nvmUint64(cValue.block_reads)
+	devPerf.blockWrites = nvmUint64(cValue.block_writes)
+	copy(devPerf.reserved[:], makeNVMUint8Array(cValue.reserved[:]))
+	return devPerf
+}
@@ -16,6 +16,7 @@ package nvm
 
 import (
 	"fmt"
+	"runtime"
 )
 
 //Version variable is initialised during build
@@ -51,6 +52,8 @@ var DeviceDiscoveryLabelNames = []string{
 	"manageability",
 	"controller_revision_id",
 	"master_passphrase_enabled",
+	"device_handle",
+	"location",
 }
 
 var DeviceSecurityCapabilitiesLabelNames = []string{
@@ -70,6 +73,9 @@ var DeviceCapabilitiesLabelNames = []string{
 
 var IpmctlExporterLabelNames = []string{
 	"version",
+	"go_version",
+	"goos",
+	"goarch",
 }
 
 type deviceDiscoveryReading MetricReading
@@ -208,6 +214,8 @@ func (reader *MetricsReader) GetDeviceDiscoveryInfo() []MetricReading {
 		devDiscoveryReading.Labels.addLabel("manageability", getManageabilityName(discovery.manageability))
 		devDiscoveryReading.Labels.addLabel("controller_revision_id", discovery.controllerRevisionID.toString(16))
 		devDiscoveryReading.Labels.addLabel("master_passphrase_enabled", discovery.masterPassphraseEnabled.toString(10))
+		devDiscoveryReading.Labels.addLabel("device_handle", discovery.deviceHandle.toString(16))
+		devDiscoveryReading.Labels.addLabel("location", discovery.deviceHandle.toParts().toLocation())
 		results[i] = MetricReading(devDiscoveryReading)
 	}
 	return results
@@ -253,6 +261,9 @@ func GetIpmctlExporterInfo() ([]MetricReading, error) {
 	}
 	ipmctlExpReading.MetricValue = 1
 	ipmctlExpReading.Labels.addLabel("version", Version)
+	ipmctlExpReading.Labels.addLabel("go_version", runtime.Version())
+	ipmctlExpReading.Labels.addLabel("goos", runtime.GOOS)
+	ipmctlExpReading.Labels.addLabel("goarch", runtime.GOARCH)
 	results[0] = MetricReading(ipmctlExpReading)
 	return results, err
 }
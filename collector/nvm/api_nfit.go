@@ -0,0 +1,506 @@
+/**
+ * Copyright (c) 2020-2021, Intel Corporation.
+ * SPDX-License-Identifier: BSD-3-Clause
+ **
+ * This package introduces wrapper for ipmctl library written in C.
+ * api_nfit.go file contains parser of ACPI NVDIMM Firmware Interface Table
+ * (NFIT, usually exposed by the kernel as /sys/firmware/acpi/tables/NFIT),
+ * and exposes external API for exporter to collect topology metrics based on
+ * the table. Decoded structures are: System Physical Address (SPA) ranges,
+ * NVDIMM region mappings, interleave and NVDIMM control regions. All the
+ * metrics are keyed by NFIT device handle of the DIMM.
+ */
+
+package nvm
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+const (
+	nfitHeaderLength             = 40
+	nfitSPARangeLength           = 56
+	nfitRegionMappingLength      = 48
+	nfitInterleaveLength         = 16
+	nfitControlRegionShortLength = 32
+)
+
+// NFIT structure types
+const (
+	nfitTypeSPARange      = 0
+	nfitTypeRegionMapping = 1
+	nfitTypeInterleave    = 2
+	nfitTypeControlRegion = 4
+)
+
+// Address range type GUIDs of SPA range structure, as defined by ACPI
+// specification, mapped to the name used in metric labels
+var nfitAddressRangeTypes = map[string]string{
+	"7305944f-fdda-44e3-b16c-3f22d252e5d0": "volatile",
+	"66f0d379-b4f3-4074-ac43-0d3318b78cdb": "persistent",
+	"92f701f6-13b4-405d-910b-299367e8234c": "control_region",
+	"91af0530-5d86-470e-a6b0-0a2db9408249": "block_data_window",
+	"77ab535a-45fc-624b-5560-f7b281d1f96e": "volatile_virtual_disk",
+	"3d5abd30-4175-87ce-6d64-d2ade523c4bb": "volatile_virtual_cd",
+	"5cea02c9-4d07-69d3-269f-4496fbe096f9": "persistent_virtual_disk",
+	"08018188-42cd-bb48-100f-5387d53ded3d": "persistent_virtual_cd",
+}
+
+type nfitSPARange struct {
+	index           uint16
+	flags           uint16
+	proximityDomain uint32
+	addressType     string
+	base            uint64
+	length          uint64
+	mappingAttrs    uint64
+}
+
+type nfitRegionMapping struct {
+	deviceHandle       uint32
+	physicalID         uint16
+	regionID           uint16
+	spaRangeIndex      uint16
+	controlRegionIndex uint16
+	regionSize         uint64
+	regionOffset       uint64
+	physicalBase       uint64
+	interleaveIndex    uint16
+	interleaveWays     uint16
+	stateFlags         uint16
+}
+
+type nfitInterleave struct {
+	index       uint16
+	lineSize    uint32
+	lineOffsets []uint32
+}
+
+type nfitControlRegion struct {
+	index                  uint16
+	vendorID               uint16
+	deviceID               uint16
+	revisionID             uint16
+	subsystemVendorID      uint16
+	subsystemDeviceID      uint16
+	subsystemRevisionID    uint16
+	validFields            uint8
+	manufacturingLocation  uint8
+	manufacturingDate      uint16
+	serialNumber           uint32
+	formatInterfaceCode    uint16
+	blockControlWindows    uint16
+	controlRegionFlags     uint16
+	hasBlockControlWindows bool
+}
+
+// nfitTable holds all the decoded NFIT structures
+type nfitTable struct {
+	revision       uint8
+	spaRanges      map[uint16]nfitSPARange
+	mappings       []nfitRegionMapping
+	interleaves    map[uint16]nfitInterleave
+	controlRegions map[uint16]nfitControlRegion
+}
+
+// parseNfit decodes raw NFIT table, including the ACPI table header.
+// Structures of types not listed above are skipped.
+func parseNfit(data []byte) (*nfitTable, error) {
+	if len(data) < nfitHeaderLength {
+		return nil, fmt.Errorf("NFIT table too short: %d bytes", len(data))
+	}
+	if "NFIT" != string(data[0:4]) {
+		return nil, fmt.Errorf("Invalid NFIT table signature: %q", data[0:4])
+	}
+	length := binary.LittleEndian.Uint32(data[4:8])
+	if int(length) > len(data) || length < nfitHeaderLength {
+		return nil, fmt.Errorf("Invalid NFIT table length: %d (%d bytes available)", length, len(data))
+	}
+	var checksum uint8
+	for _, b := range data[:length] {
+		checksum += b
+	}
+	if 0 != checksum {
+		return nil, fmt.Errorf("Invalid NFIT table checksum")
+	}
+	table := &nfitTable{
+		revision:       data[8],
+		spaRanges:      make(map[uint16]nfitSPARange),
+		mappings:       make([]nfitRegionMapping, 0),
+		interleaves:    make(map[uint16]nfitInterleave),
+		controlRegions: make(map[uint16]nfitControlRegion),
+	}
+	for offset := uint32(nfitHeaderLength); offset < length; {
+		if length-offset < 4 {
+			return nil, fmt.Errorf("Truncated NFIT structure at offset %d", offset)
+		}
+		stype := binary.LittleEndian.Uint16(data[offset:])
+		slength := uint32(binary.LittleEndian.Uint16(data[offset+2:]))
+		if slength < 4 || offset+slength > length {
+			return nil, fmt.Errorf("Invalid length %d of NFIT structure at offset %d", slength, offset)
+		}
+		s := data[offset : offset+slength]
+		var err error
+		switch stype {
+		case nfitTypeSPARange:
+			err = table.addSPARange(s)
+		case nfitTypeRegionMapping:
+			err = table.addRegionMapping(s)
+		case nfitTypeInterleave:
+			err = table.addInterleave(s)
+		case nfitTypeControlRegion:
+			err = table.addControlRegion(s)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Invalid NFIT structure at offset %d: %v", offset, err)
+		}
+		offset += slength
+	}
+	return table, nil
+}
+
+func (table *nfitTable) addSPARange(s []byte) error {
+	if len(s) < nfitSPARangeLength {
+		return fmt.Errorf("SPA range structure too short: %d bytes", len(s))
+	}
+	spaRange := nfitSPARange{
+		index:           binary.LittleEndian.Uint16(s[4:]),
+		flags:           binary.LittleEndian.Uint16(s[6:]),
+		proximityDomain: binary.LittleEndian.Uint32(s[12:]),
+		base:            binary.LittleEndian.Uint64(s[32:]),
+		length:          binary.LittleEndian.Uint64(s[40:]),
+		mappingAttrs:    binary.LittleEndian.Uint64(s[48:]),
+	}
+	guid := formatNfitGUID(s[16:32])
+	addressType, known := nfitAddressRangeTypes[guid]
+	if !known {
+		addressType = guid
+	}
+	spaRange.addressType = addressType
+	table.spaRanges[spaRange.index] = spaRange
+	return nil
+}
+
+func (table *nfitTable) addRegionMapping(s []byte) error {
+	if len(s) < nfitRegionMappingLength {
+		return fmt.Errorf("region mapping structure too short: %d bytes", len(s))
+	}
+	table.mappings = append(table.mappings, nfitRegionMapping{
+		deviceHandle:       binary.LittleEndian.Uint32(s[4:]),
+		physicalID:         binary.LittleEndian.Uint16(s[8:]),
+		regionID:           binary.LittleEndian.Uint16(s[10:]),
+		spaRangeIndex:      binary.LittleEndian.Uint16(s[12:]),
+		controlRegionIndex: binary.LittleEndian.Uint16(s[14:]),
+		regionSize:         binary.LittleEndian.Uint64(s[16:]),
+		regionOffset:       binary.LittleEndian.Uint64(s[24:]),
+		physicalBase:       binary.LittleEndian.Uint64(s[32:]),
+		interleaveIndex:    binary.LittleEndian.Uint16(s[40:]),
+		interleaveWays:     binary.LittleEndian.Uint16(s[42:]),
+		stateFlags:         binary.LittleEndian.Uint16(s[44:]),
+	})
+	return nil
+}
+
+func (table *nfitTable) addInterleave(s []byte) error {
+	if len(s) < nfitInterleaveLength {
+		return fmt.Errorf("interleave structure too short: %d bytes", len(s))
+	}
+	lines := binary.LittleEndian.Uint32(s[8:])
+	if uint64(len(s)) < nfitInterleaveLength+4*uint64(lines) {
+		return fmt.Errorf("interleave structure describes %d lines in %d bytes", lines, len(s))
+	}
+	interleave := nfitInterleave{
+		index:       binary.LittleEndian.Uint16(s[4:]),
+		lineSize:    binary.LittleEndian.Uint32(s[12:]),
+		lineOffsets: make([]uint32, lines),
+	}
+	for i := range interleave.lineOffsets {
+		interleave.lineOffsets[i] = binary.LittleEndian.Uint32(s[nfitInterleaveLength+4*i:])
+	}
+	table.interleaves[interleave.index] = interleave
+	return nil
+}
+
+func (table *nfitTable) addControlRegion(s []byte) error {
+	if len(s) < nfitControlRegionShortLength {
+		return fmt.Errorf("control region structure too short: %d bytes", len(s))
+	}
+	controlRegion := nfitControlRegion{
+		index:                 binary.LittleEndian.Uint16(s[4:]),
+		vendorID:              binary.LittleEndian.Uint16(s[6:]),
+		deviceID:              binary.LittleEndian.Uint16(s[8:]),
+		revisionID:            binary.LittleEndian.Uint16(s[10:]),
+		subsystemVendorID:     binary.LittleEndian.Uint16(s[12:]),
+		subsystemDeviceID:     binary.LittleEndian.Uint16(s[14:]),
+		subsystemRevisionID:   binary.LittleEndian.Uint16(s[16:]),
+		validFields:           s[18],
+		manufacturingLocation: s[19],
+		manufacturingDate:     binary.LittleEndian.Uint16(s[20:]),
+		serialNumber:          binary.LittleEndian.Uint32(s[24:]),
+		formatInterfaceCode:   binary.LittleEndian.Uint16(s[28:]),
+		blockControlWindows:   binary.LittleEndian.Uint16(s[30:]),
+	}
+	// the structure is truncated to 32 bytes when there are no block
+	// control windows
+	if controlRegion.blockControlWindows > 0 && len(s) >= 80 {
+		controlRegion.hasBlockControlWindows = true
+		controlRegion.controlRegionFlags = binary.LittleEndian.Uint16(s[72:])
+	}
+	table.controlRegions[controlRegion.index] = controlRegion
+	return nil
+}
+
+// formatNfitGUID formats GUID stored in mixed endian form (first three
+// fields little endian) as a string
+func formatNfitGUID(b []byte) string {
+	return fmt.Sprintf("%08x-%04x-%04x-%x-%x",
+		binary.LittleEndian.Uint32(b[0:4]),
+		binary.LittleEndian.Uint16(b[4:6]),
+		binary.LittleEndian.Uint16(b[6:8]),
+		b[8:10], b[10:16])
+}
+
+var NfitSPARangeLabelNames = []string{
+	"device_handle",
+	"spa_range_index",
+	"address_range_type",
+	"proximity_domain",
+	"base_address",
+}
+
+var NfitRegionMappingLabelNames = []string{
+	"device_handle",
+	"physical_id",
+	"region_id",
+	"spa_range_index",
+	"control_region_index",
+	"interleave_index",
+}
+
+var NfitControlRegionLabelNames = []string{
+	"device_handle",
+	"control_region_index",
+	"vendor_id",
+	"device_id",
+	"revision_id",
+	"subsystem_vendor_id",
+	"subsystem_device_id",
+	"subsystem_revision_id",
+	"serial_number",
+	"format_interface_code",
+}
+
+var nfitMetricTypeEnum = &nfitMetricType{
+	spaRangeLength: 0,
+	regionSize:     1,
+	interleaveWays: 2,
+	lineSize:       3,
+	stateFlags:     4,
+	controlRegion:  5,
+}
+
+type nfitReading MetricReading
+type nfitSPARangeLabels MetricLabels
+type nfitRegionMappingLabels MetricLabels
+type nfitControlRegionLabels MetricLabels
+type nfitMetricTypeEnumAttr enumAttr
+type nfitMetricType struct {
+	spaRangeLength nfitMetricTypeEnumAttr
+	regionSize     nfitMetricTypeEnumAttr
+	interleaveWays nfitMetricTypeEnumAttr
+	lineSize       nfitMetricTypeEnumAttr
+	stateFlags     nfitMetricTypeEnumAttr
+	controlRegion  nfitMetricTypeEnumAttr
+}
+
+func (nl nfitSPARangeLabels) GetLabelValues() []string {
+	return getValuesByName(NfitSPARangeLabelNames, MetricLabels(nl).labels)
+}
+
+func (nl nfitRegionMappingLabels) GetLabelValues() []string {
+	return getValuesByName(NfitRegionMappingLabelNames, MetricLabels(nl).labels)
+}
+
+func (nl nfitControlRegionLabels) GetLabelValues() []string {
+	return getValuesByName(NfitControlRegionLabelNames, MetricLabels(nl).labels)
+}
+
+func (nl nfitSPARangeLabels) GetLabelNames() []string {
+	return NfitSPARangeLabelNames
+}
+
+func (nl nfitRegionMappingLabels) GetLabelNames() []string {
+	return NfitRegionMappingLabelNames
+}
+
+func (nl nfitControlRegionLabels) GetLabelNames() []string {
+	return NfitControlRegionLabelNames
+}
+
+func (nl nfitSPARangeLabels) addLabel(name string, value string) {
+	MetricLabels(nl).labels[name] = value
+}
+
+func (nl nfitRegionMappingLabels) addLabel(name string, value string) {
+	MetricLabels(nl).labels[name] = value
+}
+
+func (nl nfitControlRegionLabels) addLabel(name string, value string) {
+	MetricLabels(nl).labels[name] = value
+}
+
+func newNfitReading(metricType nfitMetricTypeEnumAttr,
+	metricValue nvmUint64,
+	labels Labels) *nfitReading {
+	nfitReading := new(nfitReading)
+	nfitReading.DIMMUID = ""
+	nfitReading.ReadStatus = int(0)
+	nfitReading.MetricType = uint8(metricType)
+	nfitReading.MetricValue = float64(metricValue)
+	nfitReading.Labels = labels
+	return nfitReading
+}
+
+// NfitReader reads topology metrics from ACPI NFIT table
+type NfitReader struct {
+	path  string
+	table *nfitTable
+}
+
+func NewNfitReader(path string) *NfitReader {
+	return &NfitReader{path: path}
+}
+
+// Read parses NFIT table, it should be called before any of the getters
+func (reader *NfitReader) Read() error {
+	data, err := ioutil.ReadFile(reader.path)
+	if err != nil {
+		reader.table = nil
+		return fmt.Errorf("Unable to read NFIT table: %v", err)
+	}
+	table, err := parseNfit(data)
+	if err != nil {
+		reader.table = nil
+		return err
+	}
+	reader.table = table
+	return nil
+}
+
+func (reader *NfitReader) mappings() []nfitRegionMapping {
+	if reader.table == nil {
+		return []nfitRegionMapping{}
+	}
+	return reader.table.mappings
+}
+
+func nfitHandleToString(handle uint32) string {
+	return toString(uint64(handle), 16)
+}
+
+func (mapping nfitRegionMapping) newLabels() nfitRegionMappingLabels {
+	labels := nfitRegionMappingLabels(*newMetricLabels())
+	labels.addLabel("device_handle", nfitHandleToString(mapping.deviceHandle))
+	labels.addLabel("physical_id", toString(uint64(mapping.physicalID), 16))
+	labels.addLabel("region_id", toString(uint64(mapping.regionID), 10))
+	labels.addLabel("spa_range_index", toString(uint64(mapping.spaRangeIndex), 10))
+	labels.addLabel("control_region_index", toString(uint64(mapping.controlRegionIndex), 10))
+	labels.addLabel("interleave_index", toString(uint64(mapping.interleaveIndex), 10))
+	return labels
+}
+
+// Length of SPA ranges mapped to DIMMs
+func (reader *NfitReader) GetSPARangeLength() []MetricReading {
+	results := make([]MetricReading, 0)
+	for _, mapping := range reader.mappings() {
+		spaRange, found := reader.table.spaRanges[mapping.spaRangeIndex]
+		if !found {
+			continue
+		}
+		labels := nfitSPARangeLabels(*newMetricLabels())
+		labels.addLabel("device_handle", nfitHandleToString(mapping.deviceHandle))
+		labels.addLabel("spa_range_index", toString(uint64(spaRange.index), 10))
+		labels.addLabel("address_range_type", spaRange.addressType)
+		labels.addLabel("proximity_domain", toString(uint64(spaRange.proximityDomain), 10))
+		labels.addLabel("base_address", toString(spaRange.base, 16))
+		reading := *newNfitReading(nfitMetricTypeEnum.spaRangeLength, nvmUint64(spaRange.length), labels)
+		results = append(results, MetricReading(reading))
+	}
+	return results
+}
+
+// Size of the region of DIMM mapped to SPA range
+func (reader *NfitReader) GetRegionSize() []MetricReading {
+	results := make([]MetricReading, 0)
+	for _, mapping := range reader.mappings() {
+		reading := *newNfitReading(nfitMetricTypeEnum.regionSize, nvmUint64(mapping.regionSize), mapping.newLabels())
+		results = append(results, MetricReading(reading))
+	}
+	return results
+}
+
+// Number of DIMMs interleaved together in SPA range
+func (reader *NfitReader) GetInterleaveWays() []MetricReading {
+	results := make([]MetricReading, 0)
+	for _, mapping := range reader.mappings() {
+		reading := *newNfitReading(nfitMetricTypeEnum.interleaveWays, nvmUint64(mapping.interleaveWays), mapping.newLabels())
+		results = append(results, MetricReading(reading))
+	}
+	return results
+}
+
+// Interleave line size of the region
+func (reader *NfitReader) GetInterleaveLineSize() []MetricReading {
+	results := make([]MetricReading, 0)
+	for _, mapping := range reader.mappings() {
+		interleave, found := reader.table.interleaves[mapping.interleaveIndex]
+		if 0 == mapping.interleaveIndex || !found {
+			continue
+		}
+		reading := *newNfitReading(nfitMetricTypeEnum.lineSize, nvmUint64(interleave.lineSize), mapping.newLabels())
+		results = append(results, MetricReading(reading))
+	}
+	return results
+}
+
+// NVDIMM state flags of the region (e.g. save, restore or flush failures)
+func (reader *NfitReader) GetStateFlags() []MetricReading {
+	results := make([]MetricReading, 0)
+	for _, mapping := range reader.mappings() {
+		reading := *newNfitReading(nfitMetricTypeEnum.stateFlags, nvmUint64(mapping.stateFlags), mapping.newLabels())
+		results = append(results, MetricReading(reading))
+	}
+	return results
+}
+
+// Control regions of DIMMs
+func (reader *NfitReader) GetControlRegionInfo() []MetricReading {
+	results := make([]MetricReading, 0)
+	reported := make(map[string]bool)
+	for _, mapping := range reader.mappings() {
+		controlRegion, found := reader.table.controlRegions[mapping.controlRegionIndex]
+		key := strings.Join([]string{nfitHandleToString(mapping.deviceHandle),
+			toString(uint64(controlRegion.index), 10)}, "/")
+		if !found || reported[key] {
+			continue
+		}
+		reported[key] = true
+		labels := nfitControlRegionLabels(*newMetricLabels())
+		labels.addLabel("device_handle", nfitHandleToString(mapping.deviceHandle))
+		labels.addLabel("control_region_index", toString(uint64(controlRegion.index), 10))
+		labels.addLabel("vendor_id", toString(uint64(controlRegion.vendorID), 16))
+		labels.addLabel("device_id", toString(uint64(controlRegion.deviceID), 16))
+		labels.addLabel("revision_id", toString(uint64(controlRegion.revisionID), 16))
+		labels.addLabel("subsystem_vendor_id", toString(uint64(controlRegion.subsystemVendorID), 16))
+		labels.addLabel("subsystem_device_id", toString(uint64(controlRegion.subsystemDeviceID), 16))
+		labels.addLabel("subsystem_revision_id", toString(uint64(controlRegion.subsystemRevisionID), 16))
+		labels.addLabel("serial_number", toString(uint64(controlRegion.serialNumber), 16))
+		labels.addLabel("format_interface_code", toString(uint64(controlRegion.formatInterfaceCode), 16))
+		reading := *newNfitReading(nfitMetricTypeEnum.controlRegion, 1, labels)
+		results = append(results, MetricReading(reading))
+	}
+	return results
+}
@@ -0,0 +1,198 @@
+/**
+ * Copyright (c) 2020-2021, Intel Corporation.
+ * SPDX-License-Identifier: BSD-3-Clause
+ **
+ * api_nfit_test.go file tests NFIT parser against testdata/nfit/NFIT, a
+ * table of two DCPMMs (handles 0x1 and 0x101) interleaved in a single
+ * persistent SPA range. Its structures start at the following offsets:
+ *
+ *	 40 SPA range 1 (persistent)      96 SPA range 2 (control region)
+ *	152 region mapping of 0x1        200 region mapping of 0x101
+ *	248 interleave 1 (2 lines)       272 control region 1 (32 bytes)
+ *	304 control region 2 (80 bytes)  384 flush hint address (skipped)
+ */
+
+package nvm
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var nfitFixtureOffsets = []int{40, 96, 152, 200, 248, 272, 304, 384, 408}
+
+func readNfitFixture(t *testing.T) []byte {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "nfit", "NFIT"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// withNfitLength returns copy of the table, which header reports given
+// length, with the checksum fixed
+func withNfitLength(data []byte, length int) []byte {
+	result := append([]byte{}, data...)
+	binary.LittleEndian.PutUint32(result[4:], uint32(length))
+	result[9] = 0
+	var checksum uint8
+	for _, b := range result[:length] {
+		checksum += b
+	}
+	result[9] = -checksum
+	return result
+}
+
+func TestParseNfit(t *testing.T) {
+	table, err := parseNfit(readNfitFixture(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.revision != 1 || len(table.spaRanges) != 2 || len(table.mappings) != 2 ||
+		len(table.interleaves) != 1 || len(table.controlRegions) != 2 {
+		t.Fatalf("unexpected structures decoded: %+v", table)
+	}
+	persistent := table.spaRanges[1]
+	if persistent.addressType != "persistent" || persistent.base != 0x3040000000 ||
+		persistent.length != 0x7e00000000 || persistent.mappingAttrs != 0x8008 {
+		t.Errorf("unexpected persistent SPA range %+v", persistent)
+	}
+	if addressType := table.spaRanges[2].addressType; addressType != "control_region" {
+		t.Errorf("unexpected address range type %s", addressType)
+	}
+	expected := nfitRegionMapping{
+		deviceHandle:       0x101,
+		physicalID:         0x26,
+		spaRangeIndex:      1,
+		controlRegionIndex: 2,
+		regionSize:         0x3f00000000,
+		regionOffset:       0x100,
+		interleaveIndex:    1,
+		interleaveWays:     2,
+		stateFlags:         1,
+	}
+	if !reflect.DeepEqual(table.mappings[1], expected) {
+		t.Errorf("expected mapping %+v, got %+v", expected, table.mappings[1])
+	}
+	interleave := table.interleaves[1]
+	if interleave.lineSize != 256 || !reflect.DeepEqual(interleave.lineOffsets, []uint32{0, 1}) {
+		t.Errorf("unexpected interleave %+v", interleave)
+	}
+	short := table.controlRegions[1]
+	if short.serialNumber != 0x1234 || short.vendorID != 0x8980 || short.formatInterfaceCode != 0x301 ||
+		short.manufacturingDate != 0x1951 || short.hasBlockControlWindows {
+		t.Errorf("unexpected control region %+v", short)
+	}
+	long := table.controlRegions[2]
+	if long.serialNumber != 0x5678 || !long.hasBlockControlWindows || long.controlRegionFlags != 1 {
+		t.Errorf("unexpected control region %+v", long)
+	}
+}
+
+func TestNfitReader(t *testing.T) {
+	reader := NewNfitReader(filepath.Join("testdata", "nfit", "NFIT"))
+	if err := reader.Read(); err != nil {
+		t.Fatal(err)
+	}
+	lengths := reader.GetSPARangeLength()
+	if len(lengths) != 2 {
+		t.Fatalf("expected SPA range of both DCPMMs, got %d", len(lengths))
+	}
+	expected := []string{"0x101", "1", "persistent", "0", "0x3040000000"}
+	if values := lengths[1].Labels.GetLabelValues(); !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected labels %v, got %v", expected, values)
+	}
+	if lengths[1].MetricValue != 0x7e00000000 {
+		t.Errorf("unexpected SPA range length %v", lengths[1].MetricValue)
+	}
+	for _, reading := range reader.GetInterleaveLineSize() {
+		if reading.MetricValue != 256 {
+			t.Errorf("unexpected line size %v", reading.MetricValue)
+		}
+	}
+	controlRegions := reader.GetControlRegionInfo()
+	if len(controlRegions) != 2 {
+		t.Fatalf("expected control regions of both DCPMMs, got %d", len(controlRegions))
+	}
+	expected = []string{"0x1", "1", "0x8980", "0x97a", "0x20", "0x8089", "0x97a", "0x20", "0x1234", "0x301"}
+	if values := controlRegions[0].Labels.GetLabelValues(); !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected labels %v, got %v", expected, values)
+	}
+
+	reader = NewNfitReader(filepath.Join("testdata", "nfit", "missing"))
+	if err := reader.Read(); err == nil {
+		t.Errorf("expected missing table to fail")
+	}
+	if readings := reader.GetRegionSize(); len(readings) != 0 {
+		t.Errorf("expected no readings without table, got %d", len(readings))
+	}
+}
+
+// TestParseNfitTruncated cuts the table at every byte, with the header
+// length either left as is or fixed, only tables ending at a structure
+// boundary are valid
+func TestParseNfitTruncated(t *testing.T) {
+	data := readNfitFixture(t)
+	boundaries := make(map[int]bool)
+	for _, offset := range nfitFixtureOffsets {
+		boundaries[offset] = true
+	}
+	for length := 0; length < len(data); length++ {
+		if _, err := parseNfit(data[:length]); err == nil {
+			t.Errorf("table truncated to %d bytes parsed", length)
+		}
+		if length < nfitHeaderLength {
+			continue
+		}
+		_, err := parseNfit(withNfitLength(data, length)[:length])
+		if boundaries[length] && err != nil {
+			t.Errorf("table of %d bytes failed: %v", length, err)
+		}
+		if !boundaries[length] && err == nil {
+			t.Errorf("table with structure truncated at %d bytes parsed", length)
+		}
+	}
+}
+
+func TestParseNfitInvalidLength(t *testing.T) {
+	data := readNfitFixture(t)
+	tests := []struct {
+		name   string
+		offset int
+		value  uint16
+	}{
+		{"zero length of SPA range", 40 + 2, 0},
+		{"length of SPA range shorter than its header", 40 + 2, 2},
+		{"length of SPA range beyond the table", 40 + 2, 0xffff},
+		{"short SPA range", 40 + 2, 52},
+		{"short region mapping", 152 + 2, 40},
+		{"short interleave", 248 + 2, 12},
+		{"interleave lines beyond the structure", 248 + 8, 0xffff},
+		{"short control region", 272 + 2, 16},
+		{"length of the last structure beyond the table", 384 + 2, 32},
+	}
+	for _, test := range tests {
+		corrupted := append([]byte{}, data...)
+		binary.LittleEndian.PutUint16(corrupted[test.offset:], test.value)
+		if _, err := parseNfit(withNfitLength(corrupted, len(corrupted))); err == nil {
+			t.Errorf("%s: table parsed", test.name)
+		}
+	}
+
+	corrupted := append([]byte{}, data...)
+	corrupted[100]++
+	if _, err := parseNfit(corrupted); err == nil {
+		t.Errorf("table with invalid checksum parsed")
+	}
+	binary.LittleEndian.PutUint32(corrupted[4:], 0xffffffff)
+	if _, err := parseNfit(corrupted); err == nil {
+		t.Errorf("table with length beyond the data parsed")
+	}
+	copy(corrupted, "SSDT")
+	if _, err := parseNfit(corrupted); err == nil {
+		t.Errorf("table with invalid signature parsed")
+	}
+}
@@ -1,5 +1,3 @@
-// +build cgo
-
 /**
  * Copyright (c) 2020-2021, Intel Corporation.
  * SPDX-License-Identifier: BSD-3-Clause
@@ -12,24 +10,11 @@
 
 package nvm
 
-// #cgo pkg-config: libipmctl
-// #include <include/nvm_management.h>
-import "C"
-
 import (
+	"fmt"
 	"strconv"
 )
 
-func strGo2C(str string, length uint) []C.char {
-	result := make([]C.char, length)
-	for i, c := range str {
-		result[i] = C.char(c)
-	}
-	// terminate string
-	result[len(str)] = 0
-	return result
-}
-
 func bytesToString(value []nvmUint8) string {
 	result := "0x"
 	for _, v := range value {
@@ -46,11 +31,6 @@ func uint16ToString(value []nvmUint16) string {
 	return result
 }
 
-func (uid *nvmUID) toCharArray() []C.char {
-	uidStr := string(*uid)
-	return strGo2C(uidStr, nvmMaxUIDLen)
-}
-
 func toString(value uint64, base int) string {
 	prefix := ""
 	if 16 == base {
@@ -95,3 +75,38 @@ func (value *nvmBool) toNvmUint64() nvmUint64 {
 	}
 	return nvmUint64(0)
 }
+
+// toUint32 returns NFIT device handle value, which is stored little endian
+// in the first 4 bytes of the handle
+func (handle nvmNfitDeviceHandle) toUint32() uint32 {
+	value := uint32(0)
+	for i := 0; i < 4 && i < len(handle); i++ {
+		value |= uint32(handle[i]) << (8 * uint(i))
+	}
+	return value
+}
+
+// toParts decodes NFIT device handle as defined by ACPI specification
+// (bits 3:0 DIMM number, 7:4 memory channel, 11:8 memory controller,
+// 15:12 socket, 27:16 node controller)
+func (handle nvmNfitDeviceHandle) toParts() deviceHandleParts {
+	value := handle.toUint32()
+	return deviceHandleParts{
+		memChannelDIMMNum:  nvmUint32(value & 0xf),
+		memChannelID:       nvmUint32((value >> 4) & 0xf),
+		memoryControllerID: nvmUint32((value >> 8) & 0xf),
+		socketID:           nvmUint32((value >> 12) & 0xf),
+		nodeControllerID:   nvmUint32((value >> 16) & 0xfff),
+		rsvd:               nvmUint32(value >> 28),
+	}
+}
+
+func (handle nvmNfitDeviceHandle) toString(base int) string {
+	return toString(uint64(handle.toUint32()), base)
+}
+
+// toLocation returns physical location of the DIMM, e.g. CPU0_IMC1_CH0_DIMM1
+func (parts deviceHandleParts) toLocation() string {
+	return fmt.Sprintf("CPU%d_IMC%d_CH%d_DIMM%d", parts.socketID,
+		parts.memoryControllerID, parts.memChannelID, parts.memChannelDIMMNum)
+}
@@ -4,7 +4,9 @@
  **
  * This package introduces wrapper for ipmctl library written in C.
  * api_perform.go file exposes external API for exporter to collect
- * some NVM performance metrics.
+ * some NVM performance metrics. Lifetime counters are taken from device
+ * performance data, counters of the current power cycle and the counters of
+ * the enabled PMON group from PMON registers.
  */
 
 package nvm
@@ -13,31 +15,62 @@ import (
 	"fmt"
 )
 
+// mediaAccessSize is the number of bytes transferred by every media read
+// or write counted by DCPMM
+const mediaAccessSize = 64
+
 var DevPerformanceLabelNames = []string{
 	"uid",
 }
 
+var PMONCounterLabelNames = []string{
+	"uid",
+	"group",
+	"counter",
+}
+
 var devPerformanceTypeEnum = &devPerformanceType{
-	bytesRead:    0,
-	hostReads:    1,
-	bytesWritten: 2,
-	hostWrites:   3,
-	blockReads:   4,
-	blockWrites:  5,
-	unknown:      0xFF,
+	bytesRead:        0,
+	hostReads:        1,
+	bytesWritten:     2,
+	hostWrites:       3,
+	blockReads:       4,
+	blockWrites:      5,
+	mediaReads:       6,
+	mediaWrites:      7,
+	ddrtReads:        8,
+	ddrtWrites:       9,
+	pmonCounter:      10,
+	bytesReadSize:    11,
+	bytesWrittenSize: 12,
+	mediaReadsSize:   13,
+	mediaWritesSize:  14,
+	time:             15,
+	unknown:          0xFF,
 }
 
 type devPerformanceReading MetricReading
 type devPerformanceLabels MetricLabels
+type pmonCounterLabels MetricLabels
 type devPerformanceTypeEnumAttr enumAttr
 type devPerformanceType struct {
-	bytesRead    devPerformanceTypeEnumAttr
-	hostReads    devPerformanceTypeEnumAttr
-	bytesWritten devPerformanceTypeEnumAttr
-	hostWrites   devPerformanceTypeEnumAttr
-	blockReads   devPerformanceTypeEnumAttr
-	blockWrites  devPerformanceTypeEnumAttr
-	unknown      devPerformanceTypeEnumAttr
+	bytesRead        devPerformanceTypeEnumAttr
+	hostReads        devPerformanceTypeEnumAttr
+	bytesWritten     devPerformanceTypeEnumAttr
+	hostWrites       devPerformanceTypeEnumAttr
+	blockReads       devPerformanceTypeEnumAttr
+	blockWrites      devPerformanceTypeEnumAttr
+	mediaReads       devPerformanceTypeEnumAttr
+	mediaWrites      devPerformanceTypeEnumAttr
+	ddrtReads        devPerformanceTypeEnumAttr
+	ddrtWrites       devPerformanceTypeEnumAttr
+	pmonCounter      devPerformanceTypeEnumAttr
+	bytesReadSize    devPerformanceTypeEnumAttr
+	bytesWrittenSize devPerformanceTypeEnumAttr
+	mediaReadsSize   devPerformanceTypeEnumAttr
+	mediaWritesSize  devPerformanceTypeEnumAttr
+	time             devPerformanceTypeEnumAttr
+	unknown          devPerformanceTypeEnumAttr
 }
 
 func (pl devPerformanceLabels) GetLabelValues() []string {
@@ -45,13 +78,25 @@ func (pl devPerformanceLabels) GetLabelValues() []string {
 }
 
 func (pl devPerformanceLabels) GetLabelNames() []string {
-	return DevPerformanceLabelNames
+	return getNamesByLabels(DevPerformanceLabelNames, MetricLabels(pl).labels)
 }
 
 func (pl devPerformanceLabels) addLabel(name string, value string) {
 	MetricLabels(pl).labels[name] = value
 }
 
+func (pl pmonCounterLabels) GetLabelValues() []string {
+	return getValuesByName(PMONCounterLabelNames, MetricLabels(pl).labels)
+}
+
+func (pl pmonCounterLabels) GetLabelNames() []string {
+	return getNamesByLabels(PMONCounterLabelNames, MetricLabels(pl).labels)
+}
+
+func (pl pmonCounterLabels) addLabel(name string, value string) {
+	MetricLabels(pl).labels[name] = value
+}
+
 func newDevPerformanceReading(dimmUID nvmUID,
 	readStatus nvmStatusCodeEnumAttr,
 	metricType devPerformanceTypeEnumAttr,
@@ -84,36 +129,116 @@ func (reader *MetricsReader) getDevicePerformanceReadings(metricType devPerforma
 			metricValue = perf.blockReads
 		case devPerformanceTypeEnum.blockWrites:
 			metricValue = perf.blockWrites
+		case devPerformanceTypeEnum.bytesReadSize:
+			metricValue = perf.bytesRead * mediaAccessSize
+		case devPerformanceTypeEnum.bytesWrittenSize:
+			metricValue = perf.bytesWritten * mediaAccessSize
+		case devPerformanceTypeEnum.time:
+			metricValue = nvmUint64(perf.time)
 		}
 		devPerfReading := *newDevPerformanceReading(dev.uid, opstat, metricType, metricValue)
 		devPerfReading.Labels.addLabel("uid", string(dev.uid))
+		dev.addLocationLabel(devPerfReading.Labels)
+		results[i] = MetricReading(devPerfReading)
+	}
+	return results
+}
+
+func (reader *MetricsReader) getPowerCycleReadings(metricType devPerformanceTypeEnumAttr) []MetricReading {
+	results := make([]MetricReading, reader.deviceCount)
+	for i, dev := range reader.devices {
+		pmon := dev.pmon
+		metricValue := nvmUint64(0)
+		switch metricType {
+		case devPerformanceTypeEnum.mediaReads:
+			metricValue = nvmUint64(pmon.merd)
+		case devPerformanceTypeEnum.mediaWrites:
+			metricValue = nvmUint64(pmon.mewr)
+		case devPerformanceTypeEnum.ddrtReads:
+			metricValue = nvmUint64(pmon.ddrtrd)
+		case devPerformanceTypeEnum.ddrtWrites:
+			metricValue = nvmUint64(pmon.ddrtwr)
+		case devPerformanceTypeEnum.mediaReadsSize:
+			metricValue = nvmUint64(pmon.merd) * mediaAccessSize
+		case devPerformanceTypeEnum.mediaWritesSize:
+			metricValue = nvmUint64(pmon.mewr) * mediaAccessSize
+		}
+		devPerfReading := *newDevPerformanceReading(dev.uid, dev.pmonOpstat, metricType, metricValue)
+		devPerfReading.Labels.addLabel("uid", string(dev.uid))
+		dev.addLocationLabel(devPerfReading.Labels)
 		results[i] = MetricReading(devPerfReading)
 	}
 	return results
 }
 
 // Number of 64 byte reads from media on the DCPMM since last AC cycle
-func (reader *MetricsReader) GetMediaReads() ([]MetricReading, error) {
-	// stubbed - was not exposed by NVM API
-	return []MetricReading{}, fmt.Errorf("stubbed")
+func (reader *MetricsReader) GetMediaReads() []MetricReading {
+	metricType := devPerformanceTypeEnum.mediaReads
+	return reader.getPowerCycleReadings(metricType)
 }
 
 // Number of 64 byte writes to media on the DCPMM since last AC cycle
-func (reader *MetricsReader) GetMediaWrites() ([]MetricReading, error) {
-	// stubbed - was not exposed by NVM API
-	return []MetricReading{}, fmt.Errorf("stubbed")
+func (reader *MetricsReader) GetMediaWrites() []MetricReading {
+	metricType := devPerformanceTypeEnum.mediaWrites
+	return reader.getPowerCycleReadings(metricType)
 }
 
 // Number of DDRT read transactions the DCPMM has serviced since last AC cycle
-func (reader *MetricsReader) GetReadRequests() ([]MetricReading, error) {
-	// stubbed - was not exposed by NVM API
-	return []MetricReading{}, fmt.Errorf("stubbed")
+func (reader *MetricsReader) GetReadRequests() []MetricReading {
+	metricType := devPerformanceTypeEnum.ddrtReads
+	return reader.getPowerCycleReadings(metricType)
 }
 
 // Number of DDRT write transactions the DCPMM has serviced since last AC cycle
-func (reader *MetricsReader) GetWriteRequest() ([]MetricReading, error) {
-	// stubbed - was not exposed by NVM API
-	return []MetricReading{}, fmt.Errorf("stubbed")
+func (reader *MetricsReader) GetWriteRequests() []MetricReading {
+	metricType := devPerformanceTypeEnum.ddrtWrites
+	return reader.getPowerCycleReadings(metricType)
+}
+
+// Number of bytes read from media on the DCPMM since last AC cycle
+func (reader *MetricsReader) GetMediaReadBytes() []MetricReading {
+	metricType := devPerformanceTypeEnum.mediaReadsSize
+	return reader.getPowerCycleReadings(metricType)
+}
+
+// Number of bytes written to media on the DCPMM since last AC cycle
+func (reader *MetricsReader) GetMediaWrittenBytes() []MetricReading {
+	metricType := devPerformanceTypeEnum.mediaWritesSize
+	return reader.getPowerCycleReadings(metricType)
+}
+
+// Values of the PMON counters of the DCPMM, the events counted depend on
+// the PMON group enabled (group F means no group is enabled). These are all
+// the counters PMON_REGISTERS of nvm_management.h carries: PMON 4-9 except
+// PMON 6, which slot is reserved in the payload, and PMON 14. The group is
+// a label, so the counters of newly enabled group are new series.
+func (reader *MetricsReader) GetPMONCounters() []MetricReading {
+	results := make([]MetricReading, 0, 6*len(reader.devices))
+	for _, dev := range reader.devices {
+		pmon := dev.pmon
+		counters := []struct {
+			name  string
+			value uint
+		}{
+			{"4", pmon.pmon4Counter},
+			{"5", pmon.pmon5Counter},
+			{"7", pmon.pmon7Counter},
+			{"8", pmon.pmon8Counter},
+			{"9", pmon.pmon9Counter},
+			{"14", pmon.pmon14Counter},
+		}
+		for _, counter := range counters {
+			devPerfReading := newDevPerformanceReading(dev.uid, dev.pmonOpstat,
+				devPerformanceTypeEnum.pmonCounter, nvmUint64(counter.value))
+			devPerfReading.Labels = pmonCounterLabels(*newMetricLabels())
+			devPerfReading.Labels.addLabel("uid", string(dev.uid))
+			devPerfReading.Labels.addLabel("group", fmt.Sprintf("%X", pmon.groupEnabled))
+			devPerfReading.Labels.addLabel("counter", counter.name)
+			dev.addLocationLabel(devPerfReading.Labels)
+			results = append(results, MetricReading(*devPerfReading))
+		}
+	}
+	return results
 }
 
 // Lifetime number of 64 byte reads from media on the DCPMM
@@ -139,3 +264,33 @@ func (reader *MetricsReader) GetTotalWriteRequests() []MetricReading {
 	metricType := devPerformanceTypeEnum.hostWrites
 	return reader.getDevicePerformanceReadings(metricType)
 }
+
+// Lifetime number of block window read requests the DCPMM has serviced
+func (reader *MetricsReader) GetTotalBlockReads() []MetricReading {
+	metricType := devPerformanceTypeEnum.blockReads
+	return reader.getDevicePerformanceReadings(metricType)
+}
+
+// Lifetime number of block window write requests the DCPMM has serviced
+func (reader *MetricsReader) GetTotalBlockWrites() []MetricReading {
+	metricType := devPerformanceTypeEnum.blockWrites
+	return reader.getDevicePerformanceReadings(metricType)
+}
+
+// Lifetime number of bytes read from media on the DCPMM
+func (reader *MetricsReader) GetTotalMediaReadBytes() []MetricReading {
+	metricType := devPerformanceTypeEnum.bytesReadSize
+	return reader.getDevicePerformanceReadings(metricType)
+}
+
+// Lifetime number of bytes written to media on the DCPMM
+func (reader *MetricsReader) GetTotalMediaWrittenBytes() []MetricReading {
+	metricType := devPerformanceTypeEnum.bytesWrittenSize
+	return reader.getDevicePerformanceReadings(metricType)
+}
+
+// Time the performance snapshot of the DCPMM was gathered, as Unix timestamp
+func (reader *MetricsReader) GetPerformanceTime() []MetricReading {
+	metricType := devPerformanceTypeEnum.time
+	return reader.getDevicePerformanceReadings(metricType)
+}
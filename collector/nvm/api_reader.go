@@ -11,57 +11,201 @@ package nvm
 
 import (
 	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
 )
 
 const NumberOfAvailableSensors = 10
 
 type device struct {
-	uid               nvmUID
-	discovery         deviceDiscovery
-	sensors           [NumberOfAvailableSensors]sensor
-	performance       devicePerformance
-	performanceOpstat nvmStatusCodeEnumAttr
-	sensorsOpstat     [NumberOfAvailableSensors]nvmStatusCodeEnumAttr
+	uid                  nvmUID
+	discovery            deviceDiscovery
+	sensors              [NumberOfAvailableSensors]sensor
+	performance          devicePerformance
+	performanceOpstat    nvmStatusCodeEnumAttr
+	sensorsOpstat        [NumberOfAvailableSensors]nvmStatusCodeEnumAttr
+	status               deviceStatus
+	statusOpstat         nvmStatusCodeEnumAttr
+	fwInfo               deviceFWInfo
+	fwInfoOpstat         nvmStatusCodeEnumAttr
+	capacities           deviceCapacities
+	capacitiesOpstat     nvmStatusCodeEnumAttr
+	errorLogStatus       deviceErrorLogStatus
+	errorLogStatusOpstat nvmStatusCodeEnumAttr
+	pmon                 pmonRegisters
+	pmonOpstat           nvmStatusCodeEnumAttr
+	settings             deviceSettings
+	settingsOpstat       nvmStatusCodeEnumAttr
+	details              deviceDetails
+	detailsOpstat        nvmStatusCodeEnumAttr
+	// firmware error log entries fetched in the current reading cycle
+	errorLogEntries []errorLogEntry
+	// physical location of the device, empty if location labels are disabled
+	location string
+}
+
+// platform holds readings, which describe the whole system rather than any
+// single device
+type platform struct {
+	regions              []region
+	regionsOpstat        nvmStatusCodeEnumAttr
+	sockets              []socket
+	socketsOpstat        nvmStatusCodeEnumAttr
+	topology             []memoryTopology
+	topologyOpstat       nvmStatusCodeEnumAttr
+	host                 host
+	hostOpstat           nvmStatusCodeEnumAttr
+	swInventory          swInventory
+	swInventoryOpstat    nvmStatusCodeEnumAttr
+	libraryVersion       libraryVersion
+	libraryVersionOpstat nvmStatusCodeEnumAttr
+	capabilities         nvmCapabilities
+	capabilitiesOpstat   nvmStatusCodeEnumAttr
+	// events logged since the previous reading cycle
+	events       []event
+	eventsOpstat nvmStatusCodeEnumAttr
 }
 
 type MetricsReader struct {
-	deviceCount nvmUint8
-	devices     []device
+	backend        Backend
+	deviceCount    nvmUint8
+	devices        []device
+	platform       platform
+	recorder       *Recorder
+	throttle       map[nvmUID]*throttleHistory
+	events         *eventHistory
+	errorLogs      *errorLogHistory
+	locationLabels bool
+	// serializes calls to the backend, which is shared with EventForwarder
+	// polling the event log in the background (libipmctl is not thread
+	// safe)
+	backendLock sync.Mutex
 }
 
-func NewMetricsReader() *MetricsReader {
-	opstat, count, _ := GetNumberOfDevices()
-	if nvmStatusCodeEnum.nvmSuccess != opstat {
-		return &MetricsReader{
-			deviceCount: 0,
-			devices:     make([]device, 0),
-		}
+// addLocationLabel attaches location of the device (e.g.
+// CPU0_IMC1_CH0_DIMM1) to the labels of per device reading, if location
+// labels are enabled
+func (dev *device) addLocationLabel(labels Labels) {
+	if dev.location != "" {
+		labels.addLabel("location", dev.location)
 	}
+}
+
+// NewMetricsReader creates reader taking all the readings from given
+// backend, with locationLabels enabled location label is attached to all per
+// device metrics (sensors, sensor settings, performance, PMON, status,
+// settings, details, firmware, capacities, firmware error log)
+func NewMetricsReader(backend Backend, locationLabels bool) *MetricsReader {
 	return &MetricsReader{
-		deviceCount: count,
-		devices:     make([]device, count),
+		backend:        backend,
+		deviceCount:    0,
+		devices:        make([]device, 0),
+		throttle:       make(map[nvmUID]*throttleHistory),
+		events:         newEventHistory(),
+		errorLogs:      newErrorLogHistory(),
+		locationLabels: locationLabels,
 	}
 }
 
-func (reader *MetricsReader) GetRequiredReadings() (bool, error) {
-	if 0 == reader.deviceCount {
-		opstat, count, _ := GetNumberOfDevices()
-		if nvmStatusCodeEnum.nvmSuccess != opstat {
-			return false, fmt.Errorf("Unable to get number of NVM devices")
-		}
-		reader.deviceCount = count
+// LabelNames returns names of the labels of per device metric described by
+// given names, location label is appended if it is enabled
+func (reader *MetricsReader) LabelNames(names []string) []string {
+	result := append([]string{}, names...)
+	if reader.locationLabels {
+		result = append(result, "location")
 	}
+	return result
+}
+
+// readingsFromDetails tells if capacities and sensor readings of the device
+// are taken from its details, it holds when the details were read from
+// backend embedding these readings
+func (reader *MetricsReader) readingsFromDetails(dev *device) bool {
+	detailed, embeds := reader.backend.(detailedBackend)
+	return embeds && detailed.embedsDetailsReadings() && nvmStatusCodeEnum.nvmSuccess == dev.detailsOpstat
+}
 
-	opstat, discoveries, err := GetDevices(reader.deviceCount)
+// readEvents reads the whole event log of the backend, on behalf of
+// EventForwarder, while no reading cycle is in progress
+func (reader *MetricsReader) readEvents() (nvmStatusCodeEnumAttr, []event, error) {
+	reader.backendLock.Lock()
+	defer reader.backendLock.Unlock()
+	return reader.backend.GetEvents()
+}
+
+// SetRecorder makes reader save all raw readings of every reading cycle
+// with the use of given recorder, nil disables recording
+func (reader *MetricsReader) SetRecorder(recorder *Recorder) {
+	reader.recorder = recorder
+}
+
+func (reader *MetricsReader) GetRequiredReadings() (bool, error) {
+	reader.backendLock.Lock()
+	defer reader.backendLock.Unlock()
+	backend := reader.backend
+	opstat, count, _ := backend.GetNumberOfDevices()
+	if nvmStatusCodeEnum.nvmSuccess != opstat {
+		return false, fmt.Errorf("Unable to get number of NVM devices")
+	}
+	opstat, discoveries, err := backend.GetDevices(count)
 	if nvmStatusCodeEnum.nvmSuccess != opstat {
 		return false, err
 	}
-	for i := 0; i < int(reader.deviceCount); i++ {
-		reader.devices[i].uid = discoveries[i].uid
-		reader.devices[i].discovery = discoveries[i]
-		reader.devices[i].performanceOpstat, reader.devices[i].performance, _ = GetDevicePerformance(discoveries[i].uid)
-		for j := sensorTypeEnum.sensorHealth; j < NumberOfAvailableSensors; j++ {
-			reader.devices[i].sensorsOpstat[j], reader.devices[i].sensors[j], _ = GetSensor(discoveries[i].uid, j)
+	// backend may return less devices than it reported, only the ones
+	// discovered are read
+	if len(discoveries) != len(reader.devices) {
+		reader.devices = make([]device, len(discoveries))
+	}
+	reader.deviceCount = nvmUint8(len(discoveries))
+	for i := range discoveries {
+		dev := &reader.devices[i]
+		dev.uid = discoveries[i].uid
+		dev.discovery = discoveries[i]
+		dev.location = ""
+		if reader.locationLabels {
+			dev.location = dev.discovery.deviceHandle.toParts().toLocation()
+		}
+		dev.performanceOpstat, dev.performance, _ = backend.GetDevicePerformance(dev.uid)
+		dev.statusOpstat, dev.status, _ = backend.GetDeviceStatus(dev.uid)
+		dev.fwInfoOpstat, dev.fwInfo, _ = backend.GetDeviceFwImageInfo(dev.uid)
+		dev.errorLogStatusOpstat, dev.errorLogStatus, _ = backend.GetFwErrLogStats(dev.uid)
+		dev.pmonOpstat, dev.pmon, _ = backend.GetPMONRegisters(dev.uid)
+		dev.settingsOpstat, dev.settings, _ = backend.GetDeviceSettings(dev.uid)
+		dev.detailsOpstat, dev.details, _ = backend.GetDeviceDetails(dev.uid)
+		if reader.readingsFromDetails(dev) {
+			// sensors embedded in the details are indexed by sensor type
+			dev.capacitiesOpstat, dev.capacities = dev.detailsOpstat, dev.details.capacities
+			for j := sensorTypeEnum.sensorHealth; j < NumberOfAvailableSensors; j++ {
+				dev.sensorsOpstat[j], dev.sensors[j] = dev.detailsOpstat, dev.details.sensors[j]
+			}
+		} else {
+			dev.capacitiesOpstat, dev.capacities, _ = backend.GetDeviceCapacities(dev.uid)
+			for j := sensorTypeEnum.sensorHealth; j < NumberOfAvailableSensors; j++ {
+				dev.sensorsOpstat[j], dev.sensors[j], _ = backend.GetSensor(dev.uid, j)
+			}
+		}
+	}
+	reader.platform.regionsOpstat, reader.platform.regions, _ = backend.GetRegions()
+	reader.platform.socketsOpstat, reader.platform.sockets, _ = backend.GetSockets()
+	reader.platform.topologyOpstat, reader.platform.topology, _ = backend.GetMemoryTopology()
+	reader.platform.hostOpstat, reader.platform.host, _ = backend.GetHost()
+	reader.platform.swInventoryOpstat, reader.platform.swInventory, _ = backend.GetSwInventory()
+	reader.platform.libraryVersionOpstat, reader.platform.libraryVersion, _ = backend.GetLibraryVersion()
+	reader.platform.capabilitiesOpstat, reader.platform.capabilities, _ = backend.GetNVMCapabilities()
+	opstat, events, _ := backend.GetEvents()
+	reader.platform.eventsOpstat = opstat
+	reader.platform.events = nil
+	if nvmStatusCodeEnum.nvmSuccess == opstat {
+		reader.platform.events = reader.events.update(events)
+	}
+	now := time.Now()
+	reader.updateThrottleHistory(now)
+	reader.updateErrorLogHistory()
+	if reader.recorder != nil {
+		if err := reader.recorder.record(now, reader.devices, reader.platform); err != nil {
+			log.Error("ipmctl exporter - failed to record readings due to: ", err)
 		}
 	}
 	return true, nil
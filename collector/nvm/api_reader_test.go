@@ -0,0 +1,245 @@
+/**
+ * Copyright (c) 2020-2021, Intel Corporation.
+ * SPDX-License-Identifier: BSD-3-Clause
+ **
+ * api_reader_test.go file drives MetricsReader with stubBackend, which
+ * returns fixed readings without libipmctl or DCPMM hardware present.
+ */
+
+package nvm
+
+import (
+	"fmt"
+	"testing"
+)
+
+// stubBackend reports given number of devices, but discovers only the ones
+// listed, every device gets the same sensor readings
+type stubBackend struct {
+	unsupportedBackend
+	count       nvmUint8
+	discoveries []deviceDiscovery
+	sensors     map[sensorTypeEnumAttr]sensor
+}
+
+func (backend *stubBackend) GetNumberOfDevices() (nvmStatusCodeEnumAttr, nvmUint8, error) {
+	return nvmStatusCodeEnum.nvmSuccess, backend.count, nil
+}
+
+func (backend *stubBackend) GetDevices(count nvmUint8) (nvmStatusCodeEnumAttr, []deviceDiscovery, error) {
+	return nvmStatusCodeEnum.nvmSuccess, backend.discoveries, nil
+}
+
+func (backend *stubBackend) GetSensor(deviceUID nvmUID,
+	stype sensorTypeEnumAttr) (nvmStatusCodeEnumAttr, sensor, error) {
+	if result, found := backend.sensors[stype]; found {
+		return nvmStatusCodeEnum.nvmSuccess, result, nil
+	}
+	return backend.unsupportedBackend.GetSensor(deviceUID, stype)
+}
+
+func newStubBackend(count nvmUint8, uids ...nvmUID) *stubBackend {
+	backend := &stubBackend{
+		count: count,
+		sensors: map[sensorTypeEnumAttr]sensor{
+			sensorTypeEnum.sensorMediaTemperature: {
+				stype:        sensorTypeEnum.sensorMediaTemperature,
+				units:        sensorUnitsEnum.unitCelsius,
+				currentState: sensorStatusEnum.sensorCritical,
+				reading:      85,
+			},
+		},
+	}
+	for _, uid := range uids {
+		backend.discoveries = append(backend.discoveries, deviceDiscovery{uid: uid})
+	}
+	return backend
+}
+
+func TestGetRequiredReadings(t *testing.T) {
+	reader := NewMetricsReader(newStubBackend(2, "8089-a2-1901-00001000", "8089-a2-1901-00001001"), false)
+	if status, err := reader.GetRequiredReadings(); !status {
+		t.Fatalf("GetRequiredReadings failed: %v", err)
+	}
+	readings := reader.GetMediaTemperature()
+	if len(readings) != 2 {
+		t.Fatalf("expected 2 media temperature readings, got %d", len(readings))
+	}
+	for i, reading := range readings {
+		if reading.ReadStatus != int(nvmStatusCodeEnum.nvmSuccess) || reading.MetricValue != 85 {
+			t.Errorf("reading %d: expected 85 read successfully, got %v (status %d)",
+				i, reading.MetricValue, reading.ReadStatus)
+		}
+	}
+	if uid := readings[1].Labels.GetLabelValues()[0]; uid != "8089-a2-1901-00001001" {
+		t.Errorf("expected reading of the second device, got %s", uid)
+	}
+	for _, reading := range reader.GetHealth() {
+		if reading.ReadStatus != int(nvmStatusCodeEnum.nvmErrAPINotSupported) {
+			t.Errorf("expected health not supported by backend, got status %d", reading.ReadStatus)
+		}
+	}
+}
+
+func TestGetRequiredReadingsLessDiscovered(t *testing.T) {
+	reader := NewMetricsReader(newStubBackend(3, "8089-a2-1901-00001000"), false)
+	if status, err := reader.GetRequiredReadings(); !status {
+		t.Fatalf("GetRequiredReadings failed: %v", err)
+	}
+	if readings := reader.GetMediaTemperature(); len(readings) != 1 {
+		t.Errorf("expected readings of 1 discovered device, got %d", len(readings))
+	}
+}
+
+func TestGetRequiredReadingsDeviceRemoved(t *testing.T) {
+	backend := newStubBackend(2, "8089-a2-1901-00001000", "8089-a2-1901-00001001")
+	reader := NewMetricsReader(backend, false)
+	if status, err := reader.GetRequiredReadings(); !status {
+		t.Fatalf("GetRequiredReadings failed: %v", err)
+	}
+	backend.count = 1
+	backend.discoveries = backend.discoveries[1:]
+	if status, err := reader.GetRequiredReadings(); !status {
+		t.Fatalf("GetRequiredReadings failed: %v", err)
+	}
+	readings := reader.GetMediaTemperature()
+	if len(readings) != 1 {
+		t.Fatalf("expected readings of 1 device, got %d", len(readings))
+	}
+	if uid := readings[0].Labels.GetLabelValues()[0]; uid != "8089-a2-1901-00001001" {
+		t.Errorf("expected reading of the device left, got %s", uid)
+	}
+}
+
+func TestGetRequiredReadingsNoDevices(t *testing.T) {
+	reader := NewMetricsReader(&stubBackend{}, false)
+	if status, err := reader.GetRequiredReadings(); !status {
+		t.Fatalf("GetRequiredReadings failed: %v", err)
+	}
+	if readings := reader.GetHealth(); len(readings) != 0 {
+		t.Errorf("expected no readings, got %d", len(readings))
+	}
+	reader = NewMetricsReader(&unsupportedBackend{}, false)
+	if status, _ := reader.GetRequiredReadings(); status {
+		t.Errorf("expected GetRequiredReadings to fail when number of devices is not available")
+	}
+}
+
+// detailedStubBackend counts the calls of the methods, which readings may
+// be embedded in the device details
+type detailedStubBackend struct {
+	stubBackend
+	embeds      bool
+	detailsFail bool
+	calls       map[string]int
+}
+
+func (backend *detailedStubBackend) GetSensor(deviceUID nvmUID,
+	stype sensorTypeEnumAttr) (nvmStatusCodeEnumAttr, sensor, error) {
+	backend.calls["GetSensor"]++
+	return backend.stubBackend.GetSensor(deviceUID, stype)
+}
+
+func (backend *detailedStubBackend) GetDeviceCapacities(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceCapacities, error) {
+	backend.calls["GetDeviceCapacities"]++
+	return nvmStatusCodeEnum.nvmSuccess, deviceCapacities{capacity: 1 << 30}, nil
+}
+
+func (backend *detailedStubBackend) GetDeviceDetails(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceDetails, error) {
+	backend.calls["GetDeviceDetails"]++
+	if backend.detailsFail {
+		return nvmStatusCodeEnum.nvmErrUnknown, deviceDetails{}, fmt.Errorf("Unable to get details of DIMM: %s", deviceUID)
+	}
+	details := deviceDetails{capacities: deviceCapacities{capacity: 1 << 30}}
+	details.sensors[sensorTypeEnum.sensorMediaTemperature] = backend.sensors[sensorTypeEnum.sensorMediaTemperature]
+	return nvmStatusCodeEnum.nvmSuccess, details, nil
+}
+
+func (backend *detailedStubBackend) embedsDetailsReadings() bool {
+	return backend.embeds
+}
+
+func TestGetRequiredReadingsDetails(t *testing.T) {
+	tests := []struct {
+		name        string
+		embeds      bool
+		detailsFail bool
+		expected    map[string]int
+	}{
+		{"details not embedding readings", false, false,
+			map[string]int{"GetSensor": 2 * NumberOfAvailableSensors, "GetDeviceCapacities": 2, "GetDeviceDetails": 2}},
+		{"details embedding readings", true, false,
+			map[string]int{"GetDeviceDetails": 2}},
+		// readings are taken one by one, when details are not available
+		{"details failed", true, true,
+			map[string]int{"GetSensor": 2 * NumberOfAvailableSensors, "GetDeviceCapacities": 2, "GetDeviceDetails": 2}},
+	}
+	for _, test := range tests {
+		backend := &detailedStubBackend{
+			stubBackend: *newStubBackend(2, "8089-a2-1901-00001000", "8089-a2-1901-00001001"),
+			embeds:      test.embeds,
+			detailsFail: test.detailsFail,
+			calls:       make(map[string]int),
+		}
+		reader := NewMetricsReader(backend, false)
+		if status, err := reader.GetRequiredReadings(); !status {
+			t.Fatalf("%s: GetRequiredReadings failed: %v", test.name, err)
+		}
+		for _, method := range []string{"GetSensor", "GetDeviceCapacities", "GetDeviceDetails"} {
+			if backend.calls[method] != test.expected[method] {
+				t.Errorf("%s: expected %d calls of %s, got %d", test.name, test.expected[method], method, backend.calls[method])
+			}
+		}
+		for _, reading := range reader.GetMediaTemperature() {
+			if reading.ReadStatus != int(nvmStatusCodeEnum.nvmSuccess) || reading.MetricValue != 85 {
+				t.Errorf("%s: expected media temperature 85, got %v (status %d)",
+					test.name, reading.MetricValue, reading.ReadStatus)
+			}
+		}
+		for _, reading := range reader.GetCapacities() {
+			if reading.Labels.GetLabelValues()[1] != "total" {
+				continue
+			}
+			if reading.ReadStatus != int(nvmStatusCodeEnum.nvmSuccess) || reading.MetricValue != 1<<30 {
+				t.Errorf("%s: expected capacity of 1 GiB, got %v (status %d)",
+					test.name, reading.MetricValue, reading.ReadStatus)
+			}
+		}
+	}
+}
+
+// failingStubBackend is stubBackend failing to read performance counters
+type failingStubBackend struct {
+	stubBackend
+}
+
+func (backend *failingStubBackend) GetDevicePerformance(deviceUID nvmUID) (nvmStatusCodeEnumAttr, devicePerformance, error) {
+	return nvmStatusCodeEnum.nvmErrUnknown, devicePerformance{}, fmt.Errorf("Unable to get performance of DIMM: %s", deviceUID)
+}
+
+func TestGetReadErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		backend  Backend
+		expected float64
+	}{
+		// readings not supported by stub backend are not counted
+		{"readings not supported", newStubBackend(2, "8089-a2-1901-00001000", "8089-a2-1901-00001001"), 0},
+		{"performance failed", &failingStubBackend{*newStubBackend(2, "8089-a2-1901-00001000", "8089-a2-1901-00001001")}, 1},
+	}
+	for _, test := range tests {
+		reader := NewMetricsReader(test.backend, false)
+		if status, err := reader.GetRequiredReadings(); !status {
+			t.Fatalf("%s: GetRequiredReadings failed: %v", test.name, err)
+		}
+		readings := reader.GetReadErrors()
+		if len(readings) != 2 {
+			t.Fatalf("%s: expected read errors of 2 devices, got %d", test.name, len(readings))
+		}
+		for _, reading := range readings {
+			if reading.ReadStatus != int(nvmStatusCodeEnum.nvmSuccess) || reading.MetricValue != test.expected {
+				t.Errorf("%s: expected %v read errors, got %v", test.name, test.expected, reading.MetricValue)
+			}
+		}
+	}
+}
@@ -0,0 +1,71 @@
+/**
+ * Copyright (c) 2020-2021, Intel Corporation.
+ * SPDX-License-Identifier: BSD-3-Clause
+ **
+ * This package introduces wrapper for ipmctl library written in C.
+ * api_readerrors.go file exposes external API for exporter to collect
+ * the number of readings of every DCPMM, which failed. Failed readings are
+ * not exported at all, so their metrics disappear instead.
+ */
+
+package nvm
+
+var ReadErrorsLabelNames = []string{
+	"uid",
+}
+
+type readErrorsReading MetricReading
+type readErrorsLabels MetricLabels
+
+func (rl readErrorsLabels) GetLabelValues() []string {
+	return getValuesByName(ReadErrorsLabelNames, MetricLabels(rl).labels)
+}
+
+func (rl readErrorsLabels) GetLabelNames() []string {
+	return getNamesByLabels(ReadErrorsLabelNames, MetricLabels(rl).labels)
+}
+
+func (rl readErrorsLabels) addLabel(name string, value string) {
+	MetricLabels(rl).labels[name] = value
+}
+
+// opstats returns statuses of all the readings of the device taken in the
+// current reading cycle
+func (dev *device) opstats() []nvmStatusCodeEnumAttr {
+	opstats := []nvmStatusCodeEnumAttr{
+		dev.performanceOpstat,
+		dev.statusOpstat,
+		dev.fwInfoOpstat,
+		dev.capacitiesOpstat,
+		dev.errorLogStatusOpstat,
+		dev.pmonOpstat,
+		dev.settingsOpstat,
+		dev.detailsOpstat,
+	}
+	return append(opstats, dev.sensorsOpstat[:]...)
+}
+
+// Number of readings of the DCPMM, which failed in the last reading cycle,
+// readings not supported by the backend are not counted
+func (reader *MetricsReader) GetReadErrors() []MetricReading {
+	results := make([]MetricReading, reader.deviceCount)
+	for i := range reader.devices {
+		dev := &reader.devices[i]
+		failed := 0
+		for _, opstat := range dev.opstats() {
+			if nvmStatusCodeEnum.nvmSuccess != opstat && nvmStatusCodeEnum.nvmErrAPINotSupported != opstat {
+				failed++
+			}
+		}
+		readErrorsReading := readErrorsReading{
+			DIMMUID:     string(dev.uid),
+			ReadStatus:  int(nvmStatusCodeEnum.nvmSuccess),
+			MetricValue: float64(failed),
+			Labels:      readErrorsLabels(*newMetricLabels()),
+		}
+		readErrorsReading.Labels.addLabel("uid", string(dev.uid))
+		dev.addLocationLabel(readErrorsReading.Labels)
+		results[i] = MetricReading(readErrorsReading)
+	}
+	return results
+}
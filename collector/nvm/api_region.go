@@ -0,0 +1,240 @@
+/**
+ * Copyright (c) 2020-2021, Intel Corporation.
+ * SPDX-License-Identifier: BSD-3-Clause
+ **
+ * This package introduces wrapper for ipmctl library written in C.
+ * api_region.go file exposes external API for exporter to collect
+ * persistent memory regions (interleave sets) metrics. Regions identify
+ * their member DCPMMs by DIMM IDs (SMBIOS handles, reported as physical_id
+ * of the device), which are translated to device UIDs.
+ */
+
+package nvm
+
+import (
+	"sort"
+	"strings"
+)
+
+var RegionLabelNames = []string{
+	"iset_id",
+}
+
+var RegionInfoLabelNames = []string{
+	"iset_id",
+	"type",
+	"socket_id",
+	"dimm_uids",
+}
+
+var RegionMemberLabelNames = []string{
+	"iset_id",
+	"uid",
+}
+
+var RegionStateLabelNames = []string{
+	"iset_id",
+	"state",
+}
+
+var regionMetricTypeEnum = &regionMetricType{
+	info:         0,
+	member:       1,
+	capacity:     2,
+	freeCapacity: 3,
+	health:       4,
+	unknown:      0xFF,
+}
+
+type regionReading MetricReading
+type regionLabels MetricLabels
+type regionInfoLabels MetricLabels
+type regionMemberLabels MetricLabels
+type regionStateLabels MetricLabels
+type regionMetricTypeEnumAttr enumAttr
+type regionMetricType struct {
+	info         regionMetricTypeEnumAttr
+	member       regionMetricTypeEnumAttr
+	capacity     regionMetricTypeEnumAttr
+	freeCapacity regionMetricTypeEnumAttr
+	health       regionMetricTypeEnumAttr
+	unknown      regionMetricTypeEnumAttr
+}
+
+func (rl regionLabels) GetLabelValues() []string {
+	return getValuesByName(RegionLabelNames, MetricLabels(rl).labels)
+}
+
+func (rl regionLabels) GetLabelNames() []string {
+	return RegionLabelNames
+}
+
+func (rl regionLabels) addLabel(name string, value string) {
+	MetricLabels(rl).labels[name] = value
+}
+
+func (rl regionInfoLabels) GetLabelValues() []string {
+	return getValuesByName(RegionInfoLabelNames, MetricLabels(rl).labels)
+}
+
+func (rl regionInfoLabels) GetLabelNames() []string {
+	return RegionInfoLabelNames
+}
+
+func (rl regionInfoLabels) addLabel(name string, value string) {
+	MetricLabels(rl).labels[name] = value
+}
+
+func (rl regionMemberLabels) GetLabelValues() []string {
+	return getValuesByName(RegionMemberLabelNames, MetricLabels(rl).labels)
+}
+
+func (rl regionMemberLabels) GetLabelNames() []string {
+	return RegionMemberLabelNames
+}
+
+func (rl regionMemberLabels) addLabel(name string, value string) {
+	MetricLabels(rl).labels[name] = value
+}
+
+func (rl regionStateLabels) GetLabelValues() []string {
+	return getValuesByName(RegionStateLabelNames, MetricLabels(rl).labels)
+}
+
+func (rl regionStateLabels) GetLabelNames() []string {
+	return RegionStateLabelNames
+}
+
+func (rl regionStateLabels) addLabel(name string, value string) {
+	MetricLabels(rl).labels[name] = value
+}
+
+// memberIDs returns DIMM IDs of all DCPMMs the region is made of
+func (r *region) memberIDs() []nvmUint16 {
+	count := int(r.dimmCount)
+	if count > len(r.dimms) {
+		count = len(r.dimms)
+	}
+	return r.dimms[:count]
+}
+
+// memberUIDs returns UIDs of all DCPMMs the region is made of, DIMM ID is
+// reported instead of UID for the devices not known to the reader
+func (reader *MetricsReader) memberUIDs(r *region) []string {
+	uids := make([]string, 0, r.dimmCount)
+	for _, dimmID := range r.memberIDs() {
+		uid := dimmID.toString(16)
+		for _, dev := range reader.devices {
+			if dev.discovery.physicalID == dimmID {
+				uid = string(dev.uid)
+				break
+			}
+		}
+		uids = append(uids, uid)
+	}
+	sort.Strings(uids)
+	return uids
+}
+
+func newRegionReading(r *region,
+	readStatus nvmStatusCodeEnumAttr,
+	metricType regionMetricTypeEnumAttr,
+	metricValue nvmUint64,
+	labels Labels) *regionReading {
+	regionReading := new(regionReading)
+	regionReading.ReadStatus = int(readStatus)
+	regionReading.MetricType = uint8(metricType)
+	regionReading.MetricValue = float64(metricValue)
+	regionReading.Labels = labels
+	regionReading.Labels.addLabel("iset_id", r.isetId.toString(16))
+	return regionReading
+}
+
+// Describes persistent memory region, its type, socket and member DCPMMs
+func (reader *MetricsReader) GetRegionInfo() []MetricReading {
+	platform := reader.platform
+	results := make([]MetricReading, len(platform.regions))
+	for i := range platform.regions {
+		r := &platform.regions[i]
+		regionReading := *newRegionReading(r, platform.regionsOpstat,
+			regionMetricTypeEnum.info, 1, regionInfoLabels(*newMetricLabels()))
+		regionReading.Labels.addLabel("type", getRegionTypeName(r.rtype))
+		regionReading.Labels.addLabel("socket_id", r.socketID.toString(16))
+		regionReading.Labels.addLabel("dimm_uids", strings.Join(reader.memberUIDs(r), ","))
+		results[i] = MetricReading(regionReading)
+	}
+	return results
+}
+
+// Links persistent memory region with every of its member DCPMMs
+func (reader *MetricsReader) GetRegionMembers() []MetricReading {
+	platform := reader.platform
+	results := make([]MetricReading, 0)
+	for i := range platform.regions {
+		r := &platform.regions[i]
+		for _, uid := range reader.memberUIDs(r) {
+			regionReading := *newRegionReading(r, platform.regionsOpstat,
+				regionMetricTypeEnum.member, 1, regionMemberLabels(*newMetricLabels()))
+			regionReading.DIMMUID = uid
+			regionReading.Labels.addLabel("uid", uid)
+			results = append(results, MetricReading(regionReading))
+		}
+	}
+	return results
+}
+
+func (reader *MetricsReader) getRegionReadings(metricType regionMetricTypeEnumAttr) []MetricReading {
+	platform := reader.platform
+	results := make([]MetricReading, len(platform.regions))
+	for i := range platform.regions {
+		r := &platform.regions[i]
+		metricValue := nvmUint64(0)
+		switch metricType {
+		case regionMetricTypeEnum.capacity:
+			metricValue = r.capacity
+		case regionMetricTypeEnum.freeCapacity:
+			metricValue = r.freeCapacity
+		}
+		regionReading := *newRegionReading(r, platform.regionsOpstat,
+			metricType, metricValue, regionLabels(*newMetricLabels()))
+		results[i] = MetricReading(regionReading)
+	}
+	return results
+}
+
+// Size of the persistent memory region in bytes
+func (reader *MetricsReader) GetRegionCapacity() []MetricReading {
+	return reader.getRegionReadings(regionMetricTypeEnum.capacity)
+}
+
+// Available size of the persistent memory region in bytes
+func (reader *MetricsReader) GetRegionFreeCapacity() []MetricReading {
+	return reader.getRegionReadings(regionMetricTypeEnum.freeCapacity)
+}
+
+// Rolled up health of the DCPMMs the region is made of
+func (reader *MetricsReader) GetRegionHealth() []MetricReading {
+	states := []regionHealthEnumAttr{
+		regionHealthEnum.regionHealthNormal,
+		regionHealthEnum.regionHealthError,
+		regionHealthEnum.regionHealthUnknown,
+		regionHealthEnum.regionHealthPending,
+		regionHealthEnum.regionHealthLocked,
+	}
+	platform := reader.platform
+	results := make([]MetricReading, 0, len(platform.regions)*len(states))
+	for i := range platform.regions {
+		r := &platform.regions[i]
+		for _, state := range states {
+			metricValue := nvmUint64(0)
+			if state == r.health {
+				metricValue = 1
+			}
+			regionReading := *newRegionReading(r, platform.regionsOpstat,
+				regionMetricTypeEnum.health, metricValue, regionStateLabels(*newMetricLabels()))
+			regionReading.Labels.addLabel("state", getRegionHealthName(state))
+			results = append(results, MetricReading(regionReading))
+		}
+	}
+	return results
+}
@@ -0,0 +1,366 @@
+/**
+ * Copyright (c) 2020-2021, Intel Corporation.
+ * SPDX-License-Identifier: BSD-3-Clause
+ **
+ * This package introduces wrapper for ipmctl library written in C.
+ * api_replay.go file contains replayBackend, which plays back readings
+ * saved to a capture file by Recorder. Every reading cycle (call to
+ * GetNumberOfDevices) moves to the next frame of the capture. When the last
+ * frame is reached, its readings are served until the exporter is stopped.
+ */
+
+package nvm
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// replayFrame holds readings of a single reading cycle
+type replayFrame struct {
+	devices  []device
+	platform platform
+}
+
+// replayBackend serves readings recorded in a capture file
+type replayBackend struct {
+	unsupportedBackend
+	captureFile string
+	frames      []replayFrame
+	frame       int
+	lock        sync.Mutex
+}
+
+// NewReplayBackend creates backend playing back given capture file
+func NewReplayBackend(captureFile string) Backend {
+	return &replayBackend{captureFile: captureFile}
+}
+
+// Init loads all the frames from capture file
+func (backend *replayBackend) Init() (bool, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	file, err := os.Open(backend.captureFile)
+	if err != nil {
+		return false, fmt.Errorf("Unable to open capture file: %v", err)
+	}
+	defer file.Close()
+	frames := make([]replayFrame, 0)
+	decoder := json.NewDecoder(bufio.NewReader(file))
+	for decoder.More() {
+		var frame captureFrame
+		if err := decoder.Decode(&frame); err != nil {
+			return false, fmt.Errorf("Unable to parse frame %d of capture file: %v", len(frames), err)
+		}
+		devices := make([]device, len(frame.Devices))
+		for i, captured := range frame.Devices {
+			devices[i] = captured.toDevice()
+		}
+		frames = append(frames, replayFrame{devices, frame.Platform.toPlatform()})
+	}
+	if 0 == len(frames) {
+		return false, fmt.Errorf("Capture file %s does not contain any frame", backend.captureFile)
+	}
+	backend.frames = frames
+	backend.frame = -1
+	return true, nil
+}
+
+func (backend *replayBackend) Uninit() (bool, error) {
+	return true, nil
+}
+
+// GetNumberOfDevices moves to the next frame of the capture
+func (backend *replayBackend) GetNumberOfDevices() (nvmStatusCodeEnumAttr, nvmUint8, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	if backend.frame < len(backend.frames)-1 {
+		backend.frame++
+	}
+	return nvmStatusCodeEnum.nvmSuccess, nvmUint8(len(backend.frames[backend.frame].devices)), nil
+}
+
+func (backend *replayBackend) GetDevices(count nvmUint8) (nvmStatusCodeEnumAttr, []deviceDiscovery, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	devices := backend.frames[backend.frame].devices
+	if int(count) > len(devices) {
+		return nvmStatusCodeEnum.nvmErrInvalidParameter, nil,
+			fmt.Errorf("Requested %d devices, but only %d were captured", count, len(devices))
+	}
+	discoveries := make([]deviceDiscovery, count)
+	for i := range discoveries {
+		discoveries[i] = devices[i].discovery
+	}
+	return nvmStatusCodeEnum.nvmSuccess, discoveries, nil
+}
+
+func (backend *replayBackend) GetDeviceDiscovery(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceDiscovery, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	dev, err := backend.find(deviceUID)
+	if err != nil {
+		return nvmStatusCodeEnum.nvmErrDIMMNotFound, deviceDiscovery{}, err
+	}
+	return nvmStatusCodeEnum.nvmSuccess, dev.discovery, nil
+}
+
+func (backend *replayBackend) GetSensor(deviceUID nvmUID,
+	stype sensorTypeEnumAttr) (nvmStatusCodeEnumAttr, sensor, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	dev, err := backend.find(deviceUID)
+	if err != nil {
+		return nvmStatusCodeEnum.nvmErrDIMMNotFound, sensor{}, err
+	}
+	if stype < 0 || int(stype) >= NumberOfAvailableSensors {
+		return nvmStatusCodeEnum.nvmErrInvalidParameter, sensor{},
+			fmt.Errorf("Unknown sensor type %d", stype)
+	}
+	opstat := dev.sensorsOpstat[stype]
+	if nvmStatusCodeEnum.nvmSuccess != opstat {
+		err = fmt.Errorf("Captured sensor reading failed with status: %d", opstat)
+	}
+	return opstat, dev.sensors[stype], err
+}
+
+func (backend *replayBackend) GetDevicePerformance(deviceUID nvmUID) (nvmStatusCodeEnumAttr, devicePerformance, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	dev, err := backend.find(deviceUID)
+	if err != nil {
+		return nvmStatusCodeEnum.nvmErrDIMMNotFound, devicePerformance{}, err
+	}
+	opstat := dev.performanceOpstat
+	if nvmStatusCodeEnum.nvmSuccess != opstat {
+		err = fmt.Errorf("Captured performance reading failed with status: %d", opstat)
+	}
+	return opstat, dev.performance, err
+}
+
+func (backend *replayBackend) GetDeviceStatus(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceStatus, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	dev, err := backend.find(deviceUID)
+	if err != nil {
+		return nvmStatusCodeEnum.nvmErrDIMMNotFound, deviceStatus{}, err
+	}
+	opstat := dev.statusOpstat
+	if nvmStatusCodeEnum.nvmSuccess != opstat {
+		err = fmt.Errorf("Captured status reading failed with status: %d", opstat)
+	}
+	return opstat, dev.status, err
+}
+
+func (backend *replayBackend) GetDeviceFwImageInfo(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceFWInfo, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	dev, err := backend.find(deviceUID)
+	if err != nil {
+		return nvmStatusCodeEnum.nvmErrDIMMNotFound, deviceFWInfo{}, err
+	}
+	opstat := dev.fwInfoOpstat
+	if nvmStatusCodeEnum.nvmSuccess != opstat {
+		err = fmt.Errorf("Captured firmware image reading failed with status: %d", opstat)
+	}
+	return opstat, dev.fwInfo, err
+}
+
+func (backend *replayBackend) GetDeviceCapacities(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceCapacities, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	dev, err := backend.find(deviceUID)
+	if err != nil {
+		return nvmStatusCodeEnum.nvmErrDIMMNotFound, deviceCapacities{}, err
+	}
+	opstat := dev.capacitiesOpstat
+	if nvmStatusCodeEnum.nvmSuccess != opstat {
+		err = fmt.Errorf("Captured capacities reading failed with status: %d", opstat)
+	}
+	return opstat, dev.capacities, err
+}
+
+func (backend *replayBackend) GetDeviceSettings(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceSettings, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	dev, err := backend.find(deviceUID)
+	if err != nil {
+		return nvmStatusCodeEnum.nvmErrDIMMNotFound, deviceSettings{}, err
+	}
+	opstat := dev.settingsOpstat
+	if nvmStatusCodeEnum.nvmSuccess != opstat {
+		err = fmt.Errorf("Captured settings reading failed with status: %d", opstat)
+	}
+	return opstat, dev.settings, err
+}
+
+func (backend *replayBackend) GetDeviceDetails(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceDetails, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	dev, err := backend.find(deviceUID)
+	if err != nil {
+		return nvmStatusCodeEnum.nvmErrDIMMNotFound, deviceDetails{}, err
+	}
+	opstat := dev.detailsOpstat
+	if nvmStatusCodeEnum.nvmSuccess != opstat {
+		err = fmt.Errorf("Captured details reading failed with status: %d", opstat)
+	}
+	return opstat, dev.details, err
+}
+
+func (backend *replayBackend) GetRegions() (nvmStatusCodeEnumAttr, []region, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	platform := backend.frames[backend.frame].platform
+	var err error
+	if nvmStatusCodeEnum.nvmSuccess != platform.regionsOpstat {
+		err = fmt.Errorf("Captured regions reading failed with status: %d", platform.regionsOpstat)
+	}
+	return platform.regionsOpstat, platform.regions, err
+}
+
+func (backend *replayBackend) GetSockets() (nvmStatusCodeEnumAttr, []socket, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	platform := backend.frames[backend.frame].platform
+	var err error
+	if nvmStatusCodeEnum.nvmSuccess != platform.socketsOpstat {
+		err = fmt.Errorf("Captured sockets reading failed with status: %d", platform.socketsOpstat)
+	}
+	return platform.socketsOpstat, platform.sockets, err
+}
+
+func (backend *replayBackend) GetMemoryTopology() (nvmStatusCodeEnumAttr, []memoryTopology, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	platform := backend.frames[backend.frame].platform
+	var err error
+	if nvmStatusCodeEnum.nvmSuccess != platform.topologyOpstat {
+		err = fmt.Errorf("Captured memory topology reading failed with status: %d", platform.topologyOpstat)
+	}
+	return platform.topologyOpstat, platform.topology, err
+}
+
+func (backend *replayBackend) GetHost() (nvmStatusCodeEnumAttr, host, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	platform := backend.frames[backend.frame].platform
+	var err error
+	if nvmStatusCodeEnum.nvmSuccess != platform.hostOpstat {
+		err = fmt.Errorf("Captured host reading failed with status: %d", platform.hostOpstat)
+	}
+	return platform.hostOpstat, platform.host, err
+}
+
+func (backend *replayBackend) GetSwInventory() (nvmStatusCodeEnumAttr, swInventory, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	platform := backend.frames[backend.frame].platform
+	var err error
+	if nvmStatusCodeEnum.nvmSuccess != platform.swInventoryOpstat {
+		err = fmt.Errorf("Captured software inventory reading failed with status: %d", platform.swInventoryOpstat)
+	}
+	return platform.swInventoryOpstat, platform.swInventory, err
+}
+
+func (backend *replayBackend) GetLibraryVersion() (nvmStatusCodeEnumAttr, libraryVersion, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	platform := backend.frames[backend.frame].platform
+	var err error
+	if nvmStatusCodeEnum.nvmSuccess != platform.libraryVersionOpstat {
+		err = fmt.Errorf("Captured library version reading failed with status: %d", platform.libraryVersionOpstat)
+	}
+	return platform.libraryVersionOpstat, platform.libraryVersion, err
+}
+
+func (backend *replayBackend) GetNVMCapabilities() (nvmStatusCodeEnumAttr, nvmCapabilities, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	platform := backend.frames[backend.frame].platform
+	var err error
+	if nvmStatusCodeEnum.nvmSuccess != platform.capabilitiesOpstat {
+		err = fmt.Errorf("Captured NVM capabilities reading failed with status: %d", platform.capabilitiesOpstat)
+	}
+	return platform.capabilitiesOpstat, platform.capabilities, err
+}
+
+// GetEvents returns the events captured in the current frame, these are only
+// the events logged since the previous frame, so MetricsReader takes all of
+// them as new, even if the event log was purged during the capture
+func (backend *replayBackend) GetEvents() (nvmStatusCodeEnumAttr, []event, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	platform := backend.frames[backend.frame].platform
+	var err error
+	if nvmStatusCodeEnum.nvmSuccess != platform.eventsOpstat {
+		err = fmt.Errorf("Captured events reading failed with status: %d", platform.eventsOpstat)
+	}
+	return platform.eventsOpstat, platform.events, err
+}
+
+func (backend *replayBackend) GetPMONRegisters(deviceUID nvmUID) (nvmStatusCodeEnumAttr, pmonRegisters, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	dev, err := backend.find(deviceUID)
+	if err != nil {
+		return nvmStatusCodeEnum.nvmErrDIMMNotFound, pmonRegisters{}, err
+	}
+	opstat := dev.pmonOpstat
+	if nvmStatusCodeEnum.nvmSuccess != opstat {
+		err = fmt.Errorf("Captured PMON registers reading failed with status: %d", opstat)
+	}
+	return opstat, dev.pmon, err
+}
+
+func (backend *replayBackend) GetFwErrLogStats(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceErrorLogStatus, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	dev, err := backend.find(deviceUID)
+	if err != nil {
+		return nvmStatusCodeEnum.nvmErrDIMMNotFound, deviceErrorLogStatus{}, err
+	}
+	opstat := dev.errorLogStatusOpstat
+	if nvmStatusCodeEnum.nvmSuccess != opstat {
+		err = fmt.Errorf("Captured error log reading failed with status: %d", opstat)
+	}
+	return opstat, dev.errorLogStatus, err
+}
+
+// GetFwErrorLogEntry looks the entry up in the current frame and all the
+// frames before, as every frame holds only the entries fetched since the
+// previous one
+func (backend *replayBackend) GetFwErrorLogEntry(deviceUID nvmUID,
+	sequenceNumber nvmUint16,
+	logLevel errorLogLevelEnumAttr,
+	logType errorLogTypeEnumAttr) (nvmStatusCodeEnumAttr, errorLog, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	for frame := backend.frame; frame >= 0; frame-- {
+		for _, dev := range backend.frames[frame].devices {
+			if dev.uid != deviceUID {
+				continue
+			}
+			for _, entry := range dev.errorLogEntries {
+				if entry.logType == logType && entry.logLevel == logLevel && entry.sequenceNumber == sequenceNumber {
+					return nvmStatusCodeEnum.nvmSuccess, entry.entry, nil
+				}
+			}
+		}
+	}
+	return nvmStatusCodeEnum.nvmErrInvalidParameter, errorLog{},
+		fmt.Errorf("Error log entry %d of device %s not found in capture", sequenceNumber, deviceUID)
+}
+
+func (backend *replayBackend) find(deviceUID nvmUID) (*device, error) {
+	devices := backend.frames[backend.frame].devices
+	for i := range devices {
+		if devices[i].uid == deviceUID {
+			return &devices[i], nil
+		}
+	}
+	return nil, fmt.Errorf("Device %s not found in frame %d", deviceUID, backend.frame)
+}
@@ -0,0 +1,167 @@
+/**
+ * Copyright (c) 2020-2021, Intel Corporation.
+ * SPDX-License-Identifier: BSD-3-Clause
+ **
+ * api_replay_test.go file plays back the sample capture through
+ * MetricsReader, and checks that readings recorded by Recorder are played
+ * back by replayBackend unchanged.
+ */
+
+package nvm
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// replayedGetters are compared between the recorded and replayed readings
+var replayedGetters = map[string]func(*MetricsReader) []MetricReading{
+	"Health":                  (*MetricsReader).GetHealth,
+	"MediaTemperature":        (*MetricsReader).GetMediaTemperature,
+	"LatchedDirtyShutdowns":   (*MetricsReader).GetLatchedDirtyShutdownCount,
+	"SensorState":             (*MetricsReader).GetSensorState,
+	"MTUpperCriticalThresold": (*MetricsReader).GetMTUpperCriticalThreshold,
+	"TotalMediaReads":         (*MetricsReader).GetTotalMediaReads,
+	"MediaReads":              (*MetricsReader).GetMediaReads,
+	"PMONCounters":            (*MetricsReader).GetPMONCounters,
+	"DeviceDiscoveryInfo":     (*MetricsReader).GetDeviceDiscoveryInfo,
+	"LastShutdownStatusInfo":  (*MetricsReader).GetLastShutdownStatusInfo,
+	"ConfigStatus":            (*MetricsReader).GetConfigStatus,
+	"FirmwareInfo":            (*MetricsReader).GetFirmwareInfo,
+	"Capacities":              (*MetricsReader).GetCapacities,
+	"ViralPolicyEnabled":      (*MetricsReader).GetViralPolicyEnabled,
+	"DeviceDetailsInfo":       (*MetricsReader).GetDeviceDetailsInfo,
+	"FwErrorLogSequence":      (*MetricsReader).GetFwErrorLogSequenceNumber,
+	"FwErrorLogEntries":       (*MetricsReader).GetFwErrorLogEntries,
+	"EventCounts":             (*MetricsReader).GetEventCounts,
+	"RegionHealth":            (*MetricsReader).GetRegionHealth,
+	"MemoryTopologyInfo":      (*MetricsReader).GetMemoryTopologyInfo,
+	"HostInfo":                (*MetricsReader).GetHostInfo,
+	"PlatformCapabilities":    (*MetricsReader).GetPlatformCapabilities,
+}
+
+// readingValue returns value of the reading of the device with given UID
+func readingValue(t *testing.T, readings []MetricReading, uid string) float64 {
+	for _, reading := range readings {
+		if reading.DIMMUID == uid {
+			return reading.MetricValue
+		}
+	}
+	t.Fatalf("no reading of %s", uid)
+	return 0
+}
+
+// sortReadings orders readings by their labels, readings kept in maps (e.g.
+// event counts) are returned in random order
+func sortReadings(readings []MetricReading) []MetricReading {
+	sort.Slice(readings, func(i, j int) bool {
+		return strings.Join(readings[i].Labels.GetLabelValues(), ",") <
+			strings.Join(readings[j].Labels.GetLabelValues(), ",")
+	})
+	return readings
+}
+
+func TestReplayCapture(t *testing.T) {
+	backend := NewReplayBackend("testdata/capture.json")
+	if _, err := backend.Init(); err != nil {
+		t.Fatal(err)
+	}
+	reader := NewMetricsReader(backend, false)
+	// the DCPMM in the second channel turns critical and overheats at the
+	// second frame, the last frame is served once the capture is over
+	const uid = "8089-a2-1901-00001001"
+	expected := []struct {
+		health           float64
+		mediaTemperature float64
+		dirtyShutdowns   float64
+	}{
+		{1, 36, 0},
+		{3, 86, 1},
+		{3, 85, 1},
+		{3, 85, 1},
+	}
+	for step, want := range expected {
+		if status, err := reader.GetRequiredReadings(); !status {
+			t.Fatalf("step %d: GetRequiredReadings failed: %v", step, err)
+		}
+		if got := readingValue(t, reader.GetHealth(), uid); got != want.health {
+			t.Errorf("step %d: expected health %v, got %v", step, want.health, got)
+		}
+		if got := readingValue(t, reader.GetMediaTemperature(), uid); got != want.mediaTemperature {
+			t.Errorf("step %d: expected media temperature %v, got %v", step, want.mediaTemperature, got)
+		}
+		if got := readingValue(t, reader.GetLatchedDirtyShutdownCount(), uid); got != want.dirtyShutdowns {
+			t.Errorf("step %d: expected %v dirty shutdowns, got %v", step, want.dirtyShutdowns, got)
+		}
+		if readings := reader.GetHealth(); len(readings) != 2 {
+			t.Errorf("step %d: expected readings of 2 DCPMMs, got %d", step, len(readings))
+		}
+	}
+}
+
+func TestRecordReplay(t *testing.T) {
+	scenario := writeScenario(t, `
+topology: {sockets: 1, memory_controllers: 1, channels: 2, slots: 1, ddr4_gib: 16}
+defaults:
+  memory_mode_percent: 50
+  traffic: {media_reads: 1000, media_writes: 500, read_requests: 800, write_requests: 300}
+incidents:
+  - {at: 1, dimm: CPU0_IMC0_CH1_DIMM0, health: critical, dirty_shutdowns: 1, fw_errors: 2}
+  - {at: 2, dimm: CPU0_IMC0_CH0_DIMM0, power_cycles: 1, config_status: corrupt}
+`)
+	dir, err := ioutil.TempDir("", "ipmctl-capture")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	capture := filepath.Join(dir, "capture.json")
+
+	const steps = 4
+	take := func(reader *MetricsReader) []map[string][]MetricReading {
+		results := make([]map[string][]MetricReading, steps)
+		for step := range results {
+			if status, err := reader.GetRequiredReadings(); !status {
+				t.Fatalf("step %d: GetRequiredReadings failed: %v", step, err)
+			}
+			results[step] = make(map[string][]MetricReading)
+			for name, getter := range replayedGetters {
+				results[step][name] = sortReadings(getter(reader))
+			}
+		}
+		return results
+	}
+
+	backend := NewSimBackend(scenario)
+	if _, err := backend.Init(); err != nil {
+		t.Fatal(err)
+	}
+	recorder, err := NewRecorder(capture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader := NewMetricsReader(backend, false)
+	reader.SetRecorder(recorder)
+	recorded := take(reader)
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	backend = NewReplayBackend(capture)
+	if _, err := backend.Init(); err != nil {
+		t.Fatal(err)
+	}
+	replayed := take(NewMetricsReader(backend, false))
+	for step := range recorded {
+		for name := range replayedGetters {
+			if !reflect.DeepEqual(recorded[step][name], replayed[step][name]) {
+				t.Errorf("step %d: %s readings differ\nrecorded: %v\nreplayed: %v",
+					step, name, recorded[step][name], replayed[step][name])
+			}
+		}
+	}
+}
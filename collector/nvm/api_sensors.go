@@ -4,7 +4,9 @@
  **
  * This package introduces wrapper for ipmctl library written in C.
  * api_sensor.go file expose external API for exporter to collect
- * some NVM sensor readings.
+ * some NVM sensor readings. Besides the value, every sensor carries its state
+ * as evaluated by the firmware against the thresholds and the capabilities of
+ * these thresholds (if supported and settable).
  */
 
 package nvm
@@ -13,21 +15,87 @@ var SensorLabelNames = []string{
 	"uid",
 }
 
+var SensorStateLabelNames = []string{
+	"uid",
+	"sensor",
+	"state",
+}
+
+var SensorThresholdLabelNames = []string{
+	"uid",
+	"sensor",
+	"units",
+	"threshold",
+	"supported",
+	"settable",
+}
+
+// sensors which values are evaluated against thresholds
+var thresholdSensorTypes = []sensorTypeEnumAttr{
+	sensorTypeEnum.sensorMediaTemperature,
+	sensorTypeEnum.sensorControllerTemperature,
+	sensorTypeEnum.sensorPercentageRemaining,
+}
+
 type sensorReading MetricReading
 type sensorLabels MetricLabels
+type sensorStateLabels MetricLabels
+type sensorThresholdLabels MetricLabels
+
+// sensorThreshold describes capabilities of single threshold of the sensor
+type sensorThreshold struct {
+	name      string
+	supported nvmBool
+	settable  nvmBool
+}
 
 func (sl sensorLabels) GetLabelValues() []string {
 	return getValuesByName(SensorLabelNames, MetricLabels(sl).labels)
 }
 
 func (sl sensorLabels) GetLabelNames() []string {
-	return SensorLabelNames
+	return getNamesByLabels(SensorLabelNames, MetricLabels(sl).labels)
 }
 
 func (sl sensorLabels) addLabel(name string, value string) {
 	MetricLabels(sl).labels[name] = value
 }
 
+func (sl sensorStateLabels) GetLabelValues() []string {
+	return getValuesByName(SensorStateLabelNames, MetricLabels(sl).labels)
+}
+
+func (sl sensorStateLabels) GetLabelNames() []string {
+	return getNamesByLabels(SensorStateLabelNames, MetricLabels(sl).labels)
+}
+
+func (sl sensorStateLabels) addLabel(name string, value string) {
+	MetricLabels(sl).labels[name] = value
+}
+
+func (sl sensorThresholdLabels) GetLabelValues() []string {
+	return getValuesByName(SensorThresholdLabelNames, MetricLabels(sl).labels)
+}
+
+func (sl sensorThresholdLabels) GetLabelNames() []string {
+	return getNamesByLabels(SensorThresholdLabelNames, MetricLabels(sl).labels)
+}
+
+func (sl sensorThresholdLabels) addLabel(name string, value string) {
+	MetricLabels(sl).labels[name] = value
+}
+
+func getSensorThresholds(s *sensor) []sensorThreshold {
+	return []sensorThreshold{
+		{"lower_noncritical", s.lowerNoncriticalSupport, s.lowerNoncriticalSettable},
+		{"upper_noncritical", s.upperNoncriticalSupport, s.upperNoncriticalSettable},
+		{"lower_critical", s.lowerCriticalSupport, s.lowerCriticalSettable},
+		{"upper_critical", s.upperCriticalSupport, s.upperCriticalSettable},
+		{"lower_fatal", s.lowerFatalSupport, s.lowerFatalSettable},
+		{"upper_fatal", s.upperFatalSupport, s.upperFatalSettable},
+	}
+}
+
 func newSensorReading(dimmUID nvmUID,
 	readStatus nvmStatusCodeEnumAttr,
 	sensorType sensorTypeEnumAttr,
@@ -48,6 +116,7 @@ func (reader *MetricsReader) getSensorReadings(sensorType sensorTypeEnumAttr) []
 		opstat := dev.sensorsOpstat[sensorType]
 		sensorReading := *newSensorReading(dev.uid, opstat, sensorType, sensor.reading)
 		sensorReading.Labels.addLabel("uid", string(dev.uid))
+		dev.addLocationLabel(sensorReading.Labels)
 		results[i] = MetricReading(sensorReading)
 	}
 	return results
@@ -112,3 +181,60 @@ func (reader *MetricsReader) GetUnlatchedDirtyShutdownCount() []MetricReading {
 	sensorType := sensorTypeEnum.sensorUnlachedDirtyShutdownCount
 	return reader.getSensorReadings(sensorType)
 }
+
+// State of every sensor of the DCPMM as evaluated by the firmware against the
+// thresholds, 1 for the current state
+func (reader *MetricsReader) GetSensorState() []MetricReading {
+	states := []sensorStatusEnumAttr{
+		sensorStatusEnum.sensorNormal,
+		sensorStatusEnum.sensorNoncritical,
+		sensorStatusEnum.sensorCritical,
+		sensorStatusEnum.sensorFatal,
+		sensorStatusEnum.sensorUnknown,
+	}
+	results := make([]MetricReading, 0, len(reader.devices)*NumberOfAvailableSensors*len(states))
+	for _, dev := range reader.devices {
+		for sensorType := sensorTypeEnum.sensorHealth; sensorType < NumberOfAvailableSensors; sensorType++ {
+			currentState := getSensorStateName(dev.sensors[sensorType].currentState)
+			for _, state := range states {
+				metricValue := nvmUint64(0)
+				if getSensorStateName(state) == currentState {
+					metricValue = 1
+				}
+				sensorReading := *newSensorReading(dev.uid, dev.sensorsOpstat[sensorType], sensorType, metricValue)
+				sensorReading.Labels = sensorStateLabels(*newMetricLabels())
+				sensorReading.Labels.addLabel("uid", string(dev.uid))
+				sensorReading.Labels.addLabel("sensor", getSensorTypeName(sensorType))
+				sensorReading.Labels.addLabel("state", getSensorStateName(state))
+				dev.addLocationLabel(sensorReading.Labels)
+				results = append(results, MetricReading(sensorReading))
+			}
+		}
+	}
+	return results
+}
+
+// Describes capabilities of the thresholds of media temperature, controller
+// temperature and percentage remaining sensors: if the threshold is supported
+// and settable, units are the ones of sensor value
+func (reader *MetricsReader) GetSensorThresholdInfo() []MetricReading {
+	results := make([]MetricReading, 0)
+	for _, dev := range reader.devices {
+		for _, sensorType := range thresholdSensorTypes {
+			sensor := dev.sensors[sensorType]
+			for _, threshold := range getSensorThresholds(&sensor) {
+				sensorReading := *newSensorReading(dev.uid, dev.sensorsOpstat[sensorType], sensorType, 1)
+				sensorReading.Labels = sensorThresholdLabels(*newMetricLabels())
+				sensorReading.Labels.addLabel("uid", string(dev.uid))
+				sensorReading.Labels.addLabel("sensor", getSensorTypeName(sensorType))
+				sensorReading.Labels.addLabel("units", getSensorUnitsName(sensor.units))
+				sensorReading.Labels.addLabel("threshold", threshold.name)
+				sensorReading.Labels.addLabel("supported", threshold.supported.toString(10))
+				sensorReading.Labels.addLabel("settable", threshold.settable.toString(10))
+				dev.addLocationLabel(sensorReading.Labels)
+				results = append(results, MetricReading(sensorReading))
+			}
+		}
+	}
+	return results
+}
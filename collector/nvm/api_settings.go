@@ -39,11 +39,11 @@ type sensorSettingType struct {
 }
 
 func (sl settingsLabels) GetLabelValues() []string {
-	return getValuesByName(SensorLabelNames, MetricLabels(sl).labels)
+	return getValuesByName(SettingsLabelNames, MetricLabels(sl).labels)
 }
 
 func (sl settingsLabels) GetLabelNames() []string {
-	return SensorLabelNames
+	return getNamesByLabels(SettingsLabelNames, MetricLabels(sl).labels)
 }
 
 func (sl settingsLabels) addLabel(name string, value string) {
@@ -88,6 +88,7 @@ func (reader *MetricsReader) getSensorSettingsReadings(sensorType sensorTypeEnum
 		}
 		senSettingsReading := *newSensorSettingsReading(dev.uid, opstat, sensorSettingType, sensorValue)
 		senSettingsReading.Labels.addLabel("uid", string(dev.uid))
+		dev.addLocationLabel(senSettingsReading.Labels)
 		results[i] = MetricReading(senSettingsReading)
 	}
 	return results
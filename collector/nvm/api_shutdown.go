@@ -0,0 +1,109 @@
+/**
+ * Copyright (c) 2020-2021, Intel Corporation.
+ * SPDX-License-Identifier: BSD-3-Clause
+ **
+ * This package introduces wrapper for ipmctl library written in C.
+ * api_shutdown.go file exposes external API for exporter to collect
+ * last shutdown status (LSS) of NVM devices. Both latched and unlatched
+ * LSS details reported by firmware are combined into 32 bit values: bits 7:0
+ * hold LSS details, while bits 31:8 hold LSS extended details.
+ */
+
+package nvm
+
+import (
+	"strings"
+)
+
+// lastShutdownStatusFlag describes single bit of the LSS details, name is
+// used as metric label, while description matches ipmctl output
+type lastShutdownStatusFlag struct {
+	name        string
+	description string
+	bit         uint
+}
+
+var lastShutdownStatusFlags = []lastShutdownStatusFlag{
+	{"pm_adr_command", "PM ADR Command Received", 0},
+	{"pm_s3", "PM S3 Received", 1},
+	{"pm_s5", "PM S5 Received", 2},
+	{"ddrt_power_fail_command", "DDRT Power Fail Command Received", 3},
+	{"pmic_power_fail", "PMIC 12V/DDRT 1.2V Power Loss (PLI)", 4},
+	{"pm_warm_reset", "PM Warm Reset Received", 5},
+	{"thermal_shutdown", "Thermal Shutdown Received", 6},
+	{"fw_flush_complete", "Controller's FW State Flush Complete", 7},
+	{"viral_interrupt", "Viral Interrupt Received", 8},
+	{"surprise_clock_stop_interrupt", "Surprise Clock Stop Interrupt Received", 9},
+	{"write_data_flush_complete", "Write Data Flush Complete", 10},
+	{"pm_s4", "PM S4 Received", 11},
+	{"pm_idle", "PM Idle Received", 12},
+	{"ddrt_surprise_reset", "DDRT Surprise Reset Received", 13},
+	{"extended_flush_complete", "Extended Flush Complete", 14},
+}
+
+var LastShutdownStatusLabelNames = newLastShutdownStatusLabelNames()
+
+func newLastShutdownStatusLabelNames() []string {
+	names := []string{"uid", "status", "details"}
+	for _, flag := range lastShutdownStatusFlags {
+		names = append(names, flag.name)
+	}
+	return names
+}
+
+type lastShutdownStatusLabels MetricLabels
+
+func (ll lastShutdownStatusLabels) GetLabelValues() []string {
+	return getValuesByName(LastShutdownStatusLabelNames, MetricLabels(ll).labels)
+}
+
+func (ll lastShutdownStatusLabels) GetLabelNames() []string {
+	return getNamesByLabels(LastShutdownStatusLabelNames, MetricLabels(ll).labels)
+}
+
+func (ll lastShutdownStatusLabels) addLabel(name string, value string) {
+	MetricLabels(ll).labels[name] = value
+}
+
+// parseLastShutdownStatus converts comma separated list of LSS flags printed
+// by ipmctl (e.g. "PM ADR Command Received, Controller's FW State Flush
+// Complete") to LSS details, flags not known are ignored
+func parseLastShutdownStatus(value string) nvmUint32 {
+	details := nvmUint32(0)
+	for _, description := range strings.Split(value, ",") {
+		description = strings.TrimSpace(description)
+		for _, flag := range lastShutdownStatusFlags {
+			if strings.EqualFold(description, flag.description) {
+				details |= 1 << flag.bit
+			}
+		}
+	}
+	return details
+}
+
+func newLastShutdownStatusReading(dev device, status string, details nvmUint32) MetricReading {
+	devStatusReading := *newDevStatusReading(dev.uid, dev.statusOpstat, devStatusTypeEnum.lastShutdownStatus, 1)
+	devStatusReading.Labels = lastShutdownStatusLabels(*newMetricLabels())
+	devStatusReading.Labels.addLabel("uid", string(dev.uid))
+	devStatusReading.Labels.addLabel("status", status)
+	devStatusReading.Labels.addLabel("details", details.toString(16))
+	dev.addLocationLabel(devStatusReading.Labels)
+	for _, flag := range lastShutdownStatusFlags {
+		set := nvmBool(0 != details&(1<<flag.bit))
+		devStatusReading.Labels.addLabel(flag.name, set.toString(10))
+	}
+	return MetricReading(devStatusReading)
+}
+
+// Last shutdown status details decoded into flags, both latched (status of
+// the last shutdown after which dirty shutdown count was increased) and
+// unlatched (status of the last shutdown) are reported
+func (reader *MetricsReader) GetLastShutdownStatusInfo() []MetricReading {
+	results := make([]MetricReading, 0, 2*int(reader.deviceCount))
+	for _, dev := range reader.devices {
+		results = append(results,
+			newLastShutdownStatusReading(dev, "latched", dev.status.lastShutdownStatusDetails),
+			newLastShutdownStatusReading(dev, "unlatched", dev.status.unlachedLastShutdownStatusDetails))
+	}
+	return results
+}
@@ -0,0 +1,120 @@
+/**
+ * Copyright (c) 2020-2021, Intel Corporation.
+ * SPDX-License-Identifier: BSD-3-Clause
+ **
+ * api_shutdown_test.go file tests decoding of last shutdown status (LSS)
+ * details into flag labels and parsing of LSS printed by ipmctl.
+ */
+
+package nvm
+
+import (
+	"testing"
+)
+
+// setFlags returns names of the flag labels set to 1, in the order of the
+// LSS bits
+func setFlags(t *testing.T, reading MetricReading) []string {
+	names := reading.Labels.GetLabelNames()
+	values := reading.Labels.GetLabelValues()
+	flags := make([]string, 0)
+	for i, name := range names {
+		if i < 3 {
+			continue
+		}
+		switch values[i] {
+		case "1":
+			flags = append(flags, name)
+		case "0":
+		default:
+			t.Errorf("unexpected value %q of flag %s", values[i], name)
+		}
+	}
+	return flags
+}
+
+func equalStrings(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestLastShutdownStatusInfo(t *testing.T) {
+	tests := []struct {
+		name      string
+		latched   nvmUint32
+		unlatched nvmUint32
+		details   []string
+		flags     [][]string
+	}{
+		{"no flags", 0, 0, []string{"0x0", "0x0"},
+			[][]string{{}, {}}},
+		{"clean shutdown", 0x81, 0x81, []string{"0x81", "0x81"},
+			[][]string{{"pm_adr_command", "fw_flush_complete"}, {"pm_adr_command", "fw_flush_complete"}}},
+		{"latched differs from unlatched", 0x18, 0x1009, []string{"0x18", "0x1009"},
+			[][]string{{"ddrt_power_fail_command", "pmic_power_fail"}, {"pm_adr_command", "ddrt_power_fail_command", "pm_idle"}}},
+		{"extended details", 0x7f00, 0x4400, []string{"0x7f00", "0x4400"},
+			[][]string{{"viral_interrupt", "surprise_clock_stop_interrupt", "write_data_flush_complete",
+				"pm_s4", "pm_idle", "ddrt_surprise_reset", "extended_flush_complete"},
+				{"write_data_flush_complete", "extended_flush_complete"}}},
+		// reserved bits are reported in details only
+		{"reserved bits", 0x8000, 0x10000, []string{"0x8000", "0x10000"},
+			[][]string{{}, {}}},
+	}
+	for _, test := range tests {
+		reader := NewMetricsReader(newStubBackend(0), false)
+		reader.deviceCount = 1
+		reader.devices = []device{{uid: "8089-a2-1901-00001000"}}
+		reader.devices[0].status.lastShutdownStatusDetails = test.latched
+		reader.devices[0].status.unlachedLastShutdownStatusDetails = test.unlatched
+		readings := reader.GetLastShutdownStatusInfo()
+		if len(readings) != 2 {
+			t.Fatalf("%s: expected 2 readings, got %d", test.name, len(readings))
+		}
+		for i, status := range []string{"latched", "unlatched"} {
+			values := readings[i].Labels.GetLabelValues()
+			if values[1] != status || values[2] != test.details[i] {
+				t.Errorf("%s: expected %s status with details %s, got %s with %s",
+					test.name, status, test.details[i], values[1], values[2])
+			}
+			if flags := setFlags(t, readings[i]); !equalStrings(flags, test.flags[i]) {
+				t.Errorf("%s: expected %s flags %v, got %v", test.name, status, test.flags[i], flags)
+			}
+		}
+	}
+}
+
+func TestParseLastShutdownStatus(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected nvmUint32
+	}{
+		{"", 0},
+		{"N/A", 0},
+		{"PM ADR Command Received", 0x1},
+		{"PM ADR Command Received, Controller's FW State Flush Complete", 0x81},
+		{" pm s3 received ,PM S5 Received,PM Warm Reset Received ", 0x26},
+		{"Thermal Shutdown Received, Viral Interrupt Received, Surprise Clock Stop Interrupt Received", 0x340},
+		{"PM S4 Received, DDRT Surprise Reset Received", 0x2800},
+		// flags not known are ignored
+		{"PM ADR Command Received, Sx Extended Flush Not Complete", 0x1},
+	}
+	for _, test := range tests {
+		if result := parseLastShutdownStatus(test.value); result != test.expected {
+			t.Errorf("%q: expected 0x%x, got 0x%x", test.value, test.expected, result)
+		}
+	}
+	// canned ipmctl output lists all the flags set
+	records := readCLIFixture(t, "nvmxml", "dimm.xml")
+	for _, name := range []string{"LatchedLastShutdownStatus", "UnlatchedLastShutdownStatus"} {
+		if result := parseLastShutdownStatus(records[0].get(name)); result != 0x5499 {
+			t.Errorf("%s: expected 0x5499, got 0x%x", name, result)
+		}
+	}
+}
@@ -0,0 +1,1358 @@
+/**
+ * Copyright (c) 2020-2021, Intel Corporation.
+ * SPDX-License-Identifier: BSD-3-Clause
+ **
+ * This package introduces wrapper for ipmctl library written in C.
+ * api_simulator.go file contains simBackend, which fakes a configurable
+ * topology of DCPMMs described by a YAML (or JSON) scenario file. It is meant
+ * to be used for development and CI, when neither libipmctl nor DCPMM
+ * hardware is available. Every reading cycle (call to GetNumberOfDevices)
+ * advances the simulation by one step, and scenario incidents are replayed
+ * at the steps they are scheduled for, so the same scenario file always
+ * produces the same sequence of readings.
+ */
+
+package nvm
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	simDefaultStepSeconds = 10
+	// simulated time of the first step (2020-01-01T00:00:00Z)
+	simDefaultStartTime   = 1577836800
+	simDefaultCapacityGiB = 128
+	simGiB                = 1 << 30
+	// mapped memory limit of processors supporting 4.5 TiB per socket
+	simDefaultMappedMemoryLimitGiB = 4608
+	simDefaultHostName             = "ipmctl-sim"
+	// versions of libipmctl and NVDIMM driver reported by the simulator
+	simMgmtSwRevision       = "02.00.00.3885"
+	simVendorDriverRevision = "1.11"
+	// power budgets of DCPMMs in mW
+	simPeakPowerBudget = 20000
+	simAvgPowerBudget  = 15000
+)
+
+// simTopology describes how many DCPMMs are populated in the system. Every
+// slot of every channel gets a DCPMM configured with the scenario defaults.
+type simTopology struct {
+	Sockets           int `yaml:"sockets"`
+	MemoryControllers int `yaml:"memory_controllers"`
+	Channels          int `yaml:"channels"`
+	Slots             int `yaml:"slots"`
+	// capacity of DDR4 DIMM populated in the first slot of every channel
+	// before the DCPMMs, 0 when DDR4 DIMMs are not reported
+	DDR4GiB uint64 `yaml:"ddr4_gib"`
+	// maximum memory allowed to be mapped by every socket
+	MappedMemoryLimitGiB uint64 `yaml:"mapped_memory_limit_gib"`
+}
+
+// simValue describes a gauge, which starts at given value and drifts by
+// given amount (plus random jitter) every simulation step
+type simValue struct {
+	Start  float64 `yaml:"start"`
+	Drift  float64 `yaml:"drift"`
+	Jitter float64 `yaml:"jitter"`
+	Min    float64 `yaml:"min"`
+	Max    float64 `yaml:"max"`
+}
+
+// simTraffic describes the per second rates of DCPMM performance counters
+type simTraffic struct {
+	MediaReads    float64 `yaml:"media_reads"`
+	MediaWrites   float64 `yaml:"media_writes"`
+	ReadRequests  float64 `yaml:"read_requests"`
+	WriteRequests float64 `yaml:"write_requests"`
+}
+
+// simLocation identifies a DCPMM slot in the system topology
+type simLocation struct {
+	Socket           int `yaml:"socket"`
+	MemoryController int `yaml:"memory_controller"`
+	Channel          int `yaml:"channel"`
+	Slot             int `yaml:"slot"`
+}
+
+// simDIMM is a single DCPMM entry of the scenario file
+type simDIMM struct {
+	simLocation `yaml:",inline"`
+	UID         string `yaml:"uid"`
+	CapacityGiB uint64 `yaml:"capacity_gib"`
+	// percentage of the capacity provisioned in Memory Mode, the rest of
+	// it is provisioned as App Direct
+	MemoryModePercent     uint64     `yaml:"memory_mode_percent"`
+	FwRevision            string     `yaml:"fw_revision"`
+	FwAPIVersion          string     `yaml:"fw_api_version"`
+	PartNumber            string     `yaml:"part_number"`
+	Health                string     `yaml:"health"`
+	MediaTemperature      simValue   `yaml:"media_temperature"`
+	ControllerTemperature simValue   `yaml:"controller_temperature"`
+	PercentageRemaining   simValue   `yaml:"percentage_remaining"`
+	PowerOnSeconds        uint64     `yaml:"power_on_seconds"`
+	PowerCycles           uint64     `yaml:"power_cycles"`
+	DirtyShutdowns        uint64     `yaml:"dirty_shutdowns"`
+	FwErrors              uint64     `yaml:"fw_errors"`
+	Traffic               simTraffic `yaml:"traffic"`
+	ViralPolicy           bool       `yaml:"viral_policy"`
+	PackageSparing        bool       `yaml:"package_sparing"`
+}
+
+// simIncident changes the state of matching DCPMMs at given simulation step
+type simIncident struct {
+	// simulation step (number of reading cycle counted from 0)
+	At int `yaml:"at"`
+	// DCPMM UID or location in CPU<s>_IMC<m>_CH<c>_DIMM<d> format, empty
+	// value matches all the DCPMMs
+	DIMM                  string   `yaml:"dimm"`
+	Health                string   `yaml:"health"`
+	DirtyShutdowns        uint64   `yaml:"dirty_shutdowns"`
+	PowerCycles           uint64   `yaml:"power_cycles"`
+	FwErrors              uint64   `yaml:"fw_errors"`
+	MediaTemperature      *float64 `yaml:"media_temperature"`
+	ControllerTemperature *float64 `yaml:"controller_temperature"`
+	PercentageRemaining   *float64 `yaml:"percentage_remaining"`
+	Missing               *bool    `yaml:"missing"`
+	ConfigStatus          string   `yaml:"config_status"`
+	ViralState            *bool    `yaml:"viral_state"`
+	ThermalThrottle       *uint8   `yaml:"thermal_throttle"`
+	SKUViolation          *bool    `yaml:"sku_violation"`
+	// firmware staged by the incident is activated by the next power cycle
+	// or dirty shutdown of the DCPMM
+	StagedFwRevision string `yaml:"staged_fw_revision"`
+}
+
+// simScenario is the root of the scenario file
+type simScenario struct {
+	Seed        int64  `yaml:"seed"`
+	HostName    string `yaml:"host_name"`
+	StepSeconds uint64 `yaml:"step_seconds"`
+	// unix time of the first step, simulated clock advances by StepSeconds
+	// every step
+	StartTime uint64                   `yaml:"start_time"`
+	Topology  simTopology              `yaml:"topology"`
+	Defaults  simDIMM                  `yaml:"defaults"`
+	DIMMs     []map[string]interface{} `yaml:"dimms"`
+	Incidents []simIncident            `yaml:"incidents"`
+}
+
+// simDevice keeps the current state of single simulated DCPMM
+type simDevice struct {
+	config                  simDIMM
+	discovery               deviceDiscovery
+	health                  healthStatusEnumAttr
+	missing                 bool
+	mediaTemperature        float64
+	controllerTemperature   float64
+	percentageRemaining     float64
+	powerOnTime             uint64
+	upTime                  uint64
+	powerCycles             uint64
+	latchedDirtyShutdowns   uint64
+	unlatchedDirtyShutdowns uint64
+	fwErrors                uint64
+	configStatus            configStatusEnumAttr
+	viralState              bool
+	skuViolation            bool
+	lastShutdownTime        uint64
+	latchedLSS              nvmUint32
+	unlatchedLSS            nvmUint32
+	stagedFwRevision        nvmVersion
+	fwUpdateStatus          fwUpdateStatusEnumAttr
+	thermalThrottle         uint8
+	performance             devicePerformance
+	// performance counters at the last power cycle, PMON registers count
+	// the traffic since then
+	powerCyclePerformance devicePerformance
+	mediaErrorLog         simErrorLog
+}
+
+// simErrorLog is a log of the firmware error log of simulated DCPMM, only
+// the newest simErrorLogSize entries are kept, sequence numbers start at 1
+type simErrorLog struct {
+	entries []errorLog
+	current nvmUint16
+}
+
+// last shutdown status details reported by simulated DCPMMs, clean shutdown
+// is PM S5 followed by flush of FW state and write data, dirty one is
+// power failure without any flush completed
+const (
+	simCleanShutdownLSS nvmUint32 = 1<<2 | 1<<7 | 1<<10
+	simDirtyShutdownLSS nvmUint32 = 1 << 3
+)
+
+// codes of the events logged by simulated DCPMMs, these are specific to the
+// simulator and do not match the codes used by libipmctl
+const (
+	simEventHealthChanged nvmUint16 = iota + 1
+	simEventDirtyShutdown
+	simEventViralState
+	simEventConfigChanged
+	simEventFwStaged
+	simEventFwActivated
+	simEventSKUViolation
+)
+
+// simInterleaveWays maps the number of DCPMMs interleaved to interleave ways
+// reported by simulated platform
+var simInterleaveWays = map[int]interleaveWaysEnumAttr{
+	1: interleaveWaysEnum.interleaveWays1,
+	2: interleaveWaysEnum.interleaveWays2,
+	3: interleaveWaysEnum.interleaveWays3,
+	4: interleaveWaysEnum.interleaveWays4,
+	6: interleaveWaysEnum.interleaveWays6,
+}
+
+// simEventLogSize is the number of events kept by the simulator, older ones
+// are dropped just like libipmctl rolls its event table
+const simEventLogSize = 10000
+
+// simErrorLogSize is the number of entries kept in every firmware error log
+// of simulated DCPMM
+const simErrorLogSize = 256
+
+// simBackend generates DCPMM readings based on a scenario file
+type simBackend struct {
+	unsupportedBackend
+	scenarioFile string
+	scenario     simScenario
+	devices      []*simDevice
+	random       *rand.Rand
+	step         int
+	events       []event
+	lock         sync.Mutex
+}
+
+// NewSimBackend creates backend simulating DCPMMs described by given
+// scenario file
+func NewSimBackend(scenarioFile string) Backend {
+	return &simBackend{scenarioFile: scenarioFile}
+}
+
+// Init loads the scenario file and populates simulated DCPMMs
+func (backend *simBackend) Init() (bool, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	scenario, err := loadSimScenario(backend.scenarioFile)
+	if err != nil {
+		return false, err
+	}
+	devices, err := scenario.populate()
+	if err != nil {
+		return false, err
+	}
+	backend.scenario = *scenario
+	backend.devices = devices
+	backend.random = rand.New(rand.NewSource(scenario.Seed))
+	backend.step = -1
+	backend.events = make([]event, 0)
+	return true, nil
+}
+
+func (backend *simBackend) Uninit() (bool, error) {
+	return true, nil
+}
+
+// GetNumberOfDevices advances simulation by one step and returns the number
+// of DCPMMs in the system, missing ones are still discovered
+func (backend *simBackend) GetNumberOfDevices() (nvmStatusCodeEnumAttr, nvmUint8, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	backend.step++
+	if backend.step > 0 {
+		for _, dev := range backend.devices {
+			backend.advance(dev)
+		}
+	}
+	for _, incident := range backend.scenario.Incidents {
+		if incident.At == backend.step {
+			backend.apply(incident)
+		}
+	}
+	return nvmStatusCodeEnum.nvmSuccess, nvmUint8(len(backend.devices)), nil
+}
+
+func (backend *simBackend) GetDevices(count nvmUint8) (nvmStatusCodeEnumAttr, []deviceDiscovery, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	if int(count) > len(backend.devices) {
+		return nvmStatusCodeEnum.nvmErrInvalidParameter, nil,
+			fmt.Errorf("Requested %d devices, but only %d are discovered", count, len(backend.devices))
+	}
+	discoveries := make([]deviceDiscovery, count)
+	for i := range discoveries {
+		discoveries[i] = backend.devices[i].discovery
+	}
+	return nvmStatusCodeEnum.nvmSuccess, discoveries, nil
+}
+
+func (backend *simBackend) GetDeviceDiscovery(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceDiscovery, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	dev, err := backend.find(deviceUID)
+	if err != nil {
+		return nvmStatusCodeEnum.nvmErrDIMMNotFound, deviceDiscovery{}, err
+	}
+	return nvmStatusCodeEnum.nvmSuccess, dev.discovery, nil
+}
+
+func (backend *simBackend) GetSensor(deviceUID nvmUID,
+	stype sensorTypeEnumAttr) (nvmStatusCodeEnumAttr, sensor, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	dev, err := backend.find(deviceUID)
+	if err != nil {
+		return nvmStatusCodeEnum.nvmErrDIMMNotFound, sensor{}, err
+	}
+	if dev.missing {
+		return nvmStatusCodeEnum.nvmErrSmartFailedToGetSmartInfo, sensor{},
+			fmt.Errorf("Device %s is missing", deviceUID)
+	}
+	result := newSimSensor(stype)
+	switch stype {
+	case sensorTypeEnum.sensorHealth:
+		result.reading = nvmUint64(dev.health)
+	case sensorTypeEnum.sensorMediaTemperature:
+		result.reading = nvmUint64(dev.mediaTemperature)
+	case sensorTypeEnum.sensorControllerTemperature:
+		result.reading = nvmUint64(dev.controllerTemperature)
+	case sensorTypeEnum.sensorPercentageRemaining:
+		result.reading = nvmUint64(dev.percentageRemaining)
+	case sensorTypeEnum.sensorLatchedDirtyShutdownCount:
+		result.reading = nvmUint64(dev.latchedDirtyShutdowns)
+	case sensorTypeEnum.sensorPowerontime:
+		result.reading = nvmUint64(dev.powerOnTime)
+	case sensorTypeEnum.sensorUptime:
+		result.reading = nvmUint64(dev.upTime)
+	case sensorTypeEnum.sensorPowerCycles:
+		result.reading = nvmUint64(dev.powerCycles)
+	case sensorTypeEnum.sensorFWerrorlogcount:
+		result.reading = nvmUint64(dev.fwErrors)
+	case sensorTypeEnum.sensorUnlachedDirtyShutdownCount:
+		result.reading = nvmUint64(dev.unlatchedDirtyShutdowns)
+	default:
+		return nvmStatusCodeEnum.nvmErrInvalidParameter, sensor{},
+			fmt.Errorf("Unknown sensor type %d", stype)
+	}
+	result.currentState = getSimSensorState(result)
+	return nvmStatusCodeEnum.nvmSuccess, result, nil
+}
+
+func (backend *simBackend) GetDevicePerformance(deviceUID nvmUID) (nvmStatusCodeEnumAttr, devicePerformance, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	dev, err := backend.find(deviceUID)
+	if err != nil {
+		return nvmStatusCodeEnum.nvmErrDIMMNotFound, devicePerformance{}, err
+	}
+	if dev.missing {
+		return nvmStatusCodeEnum.nvmErrFailedToGetDIMMInfo, devicePerformance{},
+			fmt.Errorf("Device %s is missing", deviceUID)
+	}
+	return nvmStatusCodeEnum.nvmSuccess, dev.performance, nil
+}
+
+func (backend *simBackend) GetDeviceStatus(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceStatus, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	dev, err := backend.find(deviceUID)
+	if err != nil {
+		return nvmStatusCodeEnum.nvmErrDIMMNotFound, deviceStatus{}, err
+	}
+	status := deviceStatus{
+		health:                             nvmUint8(dev.health),
+		isConfigured:                       nvmBool(configStatusEnum.configStatusValid == dev.configStatus),
+		isMissing:                          nvmBool(dev.missing),
+		packageSparesAvailable:             1,
+		configStatus:                       dev.configStatus,
+		lastShutdownTime:                   nvmUint64(dev.lastShutdownTime),
+		lastShutdownStatusDetails:          dev.latchedLSS,
+		unlachedLastShutdownStatusDetails:  dev.unlatchedLSS,
+		viralState:                         nvmBool(dev.viralState),
+		mixedSKU:                           nvmBool(backend.mixedSKU()),
+		skuViolation:                       nvmBool(dev.skuViolation),
+		thermalThrottlePerformanceLossPCNT: nvmUint8(dev.thermalThrottle),
+		arsStatus:                          deviceARSStatusEnum.deviceARSStatusComplete,
+		overwritedimmStatus:                deviceOverwriteDIMMStatusEnum.deviceOverwriteDIMMStatusNotstarted,
+	}
+	return nvmStatusCodeEnum.nvmSuccess, status, nil
+}
+
+func (backend *simBackend) GetDeviceFwImageInfo(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceFWInfo, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	dev, err := backend.find(deviceUID)
+	if err != nil {
+		return nvmStatusCodeEnum.nvmErrDIMMNotFound, deviceFWInfo{}, err
+	}
+	fwInfo := deviceFWInfo{
+		activeFwRevision: dev.discovery.fwRevision,
+		stagedFwRevision: dev.stagedFwRevision,
+		fwUpdateStatus:   dev.fwUpdateStatus,
+	}
+	return nvmStatusCodeEnum.nvmSuccess, fwInfo, nil
+}
+
+func (backend *simBackend) GetDeviceCapacities(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceCapacities, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	dev, err := backend.find(deviceUID)
+	if err != nil {
+		return nvmStatusCodeEnum.nvmErrDIMMNotFound, deviceCapacities{}, err
+	}
+	return nvmStatusCodeEnum.nvmSuccess, dev.capacities(), nil
+}
+
+// GetDeviceSettings reports viral policy set by the scenario, viral status
+// follows the viral state of the DCPMM
+func (backend *simBackend) GetDeviceSettings(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceSettings, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	dev, err := backend.find(deviceUID)
+	if err != nil {
+		return nvmStatusCodeEnum.nvmErrDIMMNotFound, deviceSettings{}, err
+	}
+	return nvmStatusCodeEnum.nvmSuccess, dev.settings(), nil
+}
+
+func (dev *simDevice) settings() deviceSettings {
+	return deviceSettings{
+		viralPolicy: nvmBool(dev.config.ViralPolicy),
+		viralStatus: nvmBool(dev.viralState),
+	}
+}
+
+// GetDeviceDetails reports SMBIOS data of DCPMM running at 2666 MT/s,
+// readings taken by the other methods are not embedded
+func (backend *simBackend) GetDeviceDetails(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceDetails, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	dev, err := backend.find(deviceUID)
+	if err != nil {
+		return nvmStatusCodeEnum.nvmErrDIMMNotFound, deviceDetails{}, err
+	}
+	deviceLocator, bankLabel := backend.slotLabels(dev)
+	return nvmStatusCodeEnum.nvmSuccess, deviceDetails{
+		discovery:             dev.discovery,
+		formFactor:            deviceFromFactorEnum.deviceFromFactorDIMM,
+		dataWidth:             64,
+		totalWidth:            72,
+		speed:                 2666,
+		deviceLocator:         deviceLocator,
+		bankLabel:             bankLabel,
+		peakPowerBudget:       simPeakPowerBudget,
+		avgPowerBudget:        simAvgPowerBudget,
+		packageSparingEnabled: nvmBool(dev.config.PackageSparing),
+		settings:              dev.settings(),
+	}, nil
+}
+
+// GetRegions reports single App Direct region per socket, interleaved across
+// all its DCPMMs. Region health is rolled up from the health of its members,
+// any member missing or failed puts the region into error state.
+func (backend *simBackend) GetRegions() (nvmStatusCodeEnumAttr, []region, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	regions := make([]region, 0)
+	sockets := make(map[nvmUint16]int)
+	for _, dev := range backend.devices {
+		capacities := dev.capacities()
+		if 0 == capacities.appDirectoryCapacity {
+			continue
+		}
+		socketID := dev.discovery.socketID
+		i, found := sockets[socketID]
+		if !found {
+			i = len(regions)
+			sockets[socketID] = i
+			regions = append(regions, region{
+				isetId:   nvmUint64(0x2d3c7f48f4e20000 + uint64(socketID)),
+				rtype:    regionTypeEnum.regionTypePersistent,
+				socketID: socketID,
+				health:   regionHealthEnum.regionHealthNormal,
+			})
+		}
+		r := &regions[i]
+		r.capacity += capacities.appDirectoryCapacity
+		r.freeCapacity += capacities.appDirectoryCapacity
+		if int(r.dimmCount) < len(r.dimms) {
+			r.dimms[r.dimmCount] = dev.discovery.physicalID
+			r.dimmCount++
+		}
+		switch {
+		case dev.missing,
+			healthStatusEnum.healthStatusCriticalFailure == dev.health,
+			healthStatusEnum.healthStatusFatalFailure == dev.health,
+			healthStatusEnum.healthStatusNonFunctional == dev.health:
+			r.health = regionHealthEnum.regionHealthError
+		}
+	}
+	return nvmStatusCodeEnum.nvmSuccess, regions, nil
+}
+
+// GetSockets reports every socket populated with DCPMMs. All the DDR4 and
+// DCPMM capacity of the socket is mapped, unless the socket runs in Memory
+// Mode, where DDR4 DIMMs serve as cache.
+func (backend *simBackend) GetSockets() (nvmStatusCodeEnumAttr, []socket, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	topology := backend.scenario.Topology
+	sockets := make([]socket, 0)
+	index := make(map[nvmUint16]int)
+	memoryMode := make(map[nvmUint16]bool)
+	for _, dev := range backend.devices {
+		socketID := dev.discovery.socketID
+		i, found := index[socketID]
+		if !found {
+			i = len(sockets)
+			index[socketID] = i
+			sockets = append(sockets, socket{
+				id:                socketID,
+				mappedMemoryLimit: nvmUint64(topology.MappedMemoryLimitGiB * simGiB),
+			})
+		}
+		if !dev.missing {
+			sockets[i].totalMappedMemory += dev.discovery.capacity
+		}
+		if dev.config.MemoryModePercent > 0 {
+			memoryMode[socketID] = true
+		}
+	}
+	for _, ddr4 := range backend.ddr4Locations() {
+		socketID := nvmUint16(ddr4.Socket)
+		if !memoryMode[socketID] {
+			sockets[index[socketID]].totalMappedMemory += nvmUint64(topology.DDR4GiB * simGiB)
+		}
+	}
+	return nvmStatusCodeEnum.nvmSuccess, sockets, nil
+}
+
+// GetMemoryTopology reports DDR4 DIMMs (if configured by the topology) and
+// all present DCPMMs, labeled as on Intel server boards (e.g. CPU0_DIMM_A2
+// in NODE 0 bank)
+func (backend *simBackend) GetMemoryTopology() (nvmStatusCodeEnumAttr, []memoryTopology, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	devices := make([]memoryTopology, 0)
+	for i, location := range backend.ddr4Locations() {
+		devices = append(devices, memoryTopology{
+			physicalID:    nvmUint16(0x100 + i),
+			memoryType:    memoryTypeEnum.memoryTypeDDR4,
+			deviceLocator: backend.deviceLocator(location),
+			bankLabel:     fmt.Sprintf("NODE %d", location.Socket),
+		})
+	}
+	for _, dev := range backend.present() {
+		deviceLocator, bankLabel := backend.slotLabels(dev)
+		devices = append(devices, memoryTopology{
+			physicalID:    dev.discovery.physicalID,
+			memoryType:    memoryTypeEnum.memoryTypeNVMDIMM,
+			deviceLocator: deviceLocator,
+			bankLabel:     bankLabel,
+		})
+	}
+	return nvmStatusCodeEnum.nvmSuccess, devices, nil
+}
+
+// slotLabels returns device locator and bank label of the slot the DCPMM
+// is populated in
+func (backend *simBackend) slotLabels(dev *simDevice) (string, string) {
+	location := dev.config.simLocation
+	if 0 != backend.scenario.Topology.DDR4GiB {
+		// DDR4 DIMM takes the first slot of the channel
+		location.Slot++
+	}
+	return backend.deviceLocator(location), fmt.Sprintf("NODE %d", location.Socket)
+}
+
+// ddr4Locations returns location of DDR4 DIMM in every channel populated
+// with DCPMMs
+func (backend *simBackend) ddr4Locations() []simLocation {
+	locations := make([]simLocation, 0)
+	if 0 == backend.scenario.Topology.DDR4GiB {
+		return locations
+	}
+	seen := make(map[simLocation]bool)
+	for _, dev := range backend.devices {
+		location := dev.config.simLocation
+		location.Slot = 0
+		if !seen[location] {
+			seen[location] = true
+			locations = append(locations, location)
+		}
+	}
+	return locations
+}
+
+// deviceLocator returns label of given slot, channels of all memory
+// controllers of the socket are labeled with subsequent letters and slots
+// are numbered from 1
+func (backend *simBackend) deviceLocator(location simLocation) string {
+	channels := backend.scenario.Topology.Channels
+	if location.Channel >= channels {
+		channels = location.Channel + 1
+	}
+	channel := 'A' + rune(location.MemoryController*channels+location.Channel)
+	return fmt.Sprintf("CPU%d_DIMM_%c%d", location.Socket, channel, location.Slot+1)
+}
+
+// GetHost reports the host name given by the scenario and the SKU issues of
+// present DCPMMs, the simulator always pretends to run on Linux
+func (backend *simBackend) GetHost() (nvmStatusCodeEnumAttr, host, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	result := host{
+		name:      backend.scenario.HostName,
+		osType:    osTypeEnum.osTypeLinux,
+		osName:    "Linux",
+		osVersion: "simulated",
+		mixedSku:  nvmBool(backend.mixedSKU()),
+	}
+	for _, dev := range backend.present() {
+		if dev.skuViolation {
+			result.skuViolation = true
+		}
+	}
+	return nvmStatusCodeEnum.nvmSuccess, result, nil
+}
+
+func (backend *simBackend) GetSwInventory() (nvmStatusCodeEnumAttr, swInventory, error) {
+	return nvmStatusCodeEnum.nvmSuccess, swInventory{
+		mgmtSwRevision:         simMgmtSwRevision,
+		vendorDriverRevision:   simVendorDriverRevision,
+		vendorDriverCompatible: true,
+	}, nil
+}
+
+func (backend *simBackend) GetLibraryVersion() (nvmStatusCodeEnumAttr, libraryVersion, error) {
+	return nvmStatusCodeEnum.nvmSuccess, parseLibraryVersion(simMgmtSwRevision), nil
+}
+
+// GetNVMCapabilities reports all the features of the host software except
+// error injection. Memory Mode and App Direct are supported with 4KB channel
+// and memory controller interleaving, where interleaving all the DCPMMs of
+// the socket is recommended. Memory Mode is selected by the BIOS when any of
+// the DCPMMs is provisioned in Memory Mode.
+func (backend *simBackend) GetNVMCapabilities() (nvmStatusCodeEnumAttr, nvmCapabilities, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	var capabilities nvmCapabilities
+	for _, feature := range nvmFeatureNames {
+		*feature.value(&capabilities.nvmFeatures) = true
+	}
+	capabilities.nvmFeatures.errorInjection = false
+	capabilities.swCapabilities.minNamespaceSize = simGiB
+	capabilities.swCapabilities.namespaceMemoryPageAllocationCapable = true
+	platform := &capabilities.platformCapabilities
+	platform.biosConfigSupport = true
+	platform.biosRuntimeSupport = true
+	platform.oneLMMode.supported = true
+	topology := backend.scenario.Topology
+	socketWays := topology.MemoryControllers * topology.Channels
+	for _, memory := range []*memoryCapabilities{&platform.memoryMode, &platform.appDirectMode} {
+		memory.supported = true
+		// 1 GiB alignment given as power of 2
+		memory.interleaveAlignmentSize = 30
+		for _, ways := range []int{1, 2, 3, 4, 6} {
+			memory.interleaveFormats[memory.interleaveFormatsCount] = interleaveFormat{
+				recommended: nvmBool(ways == socketWays),
+				channel:     interleaveSizeEnum.interleaveSize4kb,
+				imc:         interleaveSizeEnum.interleaveSize4kb,
+				ways:        simInterleaveWays[ways],
+			}
+			memory.interleaveFormatsCount++
+		}
+	}
+	platform.currentVolatileMode = volatileModeEnum.volatileMode1LM
+	platform.currentAppDirectMode = appDirectModeEnum.appDirectModeEnabled
+	for _, dev := range backend.present() {
+		if dev.config.MemoryModePercent > 0 {
+			platform.currentVolatileMode = volatileModeEnum.volatileModeMemory
+		}
+	}
+	capabilities.dimmSKUCapabilities = skuCapabilities{
+		mixedSKU:     nvmBool(backend.mixedSKU()),
+		memorySKU:    true,
+		appDirectSKU: true,
+	}
+	for _, dev := range backend.present() {
+		if dev.skuViolation {
+			capabilities.dimmSKUCapabilities.skuViolation = true
+		}
+	}
+	return nvmStatusCodeEnum.nvmSuccess, capabilities, nil
+}
+
+// mixedSKU indicates if present DCPMMs differ in part number or capacity
+func (backend *simBackend) mixedSKU() bool {
+	present := backend.present()
+	for _, dev := range present {
+		if dev.discovery.partNumber != present[0].discovery.partNumber ||
+			dev.discovery.capacity != present[0].discovery.capacity {
+			return true
+		}
+	}
+	return false
+}
+
+// GetEvents returns events logged by the incidents applied so far
+func (backend *simBackend) GetEvents() (nvmStatusCodeEnumAttr, []event, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	events := make([]event, len(backend.events))
+	copy(events, backend.events)
+	return nvmStatusCodeEnum.nvmSuccess, events, nil
+}
+
+// GetPMONRegisters reports the traffic since the last power cycle, no PMON
+// group is enabled on simulated DCPMMs
+func (backend *simBackend) GetPMONRegisters(deviceUID nvmUID) (nvmStatusCodeEnumAttr, pmonRegisters, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	dev, err := backend.find(deviceUID)
+	if err != nil {
+		return nvmStatusCodeEnum.nvmErrDIMMNotFound, pmonRegisters{}, err
+	}
+	if dev.missing {
+		return nvmStatusCodeEnum.nvmErrFailedToGetDIMMRegisters, pmonRegisters{},
+			fmt.Errorf("Device %s is missing", deviceUID)
+	}
+	perf, base := &dev.performance, &dev.powerCyclePerformance
+	return nvmStatusCodeEnum.nvmSuccess, pmonRegisters{
+		smartDataMask: 0x3,
+		groupEnabled:  0xF,
+		ddrtrd:        uint64(perf.hostReads - base.hostReads),
+		ddrtwr:        uint64(perf.hostWrites - base.hostWrites),
+		merd:          uint64(perf.bytesRead - base.bytesRead),
+		mewr:          uint64(perf.bytesWritten - base.bytesWritten),
+		mtp:           uint16(dev.mediaTemperature),
+		ctp:           uint16(dev.controllerTemperature),
+	}, nil
+}
+
+// GetFwErrLogStats reports sequence numbers of the firmware error log,
+// simulated DCPMMs log media errors of low priority level only
+func (backend *simBackend) GetFwErrLogStats(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceErrorLogStatus, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	dev, err := backend.find(deviceUID)
+	if err != nil {
+		return nvmStatusCodeEnum.nvmErrDIMMNotFound, deviceErrorLogStatus{}, err
+	}
+	return nvmStatusCodeEnum.nvmSuccess, deviceErrorLogStatus{mediaLow: dev.mediaErrorLog.sequenceNumbers()}, nil
+}
+
+func (backend *simBackend) GetFwErrorLogEntry(deviceUID nvmUID,
+	sequenceNumber nvmUint16,
+	logLevel errorLogLevelEnumAttr,
+	logType errorLogTypeEnumAttr) (nvmStatusCodeEnumAttr, errorLog, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	dev, err := backend.find(deviceUID)
+	if err != nil {
+		return nvmStatusCodeEnum.nvmErrDIMMNotFound, errorLog{}, err
+	}
+	log := &dev.mediaErrorLog
+	if errorLogTypeEnum.media != logType || errorLogLevelEnum.low != logLevel || 0 == len(log.entries) {
+		return nvmStatusCodeEnum.nvmSuccessNoErrorLogEntry, errorLog{}, nil
+	}
+	numbers := log.sequenceNumbers()
+	if !numbers.contains(sequenceNumber) {
+		return nvmStatusCodeEnum.nvmErrInvalidParameter, errorLog{},
+			fmt.Errorf("Error log entry %d not found", sequenceNumber)
+	}
+	return nvmStatusCodeEnum.nvmSuccess, log.entries[sequenceNumber-numbers.oldest], nil
+}
+
+// logMediaError adds an entry to the media error log of the DCPMM, as if
+// patrol scrub found an error at random address
+func (backend *simBackend) logMediaError(dev *simDevice) {
+	dpa := uint64(backend.random.Int63n(int64(dev.discovery.capacity>>8))) << 8
+	log := &dev.mediaErrorLog
+	log.current++
+	entry := errorLog{
+		dimmID:          dev.discovery.physicalID,
+		systemTimestamp: nvmUint64(dev.performance.time),
+		errorType:       1,
+	}
+	// laid out as MEDIA_ERROR_LOG structure of libipmctl
+	data := make([]byte, len(entry.outputData))
+	binary.LittleEndian.PutUint64(data[0:8], dpa)
+	data[17] = 0 // uncorrectable
+	data[19] = 1 // DPA valid
+	data[22] = 9 // patrol scrub
+	binary.LittleEndian.PutUint16(data[24:26], uint16(log.current))
+	for i, value := range data {
+		entry.outputData[i] = nvmUint8(value)
+	}
+	log.entries = append(log.entries, entry)
+	if len(log.entries) > simErrorLogSize {
+		log.entries = log.entries[len(log.entries)-simErrorLogSize:]
+	}
+}
+
+// sequenceNumbers returns oldest and current sequence number of the log
+func (log *simErrorLog) sequenceNumbers() fwErrorLogSequenceNumbers {
+	if 0 == len(log.entries) {
+		return fwErrorLogSequenceNumbers{}
+	}
+	return fwErrorLogSequenceNumbers{
+		oldest:  log.current - nvmUint16(len(log.entries)) + 1,
+		current: log.current,
+	}
+}
+
+// logEvent adds an event of the DCPMM to the event log, its time is the
+// simulated time of the DCPMM
+func (backend *simBackend) logEvent(dev *simDevice,
+	etype eventTypeEnumAttr,
+	severity eventSeverityEnumAttr,
+	code nvmUint16,
+	format string,
+	args ...interface{}) {
+	eventID := nvmUint32(1)
+	if 0 != len(backend.events) {
+		eventID = backend.events[len(backend.events)-1].eventID + 1
+	}
+	backend.events = append(backend.events, event{
+		eventID:  eventID,
+		etype:    etype,
+		severity: severity,
+		code:     code,
+		uid:      dev.discovery.uid,
+		time:     dev.performance.time,
+		message:  nvmEventMsg(fmt.Sprintf(format, args...)),
+	})
+	if len(backend.events) > simEventLogSize {
+		backend.events = backend.events[len(backend.events)-simEventLogSize:]
+	}
+}
+
+// capacities returns capacities of the DCPMM, Memory Mode capacity is
+// provisioned with GiB granularity
+func (dev *simDevice) capacities() deviceCapacities {
+	memoryCapacity := nvmUint64(dev.config.CapacityGiB * dev.config.MemoryModePercent / 100 * simGiB)
+	return deviceCapacities{
+		capacity:             dev.discovery.capacity,
+		memoryCapacity:       memoryCapacity,
+		appDirectoryCapacity: dev.discovery.capacity - memoryCapacity,
+	}
+}
+
+func (backend *simBackend) present() []*simDevice {
+	result := make([]*simDevice, 0, len(backend.devices))
+	for _, dev := range backend.devices {
+		if !dev.missing {
+			result = append(result, dev)
+		}
+	}
+	return result
+}
+
+// find returns the DCPMM of given UID, missing DCPMMs are found as well, but
+// their sensors and performance counters can not be read
+func (backend *simBackend) find(deviceUID nvmUID) (*simDevice, error) {
+	for _, dev := range backend.devices {
+		if dev.discovery.uid == deviceUID {
+			return dev, nil
+		}
+	}
+	return nil, fmt.Errorf("Device %s not found", deviceUID)
+}
+
+// advance moves the state of the DCPMM forward by one simulation step
+func (backend *simBackend) advance(dev *simDevice) {
+	seconds := backend.scenario.StepSeconds
+	dev.powerOnTime += seconds
+	dev.upTime += seconds
+	dev.mediaTemperature = backend.drift(dev.mediaTemperature, dev.config.MediaTemperature)
+	dev.controllerTemperature = backend.drift(dev.controllerTemperature, dev.config.ControllerTemperature)
+	dev.percentageRemaining = backend.drift(dev.percentageRemaining, dev.config.PercentageRemaining)
+	traffic := dev.config.Traffic
+	perf := &dev.performance
+	perf.time += timeT(seconds)
+	perf.bytesRead += backend.increment(traffic.MediaReads, seconds)
+	perf.bytesWritten += backend.increment(traffic.MediaWrites, seconds)
+	perf.hostReads += backend.increment(traffic.ReadRequests, seconds)
+	perf.hostWrites += backend.increment(traffic.WriteRequests, seconds)
+}
+
+func (backend *simBackend) drift(current float64, value simValue) float64 {
+	current += value.Drift
+	if value.Jitter > 0 {
+		current += (backend.random.Float64()*2 - 1) * value.Jitter
+	}
+	if value.Min < value.Max {
+		if current < value.Min {
+			current = value.Min
+		} else if current > value.Max {
+			current = value.Max
+		}
+	}
+	if current < 0 {
+		current = 0
+	}
+	return current
+}
+
+// increment returns the growth of a counter over given number of seconds,
+// randomized by up to 10% of the configured rate
+func (backend *simBackend) increment(rate float64, seconds uint64) nvmUint64 {
+	if rate <= 0 {
+		return 0
+	}
+	rate *= 0.9 + backend.random.Float64()*0.2
+	return nvmUint64(rate * float64(seconds))
+}
+
+// apply changes the state of all DCPMMs matched by given incident
+func (backend *simBackend) apply(incident simIncident) {
+	for _, dev := range backend.devices {
+		if incident.DIMM != "" &&
+			incident.DIMM != string(dev.discovery.uid) &&
+			!strings.EqualFold(incident.DIMM, dev.config.simLocation.String()) {
+			continue
+		}
+		if incident.Health != "" {
+			// already validated when scenario was loaded
+			health, _ := parseSimHealth(incident.Health)
+			if health != dev.health {
+				backend.logEvent(dev, eventTypeEnum.eventTypeHealth, getSimHealthSeverity(health),
+					simEventHealthChanged, "The health state of the DCPMM changed to %s", incident.Health)
+			}
+			dev.health = health
+		}
+		if incident.DirtyShutdowns > 0 {
+			backend.logEvent(dev, eventTypeEnum.eventTypeHealth, eventSeverityEnum.eventSeverityWarn,
+				simEventDirtyShutdown, "The DCPMM experienced %d dirty shutdown(s)", incident.DirtyShutdowns)
+			dev.latchedDirtyShutdowns += incident.DirtyShutdowns
+			dev.unlatchedDirtyShutdowns += incident.DirtyShutdowns
+			dev.powerCycles += incident.DirtyShutdowns
+			dev.upTime = 0
+			dev.powerCyclePerformance = dev.performance
+			dev.lastShutdownTime = uint64(dev.performance.time)
+			dev.latchedLSS = simDirtyShutdownLSS
+			dev.unlatchedLSS = simDirtyShutdownLSS
+			backend.activateStagedFw(dev)
+		}
+		if incident.PowerCycles > 0 {
+			dev.powerCycles += incident.PowerCycles
+			dev.upTime = 0
+			dev.powerCyclePerformance = dev.performance
+			dev.lastShutdownTime = uint64(dev.performance.time)
+			dev.unlatchedLSS = simCleanShutdownLSS
+			backend.activateStagedFw(dev)
+		}
+		for i := uint64(0); i < incident.FwErrors; i++ {
+			backend.logMediaError(dev)
+		}
+		dev.fwErrors += incident.FwErrors
+		if incident.MediaTemperature != nil {
+			dev.mediaTemperature = *incident.MediaTemperature
+		}
+		if incident.ControllerTemperature != nil {
+			dev.controllerTemperature = *incident.ControllerTemperature
+		}
+		if incident.PercentageRemaining != nil {
+			dev.percentageRemaining = *incident.PercentageRemaining
+		}
+		if incident.Missing != nil {
+			dev.missing = *incident.Missing
+		}
+		if incident.ConfigStatus != "" {
+			// already validated when scenario was loaded
+			configStatus, _ := parseSimConfigStatus(incident.ConfigStatus)
+			if configStatus != dev.configStatus {
+				severity := eventSeverityEnum.eventSeverityWarn
+				if configStatusEnum.configStatusValid == configStatus {
+					severity = eventSeverityEnum.eventSeverityInfo
+				}
+				backend.logEvent(dev, eventTypeEnum.eventTypeConfig, severity,
+					simEventConfigChanged, "The configuration status of the DCPMM changed to %s", incident.ConfigStatus)
+			}
+			dev.configStatus = configStatus
+		}
+		if incident.ViralState != nil {
+			if *incident.ViralState && !dev.viralState {
+				backend.logEvent(dev, eventTypeEnum.eventTypeHealth, eventSeverityEnum.eventSeverityCritical,
+					simEventViralState, "The DCPMM entered viral state")
+			}
+			dev.viralState = *incident.ViralState
+		}
+		if incident.ThermalThrottle != nil {
+			dev.thermalThrottle = *incident.ThermalThrottle
+		}
+		if incident.SKUViolation != nil {
+			if *incident.SKUViolation && !dev.skuViolation {
+				backend.logEvent(dev, eventTypeEnum.eventTypeConfig, eventSeverityEnum.eventSeverityCritical,
+					simEventSKUViolation, "The configuration of the DCPMM is not supported by its SKU")
+			}
+			dev.skuViolation = *incident.SKUViolation
+		}
+		if incident.StagedFwRevision != "" {
+			dev.stagedFwRevision = nvmVersion(incident.StagedFwRevision)
+			dev.fwUpdateStatus = fwUpdateStatusEnum.fwUpdateStaged
+			backend.logEvent(dev, eventTypeEnum.eventTypeMgmt, eventSeverityEnum.eventSeverityInfo,
+				simEventFwStaged, "Firmware %s was staged on the DCPMM", incident.StagedFwRevision)
+		}
+	}
+}
+
+// activateStagedFw makes staged firmware active, as it happens on reboot
+func (backend *simBackend) activateStagedFw(dev *simDevice) {
+	if dev.stagedFwRevision == "" {
+		return
+	}
+	backend.logEvent(dev, eventTypeEnum.eventTypeMgmt, eventSeverityEnum.eventSeverityInfo,
+		simEventFwActivated, "Firmware %s was activated on the DCPMM", dev.stagedFwRevision)
+	dev.discovery.fwRevision = dev.stagedFwRevision
+	dev.stagedFwRevision = ""
+	dev.fwUpdateStatus = fwUpdateStatusEnum.fwUpdateSuccess
+}
+
+func (location simLocation) String() string {
+	return fmt.Sprintf("CPU%d_IMC%d_CH%d_DIMM%d", location.Socket,
+		location.MemoryController, location.Channel, location.Slot)
+}
+
+// loadSimScenario reads scenario from given YAML or JSON file and fills in
+// missing values with the defaults
+func loadSimScenario(path string) (*simScenario, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read scenario file: %v", err)
+	}
+	scenario := newSimScenario()
+	if err := yaml.UnmarshalStrict(content, scenario); err != nil {
+		return nil, fmt.Errorf("Unable to parse scenario file %s: %v", path, err)
+	}
+	if 0 == scenario.StepSeconds {
+		scenario.StepSeconds = simDefaultStepSeconds
+	}
+	if 0 == scenario.StartTime {
+		scenario.StartTime = simDefaultStartTime
+	}
+	if 0 == scenario.Topology.MappedMemoryLimitGiB {
+		scenario.Topology.MappedMemoryLimitGiB = simDefaultMappedMemoryLimitGiB
+	}
+	for _, incident := range scenario.Incidents {
+		if incident.Health != "" {
+			if _, err := parseSimHealth(incident.Health); err != nil {
+				return nil, err
+			}
+		}
+		if incident.ConfigStatus != "" {
+			if _, err := parseSimConfigStatus(incident.ConfigStatus); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return scenario, nil
+}
+
+func newSimScenario() *simScenario {
+	return &simScenario{
+		Seed:        1,
+		HostName:    simDefaultHostName,
+		StepSeconds: simDefaultStepSeconds,
+		StartTime:   simDefaultStartTime,
+		Defaults: simDIMM{
+			CapacityGiB:           simDefaultCapacityGiB,
+			FwRevision:            "01.02.00.5435",
+			FwAPIVersion:          "2.1",
+			PartNumber:            "NMA1XXD128GPS",
+			Health:                "healthy",
+			MediaTemperature:      simValue{Start: 36, Jitter: 0.5, Min: 20, Max: 95},
+			ControllerTemperature: simValue{Start: 42, Jitter: 0.5, Min: 20, Max: 110},
+			PercentageRemaining:   simValue{Start: 100, Min: 0, Max: 100},
+			PackageSparing:        true,
+			Traffic: simTraffic{
+				MediaReads:    50000,
+				MediaWrites:   20000,
+				ReadRequests:  40000,
+				WriteRequests: 15000,
+			},
+		},
+	}
+}
+
+// populate creates DCPMMs for all slots in the topology, and then applies
+// per DCPMM overrides. An override replaces the DCPMM found at the same
+// location, or adds a new DCPMM when given location is not populated.
+func (scenario *simScenario) populate() ([]*simDevice, error) {
+	configs := make([]simDIMM, 0)
+	topology := scenario.Topology
+	for s := 0; s < topology.Sockets; s++ {
+		for m := 0; m < topology.MemoryControllers; m++ {
+			for c := 0; c < topology.Channels; c++ {
+				for d := 0; d < topology.Slots; d++ {
+					config := scenario.Defaults
+					config.simLocation = simLocation{s, m, c, d}
+					configs = append(configs, config)
+				}
+			}
+		}
+	}
+	for i, entry := range scenario.DIMMs {
+		// YAML library merges decoded values into existing structure, so
+		// marshal the entry back and decode it over the defaults
+		content, err := yaml.Marshal(entry)
+		if err != nil {
+			return nil, err
+		}
+		var location simLocation
+		if err := yaml.Unmarshal(content, &location); err != nil {
+			return nil, fmt.Errorf("Invalid DCPMM entry %d: %v", i, err)
+		}
+		index := len(configs)
+		for j, config := range configs {
+			if config.simLocation == location {
+				index = j
+			}
+		}
+		if index == len(configs) {
+			configs = append(configs, scenario.Defaults)
+		}
+		if err := yaml.UnmarshalStrict(content, &configs[index]); err != nil {
+			return nil, fmt.Errorf("Invalid DCPMM entry %d: %v", i, err)
+		}
+	}
+	if 0 == len(configs) {
+		return nil, fmt.Errorf("Scenario does not describe any DCPMM")
+	}
+	if len(configs) > 255 {
+		return nil, fmt.Errorf("Scenario describes too many DCPMMs: %d", len(configs))
+	}
+	devices := make([]*simDevice, len(configs))
+	for i, config := range configs {
+		dev, err := newSimDevice(config, i, scenario.StartTime)
+		if err != nil {
+			return nil, err
+		}
+		devices[i] = dev
+	}
+	return devices, nil
+}
+
+func newSimDevice(config simDIMM, index int, startTime uint64) (*simDevice, error) {
+	health, err := parseSimHealth(config.Health)
+	if err != nil {
+		return nil, err
+	}
+	// media errors are logged at random address within the capacity
+	if 0 == config.CapacityGiB {
+		return nil, fmt.Errorf("Invalid DCPMM capacity: %d GiB", config.CapacityGiB)
+	}
+	if config.MemoryModePercent > 100 {
+		return nil, fmt.Errorf("Invalid memory mode percentage: %d", config.MemoryModePercent)
+	}
+	location := config.simLocation
+	serial := uint32(0x1000 + index)
+	uid := config.UID
+	if uid == "" {
+		uid = fmt.Sprintf("8089-a2-1901-%08x", serial)
+	}
+	discovery := deviceDiscovery{
+		allPropertiesPopulated: true,
+		deviceHandle:           newSimDeviceHandle(location),
+		physicalID:             nvmUint16(0x20 + index),
+		vendorID:               0x8980,
+		deviceID:               0x979,
+		revisionID:             0x0,
+		channelPos:             nvmUint16(location.Slot),
+		channelID:              nvmUint16(location.Channel),
+		memoryControllerID:     nvmUint16(location.MemoryController),
+		socketID:               nvmUint16(location.Socket),
+		memoryType:             memoryTypeEnum.memoryTypeNVMDIMM,
+		dimmSKU:                0x1,
+		manufacturer:           nvmManufacturer{0x89, 0x80},
+		serialNumber: nvmSerialNumber{nvmUint8(serial >> 24), nvmUint8(serial >> 16),
+			nvmUint8(serial >> 8), nvmUint8(serial)},
+		subsystemVendorID:      0x8980,
+		subsystemDeviceID:      0x979,
+		subsystemRevisionID:    0x18,
+		manufacturingInfoValid: true,
+		manufacturingLocation:  0xa2,
+		manufacturingDate:      0x1901,
+		partNumber:             config.PartNumber,
+		fwRevision:             nvmVersion(config.FwRevision),
+		fwAPIVersion:           nvmVersion(config.FwAPIVersion),
+		capacity:               nvmUint64(config.CapacityGiB * simGiB),
+		securityCapabilities: deviceSecurityCapabilities{
+			passphraseCapable:   true,
+			unlockDeviceCapable: true,
+			eraseCryptoCapable:  true,
+		},
+		deviceCapabilities: deviceCapabilities{
+			packageSparingCapable: true,
+			memoryModeCapable:     true,
+			appDirectModeCapable:  true,
+		},
+		uid:                  nvmUID(uid),
+		lockState:            lockStateEnum.lockStateDisable,
+		manageability:        manageabilityStateEnum.managementValidConfig,
+		controllerRevisionID: 0x10,
+	}
+	dev := &simDevice{
+		config:                  config,
+		discovery:               discovery,
+		health:                  health,
+		mediaTemperature:        config.MediaTemperature.Start,
+		controllerTemperature:   config.ControllerTemperature.Start,
+		percentageRemaining:     config.PercentageRemaining.Start,
+		powerOnTime:             config.PowerOnSeconds,
+		powerCycles:             config.PowerCycles,
+		latchedDirtyShutdowns:   config.DirtyShutdowns,
+		unlatchedDirtyShutdowns: config.DirtyShutdowns,
+		fwErrors:                config.FwErrors,
+		configStatus:            configStatusEnum.configStatusValid,
+		latchedLSS:              simCleanShutdownLSS,
+		unlatchedLSS:            simCleanShutdownLSS,
+	}
+	dev.performance.time = timeT(startTime)
+	dev.lastShutdownTime = uint64(dev.performance.time)
+	return dev, nil
+}
+
+// newSimDeviceHandle encodes DCPMM location the same way as ACPI NFIT
+// device handle does (bits 3:0 DIMM number, 7:4 memory channel, 11:8 memory
+// controller, 15:12 socket)
+func newSimDeviceHandle(location simLocation) nvmNfitDeviceHandle {
+	value := uint32(location.Slot&0xf) |
+		uint32(location.Channel&0xf)<<4 |
+		uint32(location.MemoryController&0xf)<<8 |
+		uint32(location.Socket&0xf)<<12
+	result := make([]byte, 32)
+	for i := 0; i < 4; i++ {
+		result[i] = byte(value >> (8 * uint(i)))
+	}
+	return result
+}
+
+func parseSimHealth(name string) (healthStatusEnumAttr, error) {
+	switch strings.ToLower(name) {
+	case "", "healthy", "normal":
+		return healthStatusEnum.healthStatusHealthy, nil
+	case "noncritical":
+		return healthStatusEnum.healthStatusNonCriticalFailure, nil
+	case "critical":
+		return healthStatusEnum.healthStatusCriticalFailure, nil
+	case "fatal":
+		return healthStatusEnum.healthStatusFatalFailure, nil
+	case "unmanageable":
+		return healthStatusEnum.healthStatusUnmanageable, nil
+	case "nonfunctional":
+		return healthStatusEnum.healthStatusNonFunctional, nil
+	case "unknown":
+		return healthStatusEnum.healthStatusUnknown, nil
+	}
+	return healthStatusEnum.healthStatusUnknown, fmt.Errorf("Unknown health state: %s", name)
+}
+
+// getSimHealthSeverity returns the severity of the event logged, when the
+// DCPMM changes its health to given state
+func getSimHealthSeverity(health healthStatusEnumAttr) eventSeverityEnumAttr {
+	switch health {
+	case healthStatusEnum.healthStatusHealthy:
+		return eventSeverityEnum.eventSeverityInfo
+	case healthStatusEnum.healthStatusCriticalFailure:
+		return eventSeverityEnum.eventSeverityCritical
+	case healthStatusEnum.healthStatusFatalFailure, healthStatusEnum.healthStatusNonFunctional:
+		return eventSeverityEnum.eventSeverityFatal
+	}
+	return eventSeverityEnum.eventSeverityWarn
+}
+
+func parseSimConfigStatus(name string) (configStatusEnumAttr, error) {
+	switch strings.ToLower(name) {
+	case "valid":
+		return configStatusEnum.configStatusValid, nil
+	case "not_configured":
+		return configStatusEnum.configStatusNotConfigured, nil
+	case "corrupt":
+		return configStatusEnum.configStatusErrCorrupt, nil
+	case "broken_interleave":
+		return configStatusEnum.configStatusErrBrokenInterleave, nil
+	case "reverted":
+		return configStatusEnum.configStatusErrReverted, nil
+	case "not_supported":
+		return configStatusEnum.configStatusErrNotSupported, nil
+	case "unknown":
+		return configStatusEnum.configStatusUnknown, nil
+	}
+	return configStatusEnum.configStatusUnknown, fmt.Errorf("Unknown configuration status: %s", name)
+}
+
+// newSimSensor returns sensor of given type with units and thresholds set
+// to the values reported by DCPMMs out of the box
+func newSimSensor(stype sensorTypeEnumAttr) sensor {
+	result := sensor{stype: stype, units: sensorUnitsEnum.unitCount}
+	switch stype {
+	case sensorTypeEnum.sensorMediaTemperature:
+		result.units = sensorUnitsEnum.unitCelsius
+		result.settings = sensorSettings{
+			enabled:                   true,
+			upperNoncriticalThreshold: 82,
+			upperCriticalThreshold:    85,
+			upperFatalThreshold:       90,
+		}
+	case sensorTypeEnum.sensorControllerTemperature:
+		result.units = sensorUnitsEnum.unitCelsius
+		result.settings = sensorSettings{
+			enabled:                   true,
+			upperNoncriticalThreshold: 98,
+			upperCriticalThreshold:    103,
+			upperFatalThreshold:       110,
+		}
+	case sensorTypeEnum.sensorPercentageRemaining:
+		result.units = sensorUnitsEnum.unitPercent
+		result.settings = sensorSettings{
+			enabled:                   true,
+			lowerNoncriticalThreshold: 50,
+			lowerCriticalThreshold:    10,
+		}
+	case sensorTypeEnum.sensorPowerontime, sensorTypeEnum.sensorUptime:
+		result.units = sensorUnitsEnum.unitSeconds
+	case sensorTypeEnum.sensorPowerCycles:
+		result.units = sensorUnitsEnum.unitCycles
+	}
+	if result.settings.enabled {
+		isUpper := result.settings.upperNoncriticalThreshold != 0
+		result.upperNoncriticalSupport = nvmBool(isUpper)
+		result.upperNoncriticalSettable = nvmBool(isUpper)
+		result.upperCriticalSupport = nvmBool(isUpper)
+		result.upperFatalSupport = nvmBool(isUpper)
+		result.lowerNoncriticalSupport = nvmBool(!isUpper)
+		result.lowerNoncriticalSettable = nvmBool(!isUpper)
+		result.lowerCriticalSupport = nvmBool(!isUpper)
+	}
+	return result
+}
+
+func getSimSensorState(s sensor) sensorStatusEnumAttr {
+	settings := s.settings
+	if !settings.enabled {
+		return sensorStatusEnum.sensorNormal
+	}
+	if s.upperFatalSupport && s.reading >= settings.upperFatalThreshold {
+		return sensorStatusEnum.sensorFatal
+	}
+	if (s.upperCriticalSupport && s.reading >= settings.upperCriticalThreshold) ||
+		(s.lowerCriticalSupport && s.reading <= settings.lowerCriticalThreshold) {
+		return sensorStatusEnum.sensorCritical
+	}
+	if (s.upperNoncriticalSupport && s.reading >= settings.upperNoncriticalThreshold) ||
+		(s.lowerNoncriticalSupport && s.reading <= settings.lowerNoncriticalThreshold) {
+		return sensorStatusEnum.sensorNoncritical
+	}
+	return sensorStatusEnum.sensorNormal
+}
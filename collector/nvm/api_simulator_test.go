@@ -0,0 +1,115 @@
+/**
+ * Copyright (c) 2020-2021, Intel Corporation.
+ * SPDX-License-Identifier: BSD-3-Clause
+ **
+ * api_simulator_test.go file checks that scenarios of simBackend are
+ * reproducible, invalid ones are rejected and missing DCPMMs are discovered.
+ */
+
+package nvm
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeScenario(t *testing.T, content string) string {
+	dir, err := ioutil.TempDir("", "ipmctl-sim")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, "scenario.yaml")
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSimScenarioReproducible(t *testing.T) {
+	path := writeScenario(t, `
+topology: {sockets: 1, memory_controllers: 1, channels: 2, slots: 1}
+defaults:
+  media_temperature: {start: 40, drift: 0.1, jitter: 0.5, min: 20, max: 95}
+  traffic: {media_reads: 1000, media_writes: 500, read_requests: 800, write_requests: 300}
+incidents:
+  - {at: 2, dimm: CPU0_IMC0_CH0_DIMM0, fw_errors: 2, dirty_shutdowns: 1}
+`)
+	run := func() [][]MetricReading {
+		backend := NewSimBackend(path)
+		if _, err := backend.Init(); err != nil {
+			t.Fatal(err)
+		}
+		reader := NewMetricsReader(backend, false)
+		var results [][]MetricReading
+		for step := 0; step < 4; step++ {
+			if status, err := reader.GetRequiredReadings(); !status {
+				t.Fatal(err)
+			}
+			results = append(results, reader.GetMediaTemperature(), reader.GetPerformanceTime(),
+				reader.GetLastShutdownTime(), reader.GetFwErrorLogSequenceNumber())
+		}
+		return results
+	}
+	first := run()
+	if second := run(); !reflect.DeepEqual(first, second) {
+		t.Errorf("scenario produced different readings:\n%v\n%v", first, second)
+	}
+	if time := first[len(first)-3][0].MetricValue; time != simDefaultStartTime+3*simDefaultStepSeconds {
+		t.Errorf("expected performance time of the step 3 to be %d, got %v",
+			simDefaultStartTime+3*simDefaultStepSeconds, time)
+	}
+}
+
+func TestSimScenarioZeroCapacity(t *testing.T) {
+	path := writeScenario(t, `
+topology: {sockets: 1, memory_controllers: 1, channels: 1, slots: 1}
+defaults: {capacity_gib: 0}
+incidents:
+  - {at: 1, fw_errors: 1}
+`)
+	if _, err := NewSimBackend(path).Init(); err == nil {
+		t.Errorf("expected scenario with DCPMM of zero capacity to be rejected")
+	}
+}
+
+func TestSimScenarioMissing(t *testing.T) {
+	path := writeScenario(t, `
+topology: {sockets: 1, memory_controllers: 1, channels: 2, slots: 1}
+incidents:
+  - {at: 1, dimm: CPU0_IMC0_CH1_DIMM0, missing: true}
+`)
+	backend := NewSimBackend(path)
+	if _, err := backend.Init(); err != nil {
+		t.Fatal(err)
+	}
+	reader := NewMetricsReader(backend, false)
+	for step := 0; step < 2; step++ {
+		if status, err := reader.GetRequiredReadings(); !status {
+			t.Fatal(err)
+		}
+	}
+	// missing DCPMM is still discovered, but its sensors and performance
+	// counters can not be read
+	readings := reader.GetIsMissing()
+	if len(readings) != 2 {
+		t.Fatalf("expected 2 DCPMMs discovered, got %d", len(readings))
+	}
+	for i, expected := range []float64{0, 1} {
+		if readings[i].ReadStatus != int(nvmStatusCodeEnum.nvmSuccess) || readings[i].MetricValue != expected {
+			t.Errorf("DCPMM %d: expected missing %v, got %v (status %d)",
+				i, expected, readings[i].MetricValue, readings[i].ReadStatus)
+		}
+	}
+	for _, readings := range [][]MetricReading{reader.GetMediaTemperature(), reader.GetTotalMediaReads()} {
+		if readings[0].ReadStatus != int(nvmStatusCodeEnum.nvmSuccess) {
+			t.Errorf("expected reading of present DCPMM, got status %d", readings[0].ReadStatus)
+		}
+		if readings[1].ReadStatus == int(nvmStatusCodeEnum.nvmSuccess) {
+			t.Errorf("expected reading of missing DCPMM to fail")
+		}
+	}
+}
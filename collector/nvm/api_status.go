@@ -0,0 +1,263 @@
+/**
+ * Copyright (c) 2020-2021, Intel Corporation.
+ * SPDX-License-Identifier: BSD-3-Clause
+ **
+ * This package introduces wrapper for ipmctl library written in C.
+ * api_status.go file exposes external API for exporter to collect
+ * NVM device status metrics.
+ */
+
+package nvm
+
+var DeviceStatusLabelNames = []string{
+	"uid",
+}
+
+var DeviceStatusStateLabelNames = []string{
+	"uid",
+	"state",
+}
+
+var devStatusTypeEnum = &devStatusType{
+	isNew:                  0,
+	isConfigured:           1,
+	isMissing:              2,
+	packageSparesAvailable: 3,
+	lastShutdownTime:       4,
+	viralState:             5,
+	aitDRAMEnabled:         6,
+	bootStatus:             7,
+	injectedMediaErrors:    8,
+	injectedNonMediaErrors: 9,
+	configStatus:           10,
+	arsStatus:              11,
+	overwritedimmStatus:    12,
+	thermalThrottleLoss:    13,
+	throttleEpisodes:       14,
+	throttledTime:          15,
+	lastShutdownStatus:     16,
+	unknown:                0xFF,
+}
+
+type devStatusReading MetricReading
+type devStatusLabels MetricLabels
+type devStatusStateLabels MetricLabels
+type devStatusTypeEnumAttr enumAttr
+type devStatusType struct {
+	isNew                  devStatusTypeEnumAttr
+	isConfigured           devStatusTypeEnumAttr
+	isMissing              devStatusTypeEnumAttr
+	packageSparesAvailable devStatusTypeEnumAttr
+	lastShutdownTime       devStatusTypeEnumAttr
+	viralState             devStatusTypeEnumAttr
+	aitDRAMEnabled         devStatusTypeEnumAttr
+	bootStatus             devStatusTypeEnumAttr
+	injectedMediaErrors    devStatusTypeEnumAttr
+	injectedNonMediaErrors devStatusTypeEnumAttr
+	configStatus           devStatusTypeEnumAttr
+	arsStatus              devStatusTypeEnumAttr
+	overwritedimmStatus    devStatusTypeEnumAttr
+	thermalThrottleLoss    devStatusTypeEnumAttr
+	throttleEpisodes       devStatusTypeEnumAttr
+	throttledTime          devStatusTypeEnumAttr
+	lastShutdownStatus     devStatusTypeEnumAttr
+	unknown                devStatusTypeEnumAttr
+}
+
+func (sl devStatusLabels) GetLabelValues() []string {
+	return getValuesByName(DeviceStatusLabelNames, MetricLabels(sl).labels)
+}
+
+func (sl devStatusLabels) GetLabelNames() []string {
+	return getNamesByLabels(DeviceStatusLabelNames, MetricLabels(sl).labels)
+}
+
+func (sl devStatusLabels) addLabel(name string, value string) {
+	MetricLabels(sl).labels[name] = value
+}
+
+func (sl devStatusStateLabels) GetLabelValues() []string {
+	return getValuesByName(DeviceStatusStateLabelNames, MetricLabels(sl).labels)
+}
+
+func (sl devStatusStateLabels) GetLabelNames() []string {
+	return getNamesByLabels(DeviceStatusStateLabelNames, MetricLabels(sl).labels)
+}
+
+func (sl devStatusStateLabels) addLabel(name string, value string) {
+	MetricLabels(sl).labels[name] = value
+}
+
+func newDevStatusReading(dimmUID nvmUID,
+	readStatus nvmStatusCodeEnumAttr,
+	metricType devStatusTypeEnumAttr,
+	metricValue nvmUint64) *devStatusReading {
+	devStatusReading := new(devStatusReading)
+	devStatusReading.DIMMUID = string(dimmUID)
+	devStatusReading.ReadStatus = int(readStatus)
+	devStatusReading.MetricType = uint8(metricType)
+	devStatusReading.MetricValue = float64(metricValue)
+	devStatusReading.Labels = devStatusLabels(*newMetricLabels())
+	return devStatusReading
+}
+
+func (reader *MetricsReader) getDeviceStatusReadings(metricType devStatusTypeEnumAttr) []MetricReading {
+	results := make([]MetricReading, reader.deviceCount)
+	for i, dev := range reader.devices {
+		status := dev.status
+		opstat := dev.statusOpstat
+		metricValue := nvmUint64(0)
+		switch metricType {
+		case devStatusTypeEnum.isNew:
+			metricValue = status.isNew.toNvmUint64()
+		case devStatusTypeEnum.isConfigured:
+			metricValue = status.isConfigured.toNvmUint64()
+		case devStatusTypeEnum.isMissing:
+			metricValue = status.isMissing.toNvmUint64()
+		case devStatusTypeEnum.packageSparesAvailable:
+			metricValue = nvmUint64(status.packageSparesAvailable)
+		case devStatusTypeEnum.lastShutdownTime:
+			metricValue = status.lastShutdownTime
+		case devStatusTypeEnum.viralState:
+			metricValue = status.viralState.toNvmUint64()
+		case devStatusTypeEnum.aitDRAMEnabled:
+			metricValue = status.aitDRAMEnabled.toNvmUint64()
+		case devStatusTypeEnum.bootStatus:
+			metricValue = status.bootStatus
+		case devStatusTypeEnum.injectedMediaErrors:
+			metricValue = nvmUint64(status.injectedMediaErrors)
+		case devStatusTypeEnum.injectedNonMediaErrors:
+			metricValue = nvmUint64(status.injectedNonMediaErrors)
+		case devStatusTypeEnum.thermalThrottleLoss:
+			metricValue = nvmUint64(status.thermalThrottlePerformanceLossPCNT)
+		}
+		devStatusReading := *newDevStatusReading(dev.uid, opstat, metricType, metricValue)
+		devStatusReading.Labels.addLabel("uid", string(dev.uid))
+		dev.addLocationLabel(devStatusReading.Labels)
+		results[i] = MetricReading(devStatusReading)
+	}
+	return results
+}
+
+// getDeviceStatusStateReadings returns one reading per device and possible
+// state, the reading of the current state is set to 1, all others to 0
+func (reader *MetricsReader) getDeviceStatusStateReadings(metricType devStatusTypeEnumAttr,
+	states []enumAttr,
+	getName func(dev device, state enumAttr) (string, bool)) []MetricReading {
+	results := make([]MetricReading, 0, int(reader.deviceCount)*len(states))
+	for _, dev := range reader.devices {
+		for _, state := range states {
+			name, current := getName(dev, state)
+			metricValue := nvmUint64(0)
+			if current {
+				metricValue = 1
+			}
+			devStatusReading := *newDevStatusReading(dev.uid, dev.statusOpstat, metricType, metricValue)
+			devStatusReading.Labels = devStatusStateLabels(*newMetricLabels())
+			devStatusReading.Labels.addLabel("uid", string(dev.uid))
+			devStatusReading.Labels.addLabel("state", name)
+			dev.addLocationLabel(devStatusReading.Labels)
+			results = append(results, MetricReading(devStatusReading))
+		}
+	}
+	return results
+}
+
+// Indicates if the DCPMM is unincorporated with the rest of the devices
+func (reader *MetricsReader) GetIsNew() []MetricReading {
+	return reader.getDeviceStatusReadings(devStatusTypeEnum.isNew)
+}
+
+// Indicates if the DCPMM is configured
+func (reader *MetricsReader) GetIsConfigured() []MetricReading {
+	return reader.getDeviceStatusReadings(devStatusTypeEnum.isConfigured)
+}
+
+// Indicates if the DCPMM is missing
+func (reader *MetricsReader) GetIsMissing() []MetricReading {
+	return reader.getDeviceStatusReadings(devStatusTypeEnum.isMissing)
+}
+
+// Number of package spares on the DCPMM that are available
+func (reader *MetricsReader) GetPackageSparesAvailable() []MetricReading {
+	return reader.getDeviceStatusReadings(devStatusTypeEnum.packageSparesAvailable)
+}
+
+// Time of the last shutdown - seconds since 1 January 1970
+func (reader *MetricsReader) GetLastShutdownTime() []MetricReading {
+	return reader.getDeviceStatusReadings(devStatusTypeEnum.lastShutdownTime)
+}
+
+// Current viral status of the DCPMM
+func (reader *MetricsReader) GetViralState() []MetricReading {
+	return reader.getDeviceStatusReadings(devStatusTypeEnum.viralState)
+}
+
+// Indicates if the AIT DRAM is enabled
+func (reader *MetricsReader) GetAitDRAMEnabled() []MetricReading {
+	return reader.getDeviceStatusReadings(devStatusTypeEnum.aitDRAMEnabled)
+}
+
+// Status of the DCPMM as reported by the firmware in the boot status register
+func (reader *MetricsReader) GetBootStatus() []MetricReading {
+	return reader.getDeviceStatusReadings(devStatusTypeEnum.bootStatus)
+}
+
+// Number of injected media errors on the DCPMM
+func (reader *MetricsReader) GetInjectedMediaErrors() []MetricReading {
+	return reader.getDeviceStatusReadings(devStatusTypeEnum.injectedMediaErrors)
+}
+
+// Number of injected non-media errors on the DCPMM
+func (reader *MetricsReader) GetInjectedNonMediaErrors() []MetricReading {
+	return reader.getDeviceStatusReadings(devStatusTypeEnum.injectedNonMediaErrors)
+}
+
+// Status of the last configuration request
+func (reader *MetricsReader) GetConfigStatus() []MetricReading {
+	states := []enumAttr{
+		enumAttr(configStatusEnum.configStatusNotConfigured),
+		enumAttr(configStatusEnum.configStatusValid),
+		enumAttr(configStatusEnum.configStatusErrCorrupt),
+		enumAttr(configStatusEnum.configStatusErrBrokenInterleave),
+		enumAttr(configStatusEnum.configStatusErrReverted),
+		enumAttr(configStatusEnum.configStatusErrNotSupported),
+		enumAttr(configStatusEnum.configStatusUnknown),
+	}
+	return reader.getDeviceStatusStateReadings(devStatusTypeEnum.configStatus, states,
+		func(dev device, state enumAttr) (string, bool) {
+			configStatus := configStatusEnumAttr(state)
+			return getConfigStatusName(configStatus), configStatus == dev.status.configStatus
+		})
+}
+
+// Address range scrub operation status of the DCPMM
+func (reader *MetricsReader) GetARSStatus() []MetricReading {
+	states := []enumAttr{
+		enumAttr(deviceARSStatusEnum.deviceARSStatusUnknown),
+		enumAttr(deviceARSStatusEnum.deviceARSStatusNotStarted),
+		enumAttr(deviceARSStatusEnum.deviceARSStatusInprogress),
+		enumAttr(deviceARSStatusEnum.deviceARSStatusComplete),
+		enumAttr(deviceARSStatusEnum.deviceARSStatusAborted),
+	}
+	return reader.getDeviceStatusStateReadings(devStatusTypeEnum.arsStatus, states,
+		func(dev device, state enumAttr) (string, bool) {
+			arsStatus := deviceARSStatusEnumAttr(state)
+			return getARSStatusName(arsStatus), arsStatus == dev.status.arsStatus
+		})
+}
+
+// Overwrite DCPMM operation status
+func (reader *MetricsReader) GetOverwriteDIMMStatus() []MetricReading {
+	states := []enumAttr{
+		enumAttr(deviceOverwriteDIMMStatusEnum.deviceOverwriteDIMMStatusUnknown),
+		enumAttr(deviceOverwriteDIMMStatusEnum.deviceOverwriteDIMMStatusNotstarted),
+		enumAttr(deviceOverwriteDIMMStatusEnum.deviceOverwriteDIMMStatusInprogress),
+		enumAttr(deviceOverwriteDIMMStatusEnum.deviceOverwriteDIMMStatusComplete),
+	}
+	return reader.getDeviceStatusStateReadings(devStatusTypeEnum.overwritedimmStatus, states,
+		func(dev device, state enumAttr) (string, bool) {
+			overwriteStatus := deviceOverwriteDIMMStatusEnumAttr(state)
+			return getOverwriteDIMMStatusName(overwriteStatus), overwriteStatus == dev.status.overwritedimmStatus
+		})
+}
@@ -0,0 +1,418 @@
+/**
+ * Copyright (c) 2020-2021, Intel Corporation.
+ * SPDX-License-Identifier: BSD-3-Clause
+ **
+ * This package introduces wrapper for ipmctl library written in C.
+ * api_sysfs.go file contains sysfsBackend, which reads NVDIMMs exposed by
+ * the kernel nfit driver under /sys/bus/nd/devices/nmem*, and their health
+ * (SMART) and firmware data reported by ndctl (ndctl list -DHF). It lets the
+ * exporter to report health and temperature metrics on hosts without
+ * libipmctl. Readings not available through these sources (e.g. performance
+ * counters) are reported as not supported.
+ */
+
+package nvm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ndctlDIMM is a single DIMM reported by ndctl list -DHF
+type ndctlDIMM struct {
+	Dev      string       `json:"dev"`
+	ID       string       `json:"id"`
+	Handle   uint64       `json:"handle"`
+	PhysID   uint64       `json:"phys_id"`
+	Security string       `json:"security"`
+	Health   *ndctlHealth `json:"health"`
+	Firmware *struct {
+		CurrentVersion string `json:"current_version"`
+		NextVersion    string `json:"next_version"`
+		NeedPowercycle bool   `json:"need_powercycle"`
+	} `json:"firmware"`
+}
+
+type ndctlHealth struct {
+	HealthState                    string   `json:"health_state"`
+	TemperatureCelsius             *float64 `json:"temperature_celsius"`
+	ControllerTemperatureCelsius   *float64 `json:"controller_temperature_celsius"`
+	SparesPercentage               *uint64  `json:"spares_percentage"`
+	AlarmTemperature               bool     `json:"alarm_temperature"`
+	AlarmControllerTemperature     bool     `json:"alarm_controller_temperature"`
+	AlarmSpares                    bool     `json:"alarm_spares"`
+	AlarmEnabledMediaTemperature   bool     `json:"alarm_enabled_media_temperature"`
+	TemperatureThreshold           float64  `json:"temperature_threshold"`
+	AlarmEnabledCtrlTemperature    bool     `json:"alarm_enabled_ctrl_temperature"`
+	ControllerTemperatureThreshold float64  `json:"controller_temperature_threshold"`
+	AlarmEnabledSpares             bool     `json:"alarm_enabled_spares"`
+	SparesThreshold                uint64   `json:"spares_threshold"`
+	ShutdownState                  string   `json:"shutdown_state"`
+	ShutdownCount                  *uint64  `json:"shutdown_count"`
+}
+
+type sysfsDevice struct {
+	discovery    deviceDiscovery
+	sensors      map[sensorTypeEnumAttr]sensor
+	fwInfo       deviceFWInfo
+	fwInfoOpstat nvmStatusCodeEnumAttr
+}
+
+// sysfsBackend reads NVDIMMs from sysfs and ndctl, all the readings are
+// refreshed at the beginning of every reading cycle
+type sysfsBackend struct {
+	unsupportedBackend
+	root     string
+	runNdctl cliRunner
+	devices  []sysfsDevice
+	lock     sync.Mutex
+}
+
+// NewSysfsBackend creates backend reading sysfs mounted at given root
+// directory (usually /sys) and health data reported by given ndctl binary,
+// empty ndctl path disables health data
+func NewSysfsBackend(root string, ndctlPath string) Backend {
+	var runNdctl cliRunner
+	if ndctlPath != "" {
+		runNdctl = func(args ...string) ([]byte, error) {
+			var stderr bytes.Buffer
+			cmd := exec.Command(ndctlPath, args...)
+			cmd.Stderr = &stderr
+			output, err := cmd.Output()
+			if err != nil {
+				return output, fmt.Errorf("%s %s failed: %v %s", ndctlPath,
+					strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+			}
+			return output, nil
+		}
+	}
+	return newSysfsBackend(root, runNdctl)
+}
+
+func newSysfsBackend(root string, runNdctl cliRunner) *sysfsBackend {
+	return &sysfsBackend{root: root, runNdctl: runNdctl}
+}
+
+func (backend *sysfsBackend) Init() (bool, error) {
+	path := filepath.Join(backend.root, "bus", "nd", "devices")
+	if _, err := ioutil.ReadDir(path); err != nil {
+		return false, fmt.Errorf("Unable to read NVDIMM devices, is nfit driver loaded? %v", err)
+	}
+	return true, nil
+}
+
+// GetNumberOfDevices reads sysfs and runs ndctl to refresh all the readings
+func (backend *sysfsBackend) GetNumberOfDevices() (nvmStatusCodeEnumAttr, nvmUint8, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	if err := backend.refresh(); err != nil {
+		return nvmStatusCodeEnum.nvmErrOperationFailed, 0, err
+	}
+	return nvmStatusCodeEnum.nvmSuccess, nvmUint8(len(backend.devices)), nil
+}
+
+func (backend *sysfsBackend) GetDevices(count nvmUint8) (nvmStatusCodeEnumAttr, []deviceDiscovery, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	if int(count) > len(backend.devices) {
+		return nvmStatusCodeEnum.nvmErrInvalidParameter, nil,
+			fmt.Errorf("Requested %d devices, but only %d are present", count, len(backend.devices))
+	}
+	discoveries := make([]deviceDiscovery, count)
+	for i := range discoveries {
+		discoveries[i] = backend.devices[i].discovery
+	}
+	return nvmStatusCodeEnum.nvmSuccess, discoveries, nil
+}
+
+func (backend *sysfsBackend) GetDeviceDiscovery(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceDiscovery, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	dev, err := backend.find(deviceUID)
+	if err != nil {
+		return nvmStatusCodeEnum.nvmErrDIMMNotFound, deviceDiscovery{}, err
+	}
+	return nvmStatusCodeEnum.nvmSuccess, dev.discovery, nil
+}
+
+func (backend *sysfsBackend) GetSensor(deviceUID nvmUID,
+	stype sensorTypeEnumAttr) (nvmStatusCodeEnumAttr, sensor, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	dev, err := backend.find(deviceUID)
+	if err != nil {
+		return nvmStatusCodeEnum.nvmErrDIMMNotFound, sensor{}, err
+	}
+	result, found := dev.sensors[stype]
+	if !found {
+		return nvmStatusCodeEnum.nvmErrAPINotSupported, sensor{},
+			fmt.Errorf("Sensor %d of device %s is not available in sysfs", stype, deviceUID)
+	}
+	return nvmStatusCodeEnum.nvmSuccess, result, nil
+}
+
+func (backend *sysfsBackend) GetDeviceFwImageInfo(deviceUID nvmUID) (nvmStatusCodeEnumAttr, deviceFWInfo, error) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	dev, err := backend.find(deviceUID)
+	if err != nil {
+		return nvmStatusCodeEnum.nvmErrDIMMNotFound, deviceFWInfo{}, err
+	}
+	if nvmStatusCodeEnum.nvmSuccess != dev.fwInfoOpstat {
+		err = fmt.Errorf("Firmware of device %s is not reported by ndctl", deviceUID)
+	}
+	return dev.fwInfoOpstat, dev.fwInfo, err
+}
+
+func (backend *sysfsBackend) find(deviceUID nvmUID) (*sysfsDevice, error) {
+	for i := range backend.devices {
+		if backend.devices[i].discovery.uid == deviceUID {
+			return &backend.devices[i], nil
+		}
+	}
+	return nil, fmt.Errorf("Device %s not found", deviceUID)
+}
+
+// refresh reads all nmem devices from sysfs and merges ndctl health data
+func (backend *sysfsBackend) refresh() error {
+	pattern := filepath.Join(backend.root, "bus", "nd", "devices", "nmem*")
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return err
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		return sysfsNmemIndex(paths[i]) < sysfsNmemIndex(paths[j])
+	})
+	ndctlDIMMs := make(map[string]ndctlDIMM)
+	if backend.runNdctl != nil {
+		// health data is optional, DIMMs are still reported without it
+		if output, err := backend.runNdctl("list", "-D", "-H", "-F"); err == nil {
+			if dimms, err := parseNdctlOutput(output); err == nil {
+				for _, dimm := range dimms {
+					ndctlDIMMs[dimm.Dev] = dimm
+				}
+			}
+		}
+	}
+	devices := make([]sysfsDevice, 0, len(paths))
+	for _, path := range paths {
+		name := filepath.Base(path)
+		ndctl, hasNdctl := ndctlDIMMs[name]
+		dev := sysfsDevice{
+			discovery:    newSysfsDeviceDiscovery(path, ndctl),
+			sensors:      make(map[sensorTypeEnumAttr]sensor),
+			fwInfoOpstat: nvmStatusCodeEnum.nvmErrAPINotSupported,
+		}
+		if dev.discovery.uid == "" {
+			continue
+		}
+		if hasNdctl && ndctl.Health != nil {
+			addNdctlSensors(dev.sensors, ndctl.Health)
+		}
+		if hasNdctl && ndctl.Firmware != nil {
+			dev.fwInfo = newNdctlDeviceFWInfo(ndctl)
+			dev.fwInfoOpstat = nvmStatusCodeEnum.nvmSuccess
+		}
+		if value, err := readSysfsUint(filepath.Join(path, "nfit", "dirty_shutdown")); err == nil {
+			dev.sensors[sensorTypeEnum.sensorLatchedDirtyShutdownCount] = sensor{
+				stype:   sensorTypeEnum.sensorLatchedDirtyShutdownCount,
+				units:   sensorUnitsEnum.unitCount,
+				reading: nvmUint64(value),
+			}
+		}
+		devices = append(devices, dev)
+	}
+	backend.devices = devices
+	return nil
+}
+
+func sysfsNmemIndex(path string) uint64 {
+	return parseCLIUint(strings.TrimPrefix(filepath.Base(path), "nmem"))
+}
+
+func readSysfsString(path string) (string, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+func readSysfsUint(path string) (uint64, error) {
+	value, err := readSysfsString(path)
+	if err != nil {
+		return 0, err
+	}
+	return parseCLIUint(value), nil
+}
+
+// parseNdctlOutput accepts all the forms of ndctl list output: a single
+// object, array of objects and object with "dimms" array
+func parseNdctlOutput(output []byte) ([]ndctlDIMM, error) {
+	output = bytes.TrimSpace(output)
+	if 0 == len(output) {
+		return []ndctlDIMM{}, nil
+	}
+	if '[' == output[0] {
+		var dimms []ndctlDIMM
+		err := json.Unmarshal(output, &dimms)
+		return dimms, err
+	}
+	var listing struct {
+		DIMMs []ndctlDIMM `json:"dimms"`
+	}
+	if err := json.Unmarshal(output, &listing); err != nil {
+		return nil, err
+	}
+	if listing.DIMMs != nil {
+		return listing.DIMMs, nil
+	}
+	var dimm ndctlDIMM
+	err := json.Unmarshal(output, &dimm)
+	return []ndctlDIMM{dimm}, err
+}
+
+func newSysfsDeviceDiscovery(path string, ndctl ndctlDIMM) deviceDiscovery {
+	nfit := func(name string) uint64 {
+		value, _ := readSysfsUint(filepath.Join(path, "nfit", name))
+		return value
+	}
+	handle := nfit("handle")
+	if 0 == handle {
+		handle = ndctl.Handle
+	}
+	physicalID := nfit("phys_id")
+	if 0 == physicalID {
+		physicalID = ndctl.PhysID
+	}
+	uid, _ := readSysfsString(filepath.Join(path, "nfit", "id"))
+	if "" == uid {
+		uid = ndctl.ID
+	}
+	serial, _ := readSysfsString(filepath.Join(path, "nfit", "serial"))
+	vendor, _ := readSysfsString(filepath.Join(path, "nfit", "vendor"))
+	discovery := deviceDiscovery{
+		deviceHandle:        newCLIDeviceHandle(handle),
+		physicalID:          nvmUint16(physicalID),
+		vendorID:            nvmUint16(parseCLIUint(vendor)),
+		deviceID:            nvmUint16(nfit("device")),
+		revisionID:          nvmUint16(nfit("rev_id")),
+		channelPos:          nvmUint16(handle & 0xf),
+		channelID:           nvmUint16((handle >> 4) & 0xf),
+		memoryControllerID:  nvmUint16((handle >> 8) & 0xf),
+		socketID:            nvmUint16((handle >> 12) & 0xf),
+		nodeControllerID:    nvmUint16((handle >> 16) & 0xfff),
+		memoryType:          memoryTypeEnum.memoryTypeNVMDIMM,
+		manufacturer:        nvmManufacturer(parseCLIBytes(vendor)),
+		serialNumber:        nvmSerialNumber(parseCLIBytes(serial)),
+		subsystemVendorID:   nvmUint16(nfit("subsystem_vendor")),
+		subsystemDeviceID:   nvmUint16(nfit("subsystem_device")),
+		subsystemRevisionID: nvmUint16(nfit("subsystem_rev_id")),
+		uid:                 nvmUID(uid),
+		lockState:           parseCLILockState(ndctl.Security),
+		manageability:       manageabilityStateEnum.managementUnknown,
+	}
+	if ndctl.Firmware != nil {
+		discovery.fwRevision = nvmVersion(ndctl.Firmware.CurrentVersion)
+	}
+	discovery.interfaceFormatCodes[0] = nvmUint16(nfit("format"))
+	discovery.interfaceFormatCodes[1] = nvmUint16(nfit("format1"))
+	return discovery
+}
+
+// newNdctlDeviceFWInfo returns firmware reported by ndctl, next version is
+// staged only if power cycle is needed to activate it
+func newNdctlDeviceFWInfo(ndctl ndctlDIMM) deviceFWInfo {
+	fwInfo := deviceFWInfo{
+		activeFwRevision: nvmVersion(ndctl.Firmware.CurrentVersion),
+		fwUpdateStatus:   fwUpdateStatusEnum.fwUpdateUnknown,
+	}
+	if ndctl.Firmware.NeedPowercycle {
+		fwInfo.stagedFwRevision = nvmVersion(ndctl.Firmware.NextVersion)
+		fwInfo.fwUpdateStatus = fwUpdateStatusEnum.fwUpdateStaged
+	}
+	return fwInfo
+}
+
+func addNdctlSensors(sensors map[sensorTypeEnumAttr]sensor, health *ndctlHealth) {
+	sensors[sensorTypeEnum.sensorHealth] = sensor{
+		stype:   sensorTypeEnum.sensorHealth,
+		units:   sensorUnitsEnum.unitCount,
+		reading: nvmUint64(parseNdctlHealth(health.HealthState)),
+	}
+	if health.TemperatureCelsius != nil {
+		sensors[sensorTypeEnum.sensorMediaTemperature] = newNdctlThresholdSensor(
+			sensorTypeEnum.sensorMediaTemperature, sensorUnitsEnum.unitCelsius,
+			*health.TemperatureCelsius, health.AlarmEnabledMediaTemperature,
+			health.TemperatureThreshold, true, health.AlarmTemperature)
+	}
+	if health.ControllerTemperatureCelsius != nil {
+		sensors[sensorTypeEnum.sensorControllerTemperature] = newNdctlThresholdSensor(
+			sensorTypeEnum.sensorControllerTemperature, sensorUnitsEnum.unitCelsius,
+			*health.ControllerTemperatureCelsius, health.AlarmEnabledCtrlTemperature,
+			health.ControllerTemperatureThreshold, true, health.AlarmControllerTemperature)
+	}
+	if health.SparesPercentage != nil {
+		sensors[sensorTypeEnum.sensorPercentageRemaining] = newNdctlThresholdSensor(
+			sensorTypeEnum.sensorPercentageRemaining, sensorUnitsEnum.unitPercent,
+			float64(*health.SparesPercentage), health.AlarmEnabledSpares,
+			float64(health.SparesThreshold), false, health.AlarmSpares)
+	}
+	if health.ShutdownCount != nil {
+		sensors[sensorTypeEnum.sensorLatchedDirtyShutdownCount] = sensor{
+			stype:   sensorTypeEnum.sensorLatchedDirtyShutdownCount,
+			units:   sensorUnitsEnum.unitCount,
+			reading: nvmUint64(*health.ShutdownCount),
+		}
+	}
+}
+
+// newNdctlThresholdSensor creates sensor with a single noncritical
+// threshold, which is the only one reported by ndctl
+func newNdctlThresholdSensor(stype sensorTypeEnumAttr,
+	units sensorUnitsEnumAttr,
+	reading float64,
+	enabled bool,
+	threshold float64,
+	isUpper bool,
+	alarm bool) sensor {
+	result := sensor{
+		stype:        stype,
+		units:        units,
+		currentState: sensorStatusEnum.sensorNormal,
+		reading:      nvmUint64(reading),
+	}
+	result.settings.enabled = nvmBool(enabled)
+	if isUpper {
+		result.settings.upperNoncriticalThreshold = nvmUint64(threshold)
+		result.upperNoncriticalSupport = true
+	} else {
+		result.settings.lowerNoncriticalThreshold = nvmUint64(threshold)
+		result.lowerNoncriticalSupport = true
+	}
+	if alarm {
+		result.currentState = sensorStatusEnum.sensorNoncritical
+	}
+	return result
+}
+
+func parseNdctlHealth(value string) healthStatusEnumAttr {
+	switch strings.ToLower(value) {
+	case "ok":
+		return healthStatusEnum.healthStatusHealthy
+	case "non-critical":
+		return healthStatusEnum.healthStatusNonCriticalFailure
+	case "critical":
+		return healthStatusEnum.healthStatusCriticalFailure
+	case "fatal":
+		return healthStatusEnum.healthStatusFatalFailure
+	}
+	return healthStatusEnum.healthStatusUnknown
+}
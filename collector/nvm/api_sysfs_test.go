@@ -0,0 +1,138 @@
+/**
+ * Copyright (c) 2020-2021, Intel Corporation.
+ * SPDX-License-Identifier: BSD-3-Clause
+ **
+ * api_sysfs_test.go file tests sysfsBackend against a fake sysfs tree found
+ * in testdata/sysfs and canned ndctl output found in testdata/ndctl.
+ */
+
+package nvm
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func newFixtureSysfsBackend(t *testing.T) *sysfsBackend {
+	backend := newSysfsBackend(filepath.Join("testdata", "sysfs"), func(args ...string) ([]byte, error) {
+		return ioutil.ReadFile(filepath.Join("testdata", "ndctl", "list.json"))
+	})
+	if status, err := backend.Init(); !status {
+		t.Fatalf("Init failed: %v", err)
+	}
+	return backend
+}
+
+func TestSysfsBackendDiscovery(t *testing.T) {
+	backend := newFixtureSysfsBackend(t)
+	_, count, err := backend.GetNumberOfDevices()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, discoveries, _ := backend.GetDevices(count)
+	// nmem devices are ordered by their index, the one without id is skipped
+	expected := []nvmUID{
+		"8089-a2-1951-00001234",
+		"8089-a2-1951-00005678",
+		"8089-a2-1951-00009abc",
+		"8089-a2-1951-0000def0",
+	}
+	if len(discoveries) != len(expected) {
+		t.Fatalf("expected %d devices, got %d", len(expected), len(discoveries))
+	}
+	for i, uid := range expected {
+		if discoveries[i].uid != uid {
+			t.Errorf("device %d: expected uid %s, got %s", i, uid, discoveries[i].uid)
+		}
+	}
+	discovery := discoveries[1]
+	if discovery.socketID != 1 || discovery.memoryControllerID != 1 ||
+		discovery.channelID != 0 || discovery.channelPos != 1 {
+		t.Errorf("unexpected location decoded from handle: socket %d, controller %d, channel %d, slot %d",
+			discovery.socketID, discovery.memoryControllerID, discovery.channelID, discovery.channelPos)
+	}
+	if discovery.physicalID != 0x26 || discovery.vendorID != 0x8089 || discovery.deviceID != 0x97a ||
+		discovery.interfaceFormatCodes[0] != 0x301 || discovery.lockState != lockStateEnum.lockStateFrozen {
+		t.Errorf("unexpected discovery %+v", discovery)
+	}
+}
+
+func TestSysfsBackendSensors(t *testing.T) {
+	backend := newFixtureSysfsBackend(t)
+	backend.GetNumberOfDevices()
+	healthy := nvmUID("8089-a2-1951-00001234")
+	_, temperature, _ := backend.GetSensor(healthy, sensorTypeEnum.sensorMediaTemperature)
+	if temperature.reading != 36 || temperature.currentState != sensorStatusEnum.sensorNormal ||
+		temperature.settings.upperNoncriticalThreshold != 82 || !temperature.settings.enabled {
+		t.Errorf("unexpected media temperature %+v", temperature)
+	}
+	// dirty shutdown count read from sysfs takes precedence over ndctl
+	_, shutdowns, _ := backend.GetSensor(healthy, sensorTypeEnum.sensorLatchedDirtyShutdownCount)
+	if shutdowns.reading != 3 {
+		t.Errorf("expected 3 dirty shutdowns, got %d", shutdowns.reading)
+	}
+	_, fwInfo, _ := backend.GetDeviceFwImageInfo(healthy)
+	if fwInfo.stagedFwRevision != "01.02.00.5446" || fwInfo.fwUpdateStatus != fwUpdateStatusEnum.fwUpdateStaged {
+		t.Errorf("unexpected firmware %+v", fwInfo)
+	}
+
+	critical := nvmUID("8089-a2-1951-00005678")
+	_, health, _ := backend.GetSensor(critical, sensorTypeEnum.sensorHealth)
+	if health.reading != nvmUint64(healthStatusEnum.healthStatusCriticalFailure) {
+		t.Errorf("expected critical health, got %d", health.reading)
+	}
+	_, spares, _ := backend.GetSensor(critical, sensorTypeEnum.sensorPercentageRemaining)
+	if spares.reading != 40 || spares.currentState != sensorStatusEnum.sensorNoncritical ||
+		spares.settings.lowerNoncriticalThreshold != 50 || !spares.lowerNoncriticalSupport {
+		t.Errorf("unexpected percentage remaining %+v", spares)
+	}
+	if opstat, _, _ := backend.GetSensor(critical, sensorTypeEnum.sensorControllerTemperature); opstat != nvmStatusCodeEnum.nvmErrAPINotSupported {
+		t.Errorf("expected controller temperature not reported by ndctl to be not supported, got status %d", opstat)
+	}
+	_, fwInfo, _ = backend.GetDeviceFwImageInfo(critical)
+	if fwInfo.stagedFwRevision != "" || fwInfo.fwUpdateStatus != fwUpdateStatusEnum.fwUpdateUnknown {
+		t.Errorf("expected no firmware staged, got %+v", fwInfo)
+	}
+
+	// nmem2 is not reported by ndctl, only sysfs readings are available
+	missing := nvmUID("8089-a2-1951-00009abc")
+	if opstat, _, _ := backend.GetSensor(missing, sensorTypeEnum.sensorHealth); opstat != nvmStatusCodeEnum.nvmErrAPINotSupported {
+		t.Errorf("expected health to be not supported, got status %d", opstat)
+	}
+	if opstat, _, _ := backend.GetDeviceFwImageInfo(missing); opstat != nvmStatusCodeEnum.nvmErrAPINotSupported {
+		t.Errorf("expected firmware to be not supported, got status %d", opstat)
+	}
+}
+
+func TestSysfsBackendWithoutNdctl(t *testing.T) {
+	backend := newSysfsBackend(filepath.Join("testdata", "sysfs"), nil)
+	_, count, err := backend.GetNumberOfDevices()
+	if err != nil || count != 4 {
+		t.Fatalf("expected 4 devices, got %d (%v)", count, err)
+	}
+	if opstat, _, _ := backend.GetSensor("8089-a2-1951-00001234", sensorTypeEnum.sensorMediaTemperature); opstat != nvmStatusCodeEnum.nvmErrAPINotSupported {
+		t.Errorf("expected media temperature to be not supported, got status %d", opstat)
+	}
+	backend = newSysfsBackend(filepath.Join("testdata", "missing"), nil)
+	if status, _ := backend.Init(); status {
+		t.Errorf("expected Init to fail without nd bus in sysfs")
+	}
+}
+
+func TestParseNdctlOutput(t *testing.T) {
+	outputs := []string{
+		`{"dev":"nmem0","id":"8089-a2-1951-00001234"}`,
+		`[{"dev":"nmem0","id":"8089-a2-1951-00001234"}]`,
+		`{"dimms":[{"dev":"nmem0","id":"8089-a2-1951-00001234"}]}`,
+	}
+	for _, output := range outputs {
+		dimms, err := parseNdctlOutput([]byte(output))
+		if err != nil || len(dimms) != 1 || dimms[0].Dev != "nmem0" {
+			t.Errorf("%s: unexpected DIMMs %+v (%v)", output, dimms, err)
+		}
+	}
+	if dimms, err := parseNdctlOutput([]byte("  \n")); err != nil || len(dimms) != 0 {
+		t.Errorf("expected no DIMMs in empty output, got %+v (%v)", dimms, err)
+	}
+}
@@ -0,0 +1,302 @@
+/**
+ * Copyright (c) 2020-2021, Intel Corporation.
+ * SPDX-License-Identifier: BSD-3-Clause
+ **
+ * This package introduces wrapper for ipmctl library written in C.
+ * api_syslog.go file contains EventForwarder, which polls the event log of
+ * the backend (through MetricsReader, so the backend is never called
+ * concurrently) on an interval and writes every new event to syslog as
+ * RFC5424 message. The ID of the last event forwarded is saved to a state
+ * file, so no event is forwarded twice, even if the exporter is restarted.
+ */
+
+package nvm
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// syslog facility used for all the messages (daemon)
+	syslogFacility = 3
+	// structured data ID, 343 is the private enterprise number of Intel
+	syslogSDID    = "ipmctl@343"
+	syslogAppName = "ipmctl_exporter"
+)
+
+// syslogWriter sends messages to syslog over UDP, TCP or local socket, the
+// connection is (re)established on demand
+type syslogWriter struct {
+	network string
+	address string
+	conn    net.Conn
+	// stream sockets need messages to be framed
+	stream bool
+}
+
+// newSyslogWriter creates writer for given address, one of:
+// udp://host:port, tcp://host:port or unix:///dev/log (absolute path of
+// local socket may be given without the scheme)
+func newSyslogWriter(address string) (*syslogWriter, error) {
+	if strings.HasPrefix(address, "/") {
+		return &syslogWriter{network: "unix", address: address}, nil
+	}
+	parts := strings.SplitN(address, "://", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return nil, fmt.Errorf("Invalid syslog address: %s", address)
+	}
+	switch parts[0] {
+	case "udp", "tcp", "unix":
+		return &syslogWriter{network: parts[0], address: parts[1]}, nil
+	}
+	return nil, fmt.Errorf("Unsupported syslog network: %s", parts[0])
+}
+
+func (writer *syslogWriter) connect() error {
+	var err error
+	if writer.network == "unix" {
+		// local syslog daemons (and journald) listen on datagram socket,
+		// fall back to stream one
+		if writer.conn, err = net.Dial("unixgram", writer.address); err == nil {
+			writer.stream = false
+			return nil
+		}
+	}
+	writer.conn, err = net.DialTimeout(writer.network, writer.address, 10*time.Second)
+	writer.stream = writer.network != "udp"
+	return err
+}
+
+// write sends single message, messages sent over stream sockets are framed
+// with octet counting (RFC6587), over datagram sockets one per datagram
+func (writer *syslogWriter) write(message []byte) error {
+	if writer.conn == nil {
+		if err := writer.connect(); err != nil {
+			return fmt.Errorf("Unable to connect to syslog %s://%s: %v", writer.network, writer.address, err)
+		}
+	}
+	frame := message
+	if writer.stream {
+		frame = append([]byte(strconv.Itoa(len(message))+" "), message...)
+	}
+	if _, err := writer.conn.Write(frame); err != nil {
+		writer.close()
+		return fmt.Errorf("Unable to write to syslog %s://%s: %v", writer.network, writer.address, err)
+	}
+	return nil
+}
+
+func (writer *syslogWriter) close() {
+	if writer.conn != nil {
+		writer.conn.Close()
+		writer.conn = nil
+	}
+}
+
+// getSyslogSeverity maps severity of the event to syslog severity
+func getSyslogSeverity(severity eventSeverityEnumAttr) int {
+	switch severity {
+	case eventSeverityEnum.eventSeverityInfo:
+		return 6 // informational
+	case eventSeverityEnum.eventSeverityWarn:
+		return 4 // warning
+	case eventSeverityEnum.eventSeverityCritical:
+		return 2 // critical
+	case eventSeverityEnum.eventSeverityFatal:
+		return 1 // alert
+	}
+	return 5 // notice
+}
+
+// escapeSDParam escapes characters not allowed in structured data parameter
+// value
+func escapeSDParam(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(value)
+}
+
+// formatSyslogMessage formats the event as RFC5424 message, event type is
+// used as MSGID and all the event fields are attached as structured data
+func formatSyslogMessage(e *event, hostname string, pid int) []byte {
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "<%d>1 %s %s %s %d %s ",
+		syslogFacility*8+getSyslogSeverity(e.severity),
+		time.Unix(int64(e.time), 0).UTC().Format(time.RFC3339),
+		hostname, syslogAppName, pid, getEventTypeName(e.etype))
+	fmt.Fprintf(&message, `[%s event_id="%d" type="%s" severity="%s" code="%d" uid="%s"`,
+		syslogSDID, e.eventID, getEventTypeName(e.etype), getEventSeverityName(e.severity),
+		e.code, escapeSDParam(string(e.uid)))
+	for i, arg := range e.args {
+		if arg != "" {
+			fmt.Fprintf(&message, ` arg%d="%s"`, i, escapeSDParam(string(arg)))
+		}
+	}
+	if e.isDiagnostic() {
+		fmt.Fprintf(&message, ` diag_result="%s"`, getDiagnosticResultName(e.diagResult))
+	}
+	message.WriteString("]")
+	if e.message != "" {
+		message.WriteString(" " + string(e.message))
+	}
+	return message.Bytes()
+}
+
+// loadEventMark reads the ID of the last event forwarded from the state
+// file, missing file means no event was forwarded yet
+func loadEventMark(stateFile string) (nvmUint32, error) {
+	if stateFile == "" {
+		return 0, nil
+	}
+	content, err := ioutil.ReadFile(stateFile)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("Unable to read event forwarder state: %v", err)
+	}
+	mark, err := strconv.ParseUint(strings.TrimSpace(string(content)), 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid event forwarder state in %s: %v", stateFile, err)
+	}
+	return nvmUint32(mark), nil
+}
+
+// saveEventMark replaces the state file with the ID of the last event
+// forwarded, it is written to a temporary file first, so the state is never
+// left half written
+func saveEventMark(stateFile string, mark nvmUint32) error {
+	if stateFile == "" {
+		return nil
+	}
+	temp, err := ioutil.TempFile(filepath.Dir(stateFile), filepath.Base(stateFile)+".*")
+	if err != nil {
+		return fmt.Errorf("Unable to save event forwarder state: %v", err)
+	}
+	_, err = fmt.Fprintf(temp, "%d\n", mark)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(temp.Name(), stateFile)
+	}
+	if err != nil {
+		os.Remove(temp.Name())
+		return fmt.Errorf("Unable to save event forwarder state: %v", err)
+	}
+	return nil
+}
+
+// EventForwarder writes new events of the backend event log to syslog
+type EventForwarder struct {
+	reader    *MetricsReader
+	writer    *syslogWriter
+	interval  time.Duration
+	stateFile string
+	hostname  string
+	lastID    nvmUint32
+	stop      chan struct{}
+	done      chan struct{}
+	once      sync.Once
+}
+
+// NewEventForwarder creates forwarder polling the event log of the backend of
+// given reader every interval, the ID of the last event forwarded is kept in
+// the state file (empty path disables it, so all the events in the log are
+// forwarded again after restart)
+func NewEventForwarder(reader *MetricsReader,
+	address string,
+	interval time.Duration,
+	stateFile string) (*EventForwarder, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("Event forwarder interval has to be positive")
+	}
+	writer, err := newSyslogWriter(address)
+	if err != nil {
+		return nil, err
+	}
+	lastID, err := loadEventMark(stateFile)
+	if err != nil {
+		return nil, err
+	}
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+	return &EventForwarder{
+		reader:    reader,
+		writer:    writer,
+		interval:  interval,
+		stateFile: stateFile,
+		hostname:  hostname,
+		lastID:    lastID,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}, nil
+}
+
+// Start forwards the events found in the log right away, and then polls it
+// in the background until the forwarder is stopped
+func (forwarder *EventForwarder) Start() {
+	go func() {
+		defer close(forwarder.done)
+		ticker := time.NewTicker(forwarder.interval)
+		defer ticker.Stop()
+		for {
+			if err := forwarder.forward(); err != nil {
+				log.Error("ipmctl exporter - failed to forward events due to: ", err)
+			}
+			select {
+			case <-forwarder.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop waits for the forwarder to finish and closes syslog connection
+func (forwarder *EventForwarder) Stop() {
+	forwarder.once.Do(func() {
+		close(forwarder.stop)
+		<-forwarder.done
+		forwarder.writer.close()
+	})
+}
+
+// forward writes all the events not forwarded yet to syslog. If syslog is
+// not available, the events left are forwarded by the next poll.
+func (forwarder *EventForwarder) forward() error {
+	opstat, events, err := forwarder.reader.readEvents()
+	if nvmStatusCodeEnum.nvmSuccess != opstat {
+		return err
+	}
+	previousID := forwarder.lastID
+	fresh, newestID := newerEvents(events, forwarder.lastID)
+	pid := os.Getpid()
+	var writeErr error
+	for i := range fresh {
+		if writeErr = forwarder.writer.write(formatSyslogMessage(&fresh[i], forwarder.hostname, pid)); writeErr != nil {
+			break
+		}
+		forwarder.lastID = fresh[i].eventID
+	}
+	if writeErr == nil {
+		forwarder.lastID = newestID
+	}
+	if forwarder.lastID != previousID {
+		if err := saveEventMark(forwarder.stateFile, forwarder.lastID); err != nil {
+			log.Error("ipmctl exporter - ", err)
+		}
+	}
+	return writeErr
+}
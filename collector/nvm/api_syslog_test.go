@@ -0,0 +1,294 @@
+/**
+ * Copyright (c) 2020-2021, Intel Corporation.
+ * SPDX-License-Identifier: BSD-3-Clause
+ **
+ * api_syslog_test.go file tests EventForwarder against syslog listeners
+ * started on the loopback interface.
+ */
+
+package nvm
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// eventBackend is stubBackend with an event log, it reports overlapping
+// calls, which are expected never to happen
+type eventBackend struct {
+	stubBackend
+	lock        sync.Mutex
+	events      []event
+	calls       int32
+	overlapping int32
+}
+
+func (backend *eventBackend) enter() func() {
+	if atomic.AddInt32(&backend.calls, 1) > 1 {
+		atomic.StoreInt32(&backend.overlapping, 1)
+	}
+	time.Sleep(time.Millisecond)
+	return func() { atomic.AddInt32(&backend.calls, -1) }
+}
+
+func (backend *eventBackend) GetNumberOfDevices() (nvmStatusCodeEnumAttr, nvmUint8, error) {
+	defer backend.enter()()
+	return backend.stubBackend.GetNumberOfDevices()
+}
+
+func (backend *eventBackend) GetSensor(deviceUID nvmUID,
+	stype sensorTypeEnumAttr) (nvmStatusCodeEnumAttr, sensor, error) {
+	defer backend.enter()()
+	return backend.stubBackend.GetSensor(deviceUID, stype)
+}
+
+func (backend *eventBackend) GetEvents() (nvmStatusCodeEnumAttr, []event, error) {
+	defer backend.enter()()
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	return nvmStatusCodeEnum.nvmSuccess, append([]event{}, backend.events...), nil
+}
+
+func (backend *eventBackend) setEvents(events []event) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	backend.events = events
+}
+
+func newEventBackend(ids ...nvmUint32) *eventBackend {
+	backend := &eventBackend{stubBackend: *newStubBackend(1, "8089-a2-1901-00001000")}
+	backend.setEvents(newTestEvents(ids...))
+	return backend
+}
+
+func newTestForwarder(t *testing.T, backend Backend, address string, stateFile string) *EventForwarder {
+	forwarder, err := NewEventForwarder(NewMetricsReader(backend, false), address, time.Hour, stateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return forwarder
+}
+
+// eventID returns ID of the event found in structured data of the message
+func eventID(t *testing.T, message string) int {
+	start := strings.Index(message, `event_id="`)
+	if start < 0 {
+		t.Fatalf("no event ID in message %q", message)
+	}
+	start += len(`event_id="`)
+	id, err := strconv.Atoi(message[start : start+strings.Index(message[start:], `"`)])
+	if err != nil {
+		t.Fatalf("invalid event ID in message %q", message)
+	}
+	return id
+}
+
+// udpListener collects event IDs of the datagrams received
+type udpListener struct {
+	conn net.PacketConn
+}
+
+func newUDPListener(t *testing.T) *udpListener {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &udpListener{conn: conn}
+}
+
+func (listener *udpListener) address() string {
+	return "udp://" + listener.conn.LocalAddr().String()
+}
+
+// receive reads datagrams until none arrives for a while
+func (listener *udpListener) receive(t *testing.T) []int {
+	ids := []int{}
+	buffer := make([]byte, 4096)
+	for {
+		listener.conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+		n, _, err := listener.conn.ReadFrom(buffer)
+		if err != nil {
+			return ids
+		}
+		message := string(buffer[:n])
+		if !strings.HasPrefix(message, "<28>1 ") {
+			t.Errorf("unexpected priority or version in message %q", message)
+		}
+		ids = append(ids, eventID(t, message))
+	}
+}
+
+func expectIDs(t *testing.T, step string, ids []int, expected ...int) {
+	if len(ids) != len(expected) {
+		t.Errorf("%s: expected events %v, got %v", step, expected, ids)
+		return
+	}
+	for i := range ids {
+		if ids[i] != expected[i] {
+			t.Errorf("%s: expected events %v, got %v", step, expected, ids)
+			return
+		}
+	}
+}
+
+func TestEventForwarderUDP(t *testing.T) {
+	listener := newUDPListener(t)
+	backend := newEventBackend(2, 1)
+	forwarder := newTestForwarder(t, backend, listener.address(), "")
+	defer forwarder.writer.close()
+
+	steps := []struct {
+		name     string
+		log      []nvmUint32
+		expected []int
+	}{
+		{"first poll", []nvmUint32{2, 1}, []int{1, 2}},
+		{"nothing new", []nvmUint32{1, 2}, []int{}},
+		{"new event", []nvmUint32{1, 2, 3}, []int{3}},
+		{"log purged", []nvmUint32{1}, []int{1}},
+	}
+	for _, step := range steps {
+		backend.setEvents(newTestEvents(step.log...))
+		if err := forwarder.forward(); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		expectIDs(t, step.name, listener.receive(t), step.expected...)
+	}
+}
+
+func TestEventForwarderTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	messages := make(chan string, 16)
+	go func() {
+		defer close(messages)
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		stream := bufio.NewReader(conn)
+		for {
+			// RFC6587 octet counting: MSG-LEN SP SYSLOG-MSG
+			length, err := stream.ReadString(' ')
+			if err != nil {
+				return
+			}
+			size, err := strconv.Atoi(strings.TrimSuffix(length, " "))
+			if err != nil {
+				t.Errorf("invalid frame length %q", length)
+				return
+			}
+			message := make([]byte, size)
+			if _, err := io.ReadFull(stream, message); err != nil {
+				t.Errorf("truncated frame: %v", err)
+				return
+			}
+			messages <- string(message)
+		}
+	}()
+
+	backend := newEventBackend(1, 2)
+	events := newTestEvents(1, 2)
+	// message text containing separators has to be kept in a single frame
+	events[1].message = "Health state changed 12 \n<13>1 next"
+	backend.setEvents(events)
+	forwarder := newTestForwarder(t, backend, "tcp://"+listener.Addr().String(), "")
+	if err := forwarder.forward(); err != nil {
+		t.Fatal(err)
+	}
+	if err := forwarder.forward(); err != nil {
+		t.Fatal(err)
+	}
+	forwarder.writer.close()
+
+	received := []string{}
+	for message := range messages {
+		received = append(received, message)
+	}
+	if len(received) != 2 {
+		t.Fatalf("expected 2 messages, got %d: %q", len(received), received)
+	}
+	for i, message := range received {
+		expected := string(formatSyslogMessage(&events[i], forwarder.hostname, os.Getpid()))
+		if message != expected {
+			t.Errorf("expected message %q, got %q", expected, message)
+		}
+	}
+}
+
+func TestEventForwarderRestart(t *testing.T) {
+	listener := newUDPListener(t)
+	stateFile := filepath.Join(t.TempDir(), "forwarder.state")
+	backend := newEventBackend(1, 2)
+
+	forwarder := newTestForwarder(t, backend, listener.address(), stateFile)
+	forwarder.Start()
+	expectIDs(t, "before restart", listener.receive(t), 1, 2)
+	forwarder.Stop()
+	if mark, err := loadEventMark(stateFile); err != nil || mark != 2 {
+		t.Fatalf("expected event 2 saved as the last one forwarded, got %d (%v)", mark, err)
+	}
+
+	backend.setEvents(newTestEvents(1, 2, 3))
+	forwarder = newTestForwarder(t, backend, listener.address(), stateFile)
+	forwarder.Start()
+	expectIDs(t, "after restart", listener.receive(t), 3)
+	forwarder.Stop()
+	if mark, _ := loadEventMark(stateFile); mark != 3 {
+		t.Errorf("expected event 3 saved as the last one forwarded, got %d", mark)
+	}
+}
+
+func TestEventForwarderUnavailable(t *testing.T) {
+	// take a free port and close it, so the connection is refused
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := "tcp://" + listener.Addr().String()
+	listener.Close()
+	stateFile := filepath.Join(t.TempDir(), "forwarder.state")
+	forwarder := newTestForwarder(t, newEventBackend(1, 2), address, stateFile)
+	if err := forwarder.forward(); err == nil {
+		t.Errorf("expected forwarding to unavailable syslog to fail")
+	}
+	if mark, err := loadEventMark(stateFile); err != nil || mark != 0 {
+		t.Errorf("expected no event saved as forwarded, got %d (%v)", mark, err)
+	}
+}
+
+// TestEventForwarderSerialized polls the event log while reading cycles run,
+// the backend must never be called concurrently
+func TestEventForwarderSerialized(t *testing.T) {
+	listener := newUDPListener(t)
+	backend := newEventBackend(1)
+	reader := NewMetricsReader(backend, false)
+	forwarder, err := NewEventForwarder(reader, listener.address(), time.Millisecond, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	forwarder.Start()
+	defer forwarder.Stop()
+	for i := 0; i < 20; i++ {
+		if status, err := reader.GetRequiredReadings(); !status {
+			t.Fatalf("GetRequiredReadings failed: %v", err)
+		}
+	}
+	if atomic.LoadInt32(&backend.overlapping) != 0 {
+		t.Errorf("backend called concurrently by forwarder and reading cycle")
+	}
+}
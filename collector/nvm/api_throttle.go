@@ -0,0 +1,97 @@
+/**
+ * Copyright (c) 2020-2021, Intel Corporation.
+ * SPDX-License-Identifier: BSD-3-Clause
+ **
+ * This package introduces wrapper for ipmctl library written in C.
+ * api_throttle.go file exposes external API for exporter to collect thermal
+ * throttling metrics. Besides the performance loss reported by firmware, the
+ * exporter keeps its own history of throttle episodes observed between
+ * reading cycles, since the firmware reports only the average loss since
+ * the last read.
+ */
+
+package nvm
+
+import (
+	"time"
+)
+
+// throttleHistory keeps thermal throttling state of single DCPMM observed
+// by the exporter
+type throttleHistory struct {
+	throttled     bool
+	lastReading   time.Time
+	episodes      uint64
+	throttledTime time.Duration
+}
+
+// updateThrottleHistory takes the throttling state of all the devices read
+// in the current reading cycle. The time between two readings is counted as
+// throttled, when the DCPMM was throttled at the earlier one. Devices, which
+// are no longer present, are forgotten.
+func (reader *MetricsReader) updateThrottleHistory(now time.Time) {
+	history := make(map[nvmUID]*throttleHistory, len(reader.devices))
+	for _, dev := range reader.devices {
+		if nvmStatusCodeEnum.nvmSuccess != dev.statusOpstat {
+			if previous, found := reader.throttle[dev.uid]; found {
+				history[dev.uid] = previous
+			}
+			continue
+		}
+		throttled := dev.status.thermalThrottlePerformanceLossPCNT > 0
+		current, found := reader.throttle[dev.uid]
+		if !found {
+			current = &throttleHistory{}
+		} else if current.throttled {
+			current.throttledTime += now.Sub(current.lastReading)
+		}
+		if throttled && !current.throttled {
+			current.episodes++
+		}
+		current.throttled = throttled
+		current.lastReading = now
+		history[dev.uid] = current
+	}
+	reader.throttle = history
+}
+
+// getThrottleHistoryReadings returns history of thermal throttling, which
+// is reported even if the last status reading of the DCPMM failed
+func (reader *MetricsReader) getThrottleHistoryReadings(metricType devStatusTypeEnumAttr) []MetricReading {
+	results := make([]MetricReading, reader.deviceCount)
+	for i, dev := range reader.devices {
+		opstat := dev.statusOpstat
+		metricValue := float64(0)
+		if history, found := reader.throttle[dev.uid]; found {
+			opstat = nvmStatusCodeEnum.nvmSuccess
+			switch metricType {
+			case devStatusTypeEnum.throttleEpisodes:
+				metricValue = float64(history.episodes)
+			case devStatusTypeEnum.throttledTime:
+				metricValue = history.throttledTime.Seconds()
+			}
+		}
+		devStatusReading := *newDevStatusReading(dev.uid, opstat, metricType, 0)
+		devStatusReading.MetricValue = metricValue
+		devStatusReading.Labels.addLabel("uid", string(dev.uid))
+		dev.addLocationLabel(devStatusReading.Labels)
+		results[i] = MetricReading(devStatusReading)
+	}
+	return results
+}
+
+// Average percentage of performance lost due to thermal throttling since the
+// last reading
+func (reader *MetricsReader) GetThermalThrottlePerformanceLoss() []MetricReading {
+	return reader.getDeviceStatusReadings(devStatusTypeEnum.thermalThrottleLoss)
+}
+
+// Number of transitions into thermal throttling observed by the exporter
+func (reader *MetricsReader) GetThermalThrottleEpisodes() []MetricReading {
+	return reader.getThrottleHistoryReadings(devStatusTypeEnum.throttleEpisodes)
+}
+
+// Time the DCPMM spent throttled as observed by the exporter, in seconds
+func (reader *MetricsReader) GetThermalThrottledTime() []MetricReading {
+	return reader.getThrottleHistoryReadings(devStatusTypeEnum.throttledTime)
+}
@@ -0,0 +1,74 @@
+/**
+ * Copyright (c) 2020-2021, Intel Corporation.
+ * SPDX-License-Identifier: BSD-3-Clause
+ **
+ * api_throttle_test.go file tests the history of thermal throttle episodes
+ * kept by the exporter between reading cycles.
+ */
+
+package nvm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUpdateThrottleHistory(t *testing.T) {
+	const uid = "8089-a2-1901-00001000"
+	start := time.Unix(1577836800, 0)
+	steps := []struct {
+		name string
+		// seconds since start
+		at      int
+		present bool
+		opstat  nvmStatusCodeEnumAttr
+		loss    uint8
+		// expected history, metrics are not exported if status is not success
+		episodes      float64
+		throttledTime float64
+		status        nvmStatusCodeEnumAttr
+	}{
+		{"not throttled", 0, true, nvmStatusCodeEnum.nvmSuccess, 0, 0, 0, nvmStatusCodeEnum.nvmSuccess},
+		{"throttling started", 10, true, nvmStatusCodeEnum.nvmSuccess, 20, 1, 0, nvmStatusCodeEnum.nvmSuccess},
+		{"still throttled", 20, true, nvmStatusCodeEnum.nvmSuccess, 10, 1, 10, nvmStatusCodeEnum.nvmSuccess},
+		// history is kept, when the status can not be read
+		{"status read failed", 30, true, nvmStatusCodeEnum.nvmErrUnknown, 0, 1, 10, nvmStatusCodeEnum.nvmSuccess},
+		{"throttled after failed read", 45, true, nvmStatusCodeEnum.nvmSuccess, 5, 1, 35, nvmStatusCodeEnum.nvmSuccess},
+		{"throttling stopped", 50, true, nvmStatusCodeEnum.nvmSuccess, 0, 1, 40, nvmStatusCodeEnum.nvmSuccess},
+		{"not throttled again", 60, true, nvmStatusCodeEnum.nvmSuccess, 0, 1, 40, nvmStatusCodeEnum.nvmSuccess},
+		{"second episode", 70, true, nvmStatusCodeEnum.nvmSuccess, 30, 2, 40, nvmStatusCodeEnum.nvmSuccess},
+		{"device removed", 80, false, nvmStatusCodeEnum.nvmSuccess, 0, 0, 0, nvmStatusCodeEnum.nvmSuccess},
+		// history of the device removed is forgotten, no status read yet
+		{"device back with status read failed", 90, true, nvmStatusCodeEnum.nvmErrUnknown, 0, 0, 0, nvmStatusCodeEnum.nvmErrUnknown},
+		{"device back throttled", 100, true, nvmStatusCodeEnum.nvmSuccess, 15, 1, 0, nvmStatusCodeEnum.nvmSuccess},
+	}
+	reader := NewMetricsReader(newStubBackend(0), false)
+	for _, step := range steps {
+		reader.devices = []device{}
+		if step.present {
+			dev := device{uid: uid, statusOpstat: step.opstat}
+			dev.status.thermalThrottlePerformanceLossPCNT = nvmUint8(step.loss)
+			reader.devices = append(reader.devices, dev)
+		}
+		reader.deviceCount = nvmUint8(len(reader.devices))
+		reader.updateThrottleHistory(start.Add(time.Duration(step.at) * time.Second))
+		if !step.present {
+			if len(reader.throttle) != 0 {
+				t.Errorf("%s: expected history of the device removed to be forgotten", step.name)
+			}
+			continue
+		}
+		episodes := reader.GetThermalThrottleEpisodes()[0]
+		throttledTime := reader.GetThermalThrottledTime()[0]
+		if episodes.ReadStatus != int(step.status) || throttledTime.ReadStatus != int(step.status) {
+			t.Errorf("%s: expected status %d, got %d and %d",
+				step.name, step.status, episodes.ReadStatus, throttledTime.ReadStatus)
+		}
+		if episodes.MetricValue != step.episodes {
+			t.Errorf("%s: expected %v episodes, got %v", step.name, step.episodes, episodes.MetricValue)
+		}
+		if throttledTime.MetricValue != step.throttledTime {
+			t.Errorf("%s: expected %vs throttled, got %v", step.name, step.throttledTime, throttledTime.MetricValue)
+		}
+	}
+}
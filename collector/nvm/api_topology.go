@@ -0,0 +1,138 @@
+/**
+ * Copyright (c) 2020-2021, Intel Corporation.
+ * SPDX-License-Identifier: BSD-3-Clause
+ **
+ * This package introduces wrapper for ipmctl library written in C.
+ * api_topology.go file exposes external API for exporter to collect
+ * processor sockets and memory topology metrics. Memory topology lists all
+ * memory devices installed in the system (DDR4 DIMMs as well as DCPMMs) with
+ * their physical labels (device locator and bank label), DCPMMs are matched
+ * by physical ID (SMBIOS handle) and reported with their UIDs.
+ */
+
+package nvm
+
+import (
+	"sort"
+)
+
+var SocketLabelNames = []string{
+	"socket_id",
+}
+
+var MemoryTopologyLabelNames = []string{
+	"physical_id",
+	"memory_type",
+	"device_locator",
+	"bank_label",
+	"uid",
+}
+
+var topologyMetricTypeEnum = &topologyMetricType{
+	mappedMemoryLimit: 0,
+	totalMappedMemory: 1,
+	memoryTopology:    2,
+	unknown:           0xFF,
+}
+
+type topologyReading MetricReading
+type socketLabels MetricLabels
+type memoryTopologyLabels MetricLabels
+type topologyMetricTypeEnumAttr enumAttr
+type topologyMetricType struct {
+	mappedMemoryLimit topologyMetricTypeEnumAttr
+	totalMappedMemory topologyMetricTypeEnumAttr
+	memoryTopology    topologyMetricTypeEnumAttr
+	unknown           topologyMetricTypeEnumAttr
+}
+
+func (sl socketLabels) GetLabelValues() []string {
+	return getValuesByName(SocketLabelNames, MetricLabels(sl).labels)
+}
+
+func (sl socketLabels) GetLabelNames() []string {
+	return SocketLabelNames
+}
+
+func (sl socketLabels) addLabel(name string, value string) {
+	MetricLabels(sl).labels[name] = value
+}
+
+func (tl memoryTopologyLabels) GetLabelValues() []string {
+	return getValuesByName(MemoryTopologyLabelNames, MetricLabels(tl).labels)
+}
+
+func (tl memoryTopologyLabels) GetLabelNames() []string {
+	return MemoryTopologyLabelNames
+}
+
+func (tl memoryTopologyLabels) addLabel(name string, value string) {
+	MetricLabels(tl).labels[name] = value
+}
+
+func (reader *MetricsReader) getSocketReadings(metricType topologyMetricTypeEnumAttr) []MetricReading {
+	platform := reader.platform
+	sockets := make([]socket, len(platform.sockets))
+	copy(sockets, platform.sockets)
+	sort.Slice(sockets, func(i, j int) bool {
+		return sockets[i].id < sockets[j].id
+	})
+	results := make([]MetricReading, len(sockets))
+	for i := range sockets {
+		s := &sockets[i]
+		topologyReading := new(topologyReading)
+		topologyReading.ReadStatus = int(platform.socketsOpstat)
+		topologyReading.MetricType = uint8(metricType)
+		switch metricType {
+		case topologyMetricTypeEnum.mappedMemoryLimit:
+			topologyReading.MetricValue = float64(s.mappedMemoryLimit)
+		case topologyMetricTypeEnum.totalMappedMemory:
+			topologyReading.MetricValue = float64(s.totalMappedMemory)
+		}
+		topologyReading.Labels = socketLabels(*newMetricLabels())
+		topologyReading.Labels.addLabel("socket_id", s.id.toString(16))
+		results[i] = MetricReading(*topologyReading)
+	}
+	return results
+}
+
+// Maximum memory allowed to be mapped by the socket (taken from PCAT) in
+// bytes
+func (reader *MetricsReader) GetSocketMappedMemoryLimit() []MetricReading {
+	return reader.getSocketReadings(topologyMetricTypeEnum.mappedMemoryLimit)
+}
+
+// Memory currently mapped by the socket (taken from PCAT) in bytes
+func (reader *MetricsReader) GetSocketTotalMappedMemory() []MetricReading {
+	return reader.getSocketReadings(topologyMetricTypeEnum.totalMappedMemory)
+}
+
+// Describes every memory device installed in the system, its type and
+// physical labels, UID is reported only for DCPMMs
+func (reader *MetricsReader) GetMemoryTopologyInfo() []MetricReading {
+	platform := reader.platform
+	results := make([]MetricReading, len(platform.topology))
+	for i := range platform.topology {
+		t := &platform.topology[i]
+		uid := ""
+		for _, dev := range reader.devices {
+			if dev.discovery.physicalID == t.physicalID {
+				uid = string(dev.uid)
+				break
+			}
+		}
+		topologyReading := new(topologyReading)
+		topologyReading.DIMMUID = uid
+		topologyReading.ReadStatus = int(platform.topologyOpstat)
+		topologyReading.MetricType = uint8(topologyMetricTypeEnum.memoryTopology)
+		topologyReading.MetricValue = 1
+		topologyReading.Labels = memoryTopologyLabels(*newMetricLabels())
+		topologyReading.Labels.addLabel("physical_id", t.physicalID.toString(16))
+		topologyReading.Labels.addLabel("memory_type", getMemoryTypeName(t.memoryType))
+		topologyReading.Labels.addLabel("device_locator", t.deviceLocator)
+		topologyReading.Labels.addLabel("bank_label", t.bankLabel)
+		topologyReading.Labels.addLabel("uid", uid)
+		results[i] = MetricReading(*topologyReading)
+	}
+	return results
+}
@@ -1,5 +1,3 @@
-// +build cgo
-
 /**
  * Copyright (c) 2020-2021, Intel Corporation.
  * SPDX-License-Identifier: BSD-3-Clause
@@ -10,10 +8,6 @@
  */
 package nvm
 
-// #cgo pkg-config: libipmctl
-// #include <include/nvm_management.h>
-import "C"
-
 type Labels interface {
 	GetLabelValues() []string
 	GetLabelNames() []string
@@ -236,6 +230,12 @@ type (
 		skuViolation nvmBool
 		reserved     nvmUint8
 	}
+	swInventory struct {
+		mgmtSwRevision         nvmVersion
+		vendorDriverRevision   nvmVersion
+		vendorDriverCompatible nvmBool
+		reserved               [13]nvmUint8
+	}
 	socket struct {
 		id                nvmUint16
 		mappedMemoryLimit nvmUint64
@@ -393,7 +393,10 @@ type (
 		thermalThrottlePerformanceLossPCNT nvmUint8
 		reserved                           [64]nvmUint8
 	}
-	// enumAttrSharedDefs.h
+	// NvmSharedDefs.h (PMON_REGISTERS, included by nvm_management.h), the
+	// fields follow the C layout one by one, PMON 6, 10-13 and 15 have no
+	// counter in the payload, their slots are reserved3, reserved4 and
+	// reserved5
 	pmonRegisters struct {
 		// This will specify whether or not to return the extra smart data
 		// along with the PMON Counter data.
@@ -401,16 +404,17 @@ type (
 		reserved1     [3]uint8
 		// This will specify which group that is currently enabled. If no
 		// groups are enabled Group F will be returned.
-		groupEnabled uint8
-		reserved2    [19]uint8
-		pmon4Counter uint
-		pmon5Counter uint
-		reserved3    [4]uint8
-		pmon7Counter uint
-		pmon8Counter uint
-		pmon9Counter uint
-		reserved4    [16]uint8
-		reserved5    [4]uint8
+		groupEnabled  uint8
+		reserved2     [19]uint8
+		pmon4Counter  uint
+		pmon5Counter  uint
+		reserved3     [4]uint8
+		pmon7Counter  uint
+		pmon8Counter  uint
+		pmon9Counter  uint
+		reserved4     [16]uint8
+		pmon14Counter uint
+		reserved5     [4]uint8
 		// DDRT Reads for current power cycle
 		ddrtrd uint64
 		// DDRT Writes for current power cycle
@@ -488,6 +492,17 @@ type (
 		sensorFatal          sensorStatusEnumAttr
 		sensorUnknown        sensorStatusEnumAttr
 	}
+	// Overall health of a device as reported by the health sensor
+	healthStatusEnumAttr enumAttr
+	healthStatus         struct {
+		healthStatusUnknown            healthStatusEnumAttr
+		healthStatusHealthy            healthStatusEnumAttr
+		healthStatusNonCriticalFailure healthStatusEnumAttr
+		healthStatusCriticalFailure    healthStatusEnumAttr
+		healthStatusFatalFailure       healthStatusEnumAttr
+		healthStatusUnmanageable       healthStatusEnumAttr
+		healthStatusNonFunctional      healthStatusEnumAttr
+	}
 	sensorSettings struct {
 		enabled                   nvmBool
 		upperCriticalThreshold    nvmUint64
@@ -651,6 +666,7 @@ type (
 		biosConfigSupport        nvmBool
 		biosRuntimeSupport       nvmBool
 		memoryMirrorSupported    nvmBool
+		memorySpareSupported     nvmBool
 		memoryMigrationSupported nvmBool
 		oneLMMode                memoryCapabilities
 		memoryMode               memoryCapabilities
@@ -888,9 +904,10 @@ type (
 )
 
 var (
-	isLibInitialized       = false
-	nvmMaxUIDLen      uint = 22
-	nvmStatusCodeEnum      = &nvmStatusCode{
+	isLibInitialized          = false
+	nvmMaxUIDLen      uint    = 22
+	nvmVersionLen     nvmSize = 25
+	nvmStatusCodeEnum         = &nvmStatusCode{
 		nvmSuccess:                                   0,
 		nvmSuccessFWResetRequired:                    1,
 		nvmErrOperationNotStarted:                    2,
@@ -1181,6 +1198,15 @@ var (
 		sensorFatal:          3,
 		sensorUnknown:        4,
 	}
+	healthStatusEnum = &healthStatus{
+		healthStatusUnknown:            0,
+		healthStatusHealthy:            1,
+		healthStatusNonCriticalFailure: 2,
+		healthStatusCriticalFailure:    3,
+		healthStatusFatalFailure:       4,
+		healthStatusUnmanageable:       5,
+		healthStatusNonFunctional:      6,
+	}
 	sensorUnitsEnum = &sensorUnits{
 		unitCount:   1,
 		unitCelsius: 2,
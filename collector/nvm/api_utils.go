@@ -1,5 +1,3 @@
-// +build cgo
-
 /**
  * Copyright (c) 2020-2021, Intel Corporation.
  * SPDX-License-Identifier: BSD-3-Clause
@@ -11,14 +9,24 @@
 
 package nvm
 
-// #cgo pkg-config: libipmctl
-// #include <include/nvm_management.h>
-import "C"
-
-import ()
+// getNamesByLabels returns given names followed by location, if location
+// label is attached to the labels of per device reading
+func getNamesByLabels(names []string,
+	dict map[string]string) []string {
+	if _, found := dict["location"]; !found {
+		return names
+	}
+	for _, name := range names {
+		if "location" == name {
+			return names
+		}
+	}
+	return append(append([]string{}, names...), "location")
+}
 
 func getValuesByName(names []string,
 	dict map[string]string) []string {
+	names = getNamesByLabels(names, dict)
 	values := make([]string, len(names))
 	for i, name := range names {
 		value, found := dict[name]
@@ -31,144 +39,6 @@ func getValuesByName(names []string,
 	return values
 }
 
-func makeNVMBool(cValue C.uchar) nvmBool {
-	if 0 == cValue {
-		return nvmBool(false)
-	}
-	return nvmBool(true)
-}
-
-func makeNVMUint8Array(cValue []C.uchar) []nvmUint8 {
-	result := make([]nvmUint8, len(cValue))
-	for i := 0; i < len(cValue); i++ {
-		result[i] = nvmUint8(cValue[i])
-	}
-	return result
-}
-
-func makeNVMUint16Array(cValue []C.ushort) []nvmUint16 {
-	result := make([]nvmUint16, len(cValue))
-	for i := 0; i < len(cValue); i++ {
-		result[i] = nvmUint16(cValue[i])
-	}
-	return result
-}
-
-func makeNVMNfitDeviceHandle(cValue C.NVM_NFIT_DEVICE_HANDLE) []byte {
-	result := make([]byte, 32)
-	for i, cval := range cValue {
-		result[i] = byte(cval)
-	}
-	return result
-}
-
-func newSensorSettings(cValue C.struct_sensor_settings) *sensorSettings {
-	sensorSettings := new(sensorSettings)
-	sensorSettings.enabled = makeNVMBool(cValue.enabled)
-	sensorSettings.upperCriticalThreshold = nvmUint64(cValue.upper_critical_threshold)
-	sensorSettings.lowerCriticalThreshold = nvmUint64(cValue.lower_critical_threshold)
-	sensorSettings.upperFatalThreshold = nvmUint64(cValue.upper_fatal_threshold)
-	sensorSettings.lowerFatalThreshold = nvmUint64(cValue.lower_fatal_threshold)
-	sensorSettings.upperNoncriticalThreshold = nvmUint64(cValue.upper_noncritical_threshold)
-	sensorSettings.lowerNoncriticalThreshold = nvmUint64(cValue.lower_noncritical_threshold)
-	copy(sensorSettings.reserved[:], makeNVMUint8Array(cValue.reserved[:]))
-	return sensorSettings
-}
-
-func newDeviceSecurityCapabilities(cValue C.struct_device_security_capabilities) *deviceSecurityCapabilities {
-	devSecCap := new(deviceSecurityCapabilities)
-	devSecCap.passphraseCapable = makeNVMBool(cValue.passphrase_capable)
-	devSecCap.unlockDeviceCapable = makeNVMBool(cValue.unlock_device_capable)
-	devSecCap.eraseCryptoCapable = makeNVMBool(cValue.erase_crypto_capable)
-	devSecCap.masterPassphraseCapable = makeNVMBool(cValue.master_passphrase_capable)
-	copy(devSecCap.reserved[:], makeNVMUint8Array(cValue.reserved[:]))
-	return devSecCap
-}
-
-func newDeviceCapabilities(cValue C.struct_device_capabilities) *deviceCapabilities {
-	devCap := new(deviceCapabilities)
-	devCap.packageSparingCapable = makeNVMBool(cValue.package_sparing_capable)
-	devCap.memoryModeCapable = makeNVMBool(cValue.memory_mode_capable)
-	devCap.appDirectModeCapable = makeNVMBool(cValue.app_direct_mode_capable)
-	copy(devCap.reserved[:], makeNVMUint8Array(cValue.reserved[:]))
-	return devCap
-}
-
-func newDeviceDiscovery(cValue C.struct_device_discovery) *deviceDiscovery {
-	devDisc := new(deviceDiscovery)
-	devDisc.allPropertiesPopulated = makeNVMBool(cValue.all_properties_populated)
-	devDisc.deviceHandle = makeNVMNfitDeviceHandle(cValue.device_handle)
-	devDisc.physicalID = nvmUint16(cValue.physical_id)
-	devDisc.vendorID = nvmUint16(cValue.vendor_id)
-	devDisc.deviceID = nvmUint16(cValue.device_id)
-	devDisc.revisionID = nvmUint16(cValue.revision_id)
-	devDisc.channelPos = nvmUint16(cValue.channel_pos)
-	devDisc.channelID = nvmUint16(cValue.channel_id)
-	devDisc.memoryControllerID = nvmUint16(cValue.memory_controller_id)
-	devDisc.socketID = nvmUint16(cValue.socket_id)
-	devDisc.nodeControllerID = nvmUint16(cValue.node_controller_id)
-	devDisc.memoryType = memoryTypeEnumAttr(cValue.memory_type)
-	devDisc.dimmSKU = nvmUint32(cValue.dimm_sku)
-	devDisc.manufacturer = nvmManufacturer(makeNVMUint8Array(cValue.manufacturer[:]))
-	devDisc.serialNumber = nvmSerialNumber(makeNVMUint8Array(cValue.serial_number[:]))
-	devDisc.subsystemVendorID = nvmUint16(cValue.subsystem_vendor_id)
-	devDisc.subsystemDeviceID = nvmUint16(cValue.subsystem_device_id)
-	devDisc.subsystemRevisionID = nvmUint16(cValue.subsystem_revision_id)
-	devDisc.manufacturingInfoValid = makeNVMBool(cValue.manufacturing_info_valid)
-	devDisc.manufacturingLocation = nvmUint8(cValue.manufacturing_location)
-	devDisc.manufacturingDate = nvmUint16(cValue.manufacturing_date)
-	devDisc.partNumber = C.GoString(&cValue.part_number[0])
-	devDisc.fwRevision = nvmVersion(C.GoString(&cValue.fw_revision[0]))
-	devDisc.fwAPIVersion = nvmVersion(C.GoString(&cValue.fw_api_version[0]))
-	devDisc.capacity = nvmUint64(cValue.capacity)
-	copy(devDisc.interfaceFormatCodes[:], makeNVMUint16Array(cValue.interface_format_codes[:]))
-	devDisc.securityCapabilities = *newDeviceSecurityCapabilities(cValue.security_capabilities)
-	devDisc.deviceCapabilities = *newDeviceCapabilities(cValue.device_capabilities)
-	devDisc.uid = nvmUID(C.GoString(&cValue.uid[0]))
-	devDisc.lockState = lockStateEnumAttr(cValue.lock_state)
-	devDisc.manageability = manageabilityStateEnumAttr(cValue.manageability)
-	devDisc.controllerRevisionID = nvmUint16(cValue.controller_revision_id)
-	devDisc.masterPassphraseEnabled = makeNVMBool(cValue.master_passphrase_enabled)
-	copy(devDisc.reserved[:], makeNVMUint8Array(cValue.reserved[:]))
-	return devDisc
-}
-
-func newSensor(cValue C.struct_sensor) *sensor {
-	sensor := new(sensor)
-	sensor.stype = sensorTypeEnumAttr(cValue._type)
-	sensor.units = sensorUnitsEnumAttr(cValue.units)
-	sensor.currentState = sensorStatusEnumAttr(cValue.current_state)
-	sensor.reading = nvmUint64(cValue.reading)
-	sensor.settings = *newSensorSettings(cValue.settings)
-	sensor.lowerCriticalSettable = makeNVMBool(cValue.lower_critical_settable)
-	sensor.upperCriticalSettable = makeNVMBool(cValue.upper_critical_settable)
-	sensor.lowerCriticalSupport = makeNVMBool(cValue.lower_critical_support)
-	sensor.upperCriticalSupport = makeNVMBool(cValue.upper_critical_support)
-	sensor.lowerFatalSettable = makeNVMBool(cValue.lower_fatal_settable)
-	sensor.upperFatalSettable = makeNVMBool(cValue.upper_fatal_settable)
-	sensor.lowerFatalSupport = makeNVMBool(cValue.lower_fatal_support)
-	sensor.upperFatalSupport = makeNVMBool(cValue.upper_fatal_support)
-	sensor.lowerNoncriticalSettable = makeNVMBool(cValue.lower_noncritical_settable)
-	sensor.upperNoncriticalSettable = makeNVMBool(cValue.upper_noncritical_settable)
-	sensor.lowerNoncriticalSupport = makeNVMBool(cValue.lower_noncritical_support)
-	sensor.upperNoncriticalSupport = makeNVMBool(cValue.upper_noncritical_support)
-	copy(sensor.reserved[:], makeNVMUint8Array(cValue.reserved[:]))
-	return sensor
-}
-
-func newDevicePerformance(cValue C.struct_device_performance) *devicePerformance {
-	devPerf := new(devicePerformance)
-	devPerf.time = timeT(nvmUint64(cValue.time))
-	devPerf.bytesRead = nvmUint64(cValue.bytes_read)
-	devPerf.hostReads = nvmUint64(cValue.host_reads)
-	devPerf.bytesWritten = nvmUint64(cValue.bytes_written)
-	devPerf.hostWrites = nvmUint64(cValue.host_writes)
-	devPerf.blockReads = nvmUint64(cValue.block_reads)
-	devPerf.blockWrites = nvmUint64(cValue.block_writes)
-	copy(devPerf.reserved[:], makeNVMUint8Array(cValue.reserved[:]))
-	return devPerf
-}
-
 func newMetricLabels() *MetricLabels {
 	ml := new(MetricLabels)
 	ml.labels = make(map[string]string)
@@ -220,3 +90,305 @@ func getManageabilityName(manageability manageabilityStateEnumAttr) string {
 	}
 	return "unknown"
 }
+
+func getConfigStatusName(configStatus configStatusEnumAttr) string {
+	switch configStatus {
+	case configStatusEnum.configStatusNotConfigured:
+		return "not_configured"
+	case configStatusEnum.configStatusValid:
+		return "valid"
+	case configStatusEnum.configStatusErrCorrupt:
+		return "corrupt"
+	case configStatusEnum.configStatusErrBrokenInterleave:
+		return "broken_interleave"
+	case configStatusEnum.configStatusErrReverted:
+		return "reverted"
+	case configStatusEnum.configStatusErrNotSupported:
+		return "not_supported"
+	}
+	return "unknown"
+}
+
+func getARSStatusName(arsStatus deviceARSStatusEnumAttr) string {
+	switch arsStatus {
+	case deviceARSStatusEnum.deviceARSStatusNotStarted:
+		return "not_started"
+	case deviceARSStatusEnum.deviceARSStatusInprogress:
+		return "in_progress"
+	case deviceARSStatusEnum.deviceARSStatusComplete:
+		return "complete"
+	case deviceARSStatusEnum.deviceARSStatusAborted:
+		return "aborted"
+	}
+	return "unknown"
+}
+
+func getOverwriteDIMMStatusName(overwriteStatus deviceOverwriteDIMMStatusEnumAttr) string {
+	switch overwriteStatus {
+	case deviceOverwriteDIMMStatusEnum.deviceOverwriteDIMMStatusNotstarted:
+		return "not_started"
+	case deviceOverwriteDIMMStatusEnum.deviceOverwriteDIMMStatusInprogress:
+		return "in_progress"
+	case deviceOverwriteDIMMStatusEnum.deviceOverwriteDIMMStatusComplete:
+		return "complete"
+	}
+	return "unknown"
+}
+
+func getFwUpdateStatusName(fwUpdateStatus fwUpdateStatusEnumAttr) string {
+	switch fwUpdateStatus {
+	case fwUpdateStatusEnum.fwUpdateStaged:
+		return "staged"
+	case fwUpdateStatusEnum.fwUpdateSuccess:
+		return "success"
+	case fwUpdateStatusEnum.fwUpdateFailed:
+		return "failed"
+	}
+	return "unknown"
+}
+
+func getRegionTypeName(regionType regionTypeEnumAttr) string {
+	switch regionType {
+	case regionTypeEnum.regionTypePersistent:
+		return "persistent"
+	case regionTypeEnum.regionTypeVolatile:
+		return "volatile"
+	case regionTypeEnum.regionTypePersistentMirror:
+		return "persistent_mirror"
+	}
+	return "unknown"
+}
+
+func getRegionHealthName(regionHealth regionHealthEnumAttr) string {
+	switch regionHealth {
+	case regionHealthEnum.regionHealthNormal:
+		return "normal"
+	case regionHealthEnum.regionHealthError:
+		return "error"
+	case regionHealthEnum.regionHealthPending:
+		return "pending"
+	case regionHealthEnum.regionHealthLocked:
+		return "locked"
+	}
+	return "unknown"
+}
+
+func getEventTypeName(eventType eventTypeEnumAttr) string {
+	switch eventType {
+	case eventTypeEnum.eventTypeConfig:
+		return "config"
+	case eventTypeEnum.eventTypeHealth:
+		return "health"
+	case eventTypeEnum.eventTypeMgmt:
+		return "mgmt"
+	case eventTypeEnum.eventTypeDiag:
+		return "diag"
+	case eventTypeEnum.eventTypeDiagQuick:
+		return "diag_quick"
+	case eventTypeEnum.eventTypeDiagPlatformConfig:
+		return "diag_platform_config"
+	case eventTypeEnum.eventTypeDiagSecurity:
+		return "diag_security"
+	case eventTypeEnum.eventTypeDiagFWConsistency:
+		return "diag_fw_consistency"
+	}
+	return "unknown"
+}
+
+func getEventSeverityName(severity eventSeverityEnumAttr) string {
+	switch severity {
+	case eventSeverityEnum.eventSeverityInfo:
+		return "info"
+	case eventSeverityEnum.eventSeverityWarn:
+		return "warning"
+	case eventSeverityEnum.eventSeverityCritical:
+		return "critical"
+	case eventSeverityEnum.eventSeverityFatal:
+		return "fatal"
+	}
+	return "unknown"
+}
+
+func getDiagnosticResultName(result diagnosticResultEnumAttr) string {
+	switch result {
+	case diagnosticResultEnum.diagnosticResultOK:
+		return "ok"
+	case diagnosticResultEnum.diagnosticResultWarning:
+		return "warning"
+	case diagnosticResultEnum.diagnosticResultFailed:
+		return "failed"
+	case diagnosticResultEnum.diagnosticResultAborted:
+		return "aborted"
+	}
+	return "unknown"
+}
+
+func getErrorLogTypeName(logType errorLogTypeEnumAttr) string {
+	switch logType {
+	case errorLogTypeEnum.media:
+		return "media"
+	case errorLogTypeEnum.thermal:
+		return "thermal"
+	}
+	return "unknown"
+}
+
+func getErrorLogLevelName(logLevel errorLogLevelEnumAttr) string {
+	switch logLevel {
+	case errorLogLevelEnum.low:
+		return "low"
+	case errorLogLevelEnum.high:
+		return "high"
+	}
+	return "unknown"
+}
+
+func getOsTypeName(osType osTypeEnumAttr) string {
+	switch osType {
+	case osTypeEnum.osTypeWindows:
+		return "windows"
+	case osTypeEnum.osTypeLinux:
+		return "linux"
+	case osTypeEnum.osTypeEsx:
+		return "esx"
+	}
+	return "unknown"
+}
+
+func getVolatileModeName(mode volatileModeEnumAttr) string {
+	switch mode {
+	case volatileModeEnum.volatileMode1LM:
+		return "1lm"
+	case volatileModeEnum.volatileModeMemory:
+		return "memory"
+	case volatileModeEnum.volatileModeAuto:
+		return "auto"
+	}
+	return "unknown"
+}
+
+func getAppDirectModeName(mode appDirectModeEnumAttr) string {
+	switch mode {
+	case appDirectModeEnum.appDirectModeDisabled:
+		return "disabled"
+	case appDirectModeEnum.appDirectModeEnabled:
+		return "enabled"
+	}
+	return "unknown"
+}
+
+func getInterleaveSizeName(size interleaveSizeEnumAttr) string {
+	switch size {
+	case interleaveSizeEnum.interleaveSizeNone:
+		return "none"
+	case interleaveSizeEnum.interleaveSize64b:
+		return "64b"
+	case interleaveSizeEnum.interleaveSize128b:
+		return "128b"
+	case interleaveSizeEnum.interleaveSize256b:
+		return "256b"
+	case interleaveSizeEnum.interleaveSize4kb:
+		return "4kb"
+	case interleaveSizeEnum.interleaveSize1gb:
+		return "1gb"
+	}
+	return "unknown"
+}
+
+// getInterleaveWaysName returns the number of DCPMMs interleaved, interleave
+// ways are reported by libipmctl as bit flags
+func getInterleaveWaysName(ways interleaveWaysEnumAttr) string {
+	switch ways {
+	case interleaveWaysEnum.interleaveWays0:
+		return "0"
+	case interleaveWaysEnum.interleaveWays1:
+		return "1"
+	case interleaveWaysEnum.interleaveWays2:
+		return "2"
+	case interleaveWaysEnum.interleaveWays3:
+		return "3"
+	case interleaveWaysEnum.interleaveWays4:
+		return "4"
+	case interleaveWaysEnum.interleaveWays6:
+		return "6"
+	case interleaveWaysEnum.interleaveWays8:
+		return "8"
+	case interleaveWaysEnum.interleaveWays12:
+		return "12"
+	case interleaveWaysEnum.interleaveWays16:
+		return "16"
+	case interleaveWaysEnum.interleaveWays24:
+		return "24"
+	}
+	return "unknown"
+}
+
+func getFormFactorName(formFactor deviceFromFactorEnumAttr) string {
+	switch formFactor {
+	case deviceFromFactorEnum.deviceFromFactorDIMM:
+		return "dimm"
+	case deviceFromFactorEnum.deviceFromFactorSODIMM:
+		return "sodimm"
+	}
+	return "unknown"
+}
+
+func getSensorTypeName(sensorType sensorTypeEnumAttr) string {
+	switch sensorType {
+	case sensorTypeEnum.sensorHealth:
+		return "health"
+	case sensorTypeEnum.sensorMediaTemperature:
+		return "media_temperature"
+	case sensorTypeEnum.sensorControllerTemperature:
+		return "controller_temperature"
+	case sensorTypeEnum.sensorPercentageRemaining:
+		return "percentage_remaining"
+	case sensorTypeEnum.sensorLatchedDirtyShutdownCount:
+		return "latched_dirty_shutdown_count"
+	case sensorTypeEnum.sensorPowerontime:
+		return "power_on_time"
+	case sensorTypeEnum.sensorUptime:
+		return "up_time"
+	case sensorTypeEnum.sensorPowerCycles:
+		return "power_cycles"
+	case sensorTypeEnum.sensorFWerrorlogcount:
+		return "fw_error_count"
+	case sensorTypeEnum.sensorUnlachedDirtyShutdownCount:
+		return "unlatched_dirty_shutdown_count"
+	}
+	return "unknown"
+}
+
+func getSensorStateName(sensorState sensorStatusEnumAttr) string {
+	switch sensorState {
+	case sensorStatusEnum.sensorNormal:
+		return "normal"
+	case sensorStatusEnum.sensorNoncritical:
+		return "noncritical"
+	case sensorStatusEnum.sensorCritical:
+		return "critical"
+	case sensorStatusEnum.sensorFatal:
+		return "fatal"
+	}
+	return "unknown"
+}
+
+func getSensorUnitsName(units sensorUnitsEnumAttr) string {
+	switch units {
+	case sensorUnitsEnum.unitCount:
+		return "count"
+	case sensorUnitsEnum.unitCelsius:
+		return "celsius"
+	case sensorUnitsEnum.unitSeconds:
+		return "seconds"
+	case sensorUnitsEnum.unitMinutes:
+		return "minutes"
+	case sensorUnitsEnum.unitHours:
+		return "hours"
+	case sensorUnitsEnum.unitCycles:
+		return "cycles"
+	case sensorUnitsEnum.unitPercent:
+		return "percent"
+	}
+	return "unknown"
+}
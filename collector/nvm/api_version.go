@@ -0,0 +1,82 @@
+/**
+ * Copyright (c) 2020-2021, Intel Corporation.
+ * SPDX-License-Identifier: BSD-3-Clause
+ **
+ * This package introduces wrapper for ipmctl library written in C.
+ * api_version.go file exposes external API for exporter to collect version
+ * of the native API library (libipmctl) the backend takes the readings with,
+ * so changes of DCPMM readings may be correlated with library upgrades.
+ */
+
+package nvm
+
+import (
+	"strconv"
+	"strings"
+)
+
+var LibraryLabelNames = []string{
+	"version",
+	"major",
+	"minor",
+	"hotfix",
+	"build",
+}
+
+// libraryVersion is the version of libipmctl as a string in MM.mm.hh.bbbb
+// format and all of its parts
+type libraryVersion struct {
+	version nvmVersion
+	major   int
+	minor   int
+	hotfix  int
+	build   int
+}
+
+type libraryReading MetricReading
+type libraryLabels MetricLabels
+
+func (ll libraryLabels) GetLabelValues() []string {
+	return getValuesByName(LibraryLabelNames, MetricLabels(ll).labels)
+}
+
+func (ll libraryLabels) GetLabelNames() []string {
+	return LibraryLabelNames
+}
+
+func (ll libraryLabels) addLabel(name string, value string) {
+	MetricLabels(ll).labels[name] = value
+}
+
+// parseLibraryVersion splits MM.mm.hh.bbbb version string into its parts,
+// parts missing or not being numbers are reported as 0
+func parseLibraryVersion(version nvmVersion) libraryVersion {
+	parts := strings.Split(strings.TrimSpace(string(version)), ".")
+	numbers := make([]int, 4)
+	for i := 0; i < len(parts) && i < len(numbers); i++ {
+		numbers[i], _ = strconv.Atoi(parts[i])
+	}
+	return libraryVersion{
+		version: nvmVersion(strings.TrimSpace(string(version))),
+		major:   numbers[0],
+		minor:   numbers[1],
+		hotfix:  numbers[2],
+		build:   numbers[3],
+	}
+}
+
+// Describes version of libipmctl used by the backend
+func (reader *MetricsReader) GetLibraryInfo() []MetricReading {
+	platform := reader.platform
+	version := platform.libraryVersion
+	libraryReading := new(libraryReading)
+	libraryReading.ReadStatus = int(platform.libraryVersionOpstat)
+	libraryReading.MetricValue = 1
+	libraryReading.Labels = libraryLabels(*newMetricLabels())
+	libraryReading.Labels.addLabel("version", string(version.version))
+	libraryReading.Labels.addLabel("major", strconv.Itoa(version.major))
+	libraryReading.Labels.addLabel("minor", strconv.Itoa(version.minor))
+	libraryReading.Labels.addLabel("hotfix", strconv.Itoa(version.hotfix))
+	libraryReading.Labels.addLabel("build", strconv.Itoa(version.build))
+	return []MetricReading{MetricReading(*libraryReading)}
+}
@@ -2,4 +2,7 @@ module github.com/intel/ipmctl_exporter
 
 go 1.15
 
-require github.com/prometheus/client_golang v1.6.0
+require (
+	github.com/prometheus/client_golang v1.6.0
+	gopkg.in/yaml.v2 v2.3.0
+)
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"time"
 
 	"github.com/elastic/go-elasticsearch/v7"
 	"github.com/intel/ipmctl_exporter/collector"
@@ -73,11 +74,39 @@ func setupLogger(loggingLevel string, logOnConsole bool, elasticUsed bool, elast
 	}
 }
 
-func parseCmdArgs() (string, bool, bool, string, bool, bool, string, string) {
+// cmdArgs gathers all command line arguments accepted by exporter
+type cmdArgs struct {
+	port             string
+	enableThresholds bool
+	enableDetails    bool
+	showVersion      bool
+	loggingLevel     string
+	logOnConsole     bool
+	elasticUsed      bool
+	elasticAddress   string
+	indexName        string
+	backend          string
+	ipmctlPath       string
+	sysfsRoot        string
+	ndctlPath        string
+	scenarioFile     string
+	captureFile      string
+	recordFile       string
+	enableNfit       bool
+	nfitTable        string
+	locationLabels   bool
+	syslogAddress    string
+	syslogInterval   time.Duration
+	syslogStateFile  string
+}
+
+func parseCmdArgs() cmdArgs {
 	port := flag.String("port", "9757",
 		"Listening port number used by exporter")
 	enableThresholds := flag.Bool("thresholds-enable", false,
 		"Enable media and controller temperature, plus percentage remaining thresholds collection")
+	enableDetails := flag.Bool("details-enable", false,
+		"Enable collection of SMBIOS data (form factor, widths, speed, slot labels) and power budgets of DCPMMs")
 	showVersion := flag.Bool("version", false,
 		"Shows ipmctl_exporter version")
 	loggingLevel := flag.String("log-level", "Info",
@@ -92,8 +121,61 @@ func parseCmdArgs() (string, bool, bool, string, bool, bool, string, string) {
 		"URL used for elasticsearch connection")
 	elasticIndexName := flag.String("index-name", "cr-telemetry-ipmctl-exporter",
 		"Index name used/created in elasticsearch")
+	backend := flag.String("backend", "lib",
+		"Source of DCPMM readings:\n\tlib - libipmctl\n\tcli - output of ipmctl tool\n"+
+			"\tsysfs - nfit driver (sysfs) and ndctl tool\n"+
+			"\tsim - simulated DCPMMs described by scenario file\n"+
+			"\treplay - readings recorded in capture file\n")
+	ipmctlPath := flag.String("ipmctl-path", "ipmctl",
+		"Path to ipmctl tool used by CLI backend")
+	sysfsRoot := flag.String("sysfs-root", "/sys",
+		"Mount point of sysfs read by sysfs backend")
+	ndctlPath := flag.String("ndctl-path", "ndctl",
+		"Path to ndctl tool used by sysfs backend to read health data, empty value disables it")
+	scenarioFile := flag.String("scenario", "",
+		"YAML or JSON scenario file used by simulated backend")
+	captureFile := flag.String("capture", "",
+		"Capture file played back by replay backend")
+	recordFile := flag.String("record", "",
+		"Record all raw readings to given capture file")
+	enableNfit := flag.Bool("nfit-enable", false,
+		"Enable collection of topology metrics decoded from ACPI NFIT table")
+	nfitTable := flag.String("nfit-table", "/sys/firmware/acpi/tables/NFIT",
+		"Path to ACPI NFIT table")
+	locationLabels := flag.Bool("location-labels", false,
+		"Attach location label (e.g. CPU0_IMC1_CH0_DIMM1) to all sensor and performance metrics")
+	syslogAddress := flag.String("syslog-address", "",
+		"Forward events to syslog at given address (udp://host:port, tcp://host:port or unix:///dev/log), "+
+			"empty value disables forwarding")
+	syslogInterval := flag.Duration("syslog-interval", time.Minute,
+		"Interval of event log polling by syslog forwarder")
+	syslogStateFile := flag.String("syslog-state-file", "ipmctl-exporter-syslog.state",
+		"File keeping ID of the last event forwarded to syslog across restarts")
 	flag.Parse()
-	return *port, *enableThresholds, *showVersion, *loggingLevel, *useOnConsole, *useElastic, *elasticAddress, *elasticIndexName
+	return cmdArgs{
+		port:             *port,
+		enableThresholds: *enableThresholds,
+		enableDetails:    *enableDetails,
+		showVersion:      *showVersion,
+		loggingLevel:     *loggingLevel,
+		logOnConsole:     *useOnConsole,
+		elasticUsed:      *useElastic,
+		elasticAddress:   *elasticAddress,
+		indexName:        *elasticIndexName,
+		backend:          *backend,
+		ipmctlPath:       *ipmctlPath,
+		sysfsRoot:        *sysfsRoot,
+		ndctlPath:        *ndctlPath,
+		scenarioFile:     *scenarioFile,
+		captureFile:      *captureFile,
+		recordFile:       *recordFile,
+		enableNfit:       *enableNfit,
+		nfitTable:        *nfitTable,
+		locationLabels:   *locationLabels,
+		syslogAddress:    *syslogAddress,
+		syslogInterval:   *syslogInterval,
+		syslogStateFile:  *syslogStateFile,
+	}
 }
 
 func handleSIGINT() {
@@ -110,16 +192,33 @@ func handleSIGINT() {
 }
 
 func main() {
-	port, enableThresholds, showVersion, loggingLevel, logOnConsole, elasticUsed, elasticAddress, indexName := parseCmdArgs()
-	setupLogger(loggingLevel, logOnConsole, elasticUsed, elasticAddress, indexName)
-	if showVersion {
+	args := parseCmdArgs()
+	setupLogger(args.loggingLevel, args.logOnConsole, args.elasticUsed, args.elasticAddress, args.indexName)
+	if args.showVersion {
 		fmt.Printf("%s\n", Version)
 		os.Exit(0)
 	}
 	handleSIGINT()
 	log.Debug("Ipmctl_exporter version: ", Version)
-	log.Debug("Ipmctl exporter listening port: ", port)
-	fmt.Printf("ipmctl exporter listening on port :%s\n", port)
+	log.Debug("Ipmctl exporter listening port: ", args.port)
+	log.Debug("Ipmctl exporter backend: ", args.backend)
+	fmt.Printf("ipmctl exporter listening on port :%s\n", args.port)
 	collector.Version = Version
-	collector.Run(port, enableThresholds)
+	collector.Run(args.port, collector.Config{
+		EnableThresholds: args.enableThresholds,
+		EnableDetails:    args.enableDetails,
+		Backend:          args.backend,
+		IpmctlPath:       args.ipmctlPath,
+		SysfsRoot:        args.sysfsRoot,
+		NdctlPath:        args.ndctlPath,
+		ScenarioFile:     args.scenarioFile,
+		CaptureFile:      args.captureFile,
+		RecordFile:       args.recordFile,
+		EnableNfit:       args.enableNfit,
+		NfitTable:        args.nfitTable,
+		LocationLabels:   args.locationLabels,
+		SyslogAddress:    args.syslogAddress,
+		SyslogInterval:   args.syslogInterval,
+		SyslogStateFile:  args.syslogStateFile,
+	})
 }